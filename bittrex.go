@@ -2,6 +2,7 @@
 package bittrex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,13 +14,18 @@ import (
 )
 
 const (
-	API_BASE    = "https://bittrex.com/api/" // Bittrex API endpoint
-	API_VERSION = "v1.1"                     // Bittrex API version
+	API_BASE    = "https://bittrex.com/api/"     // Bittrex API endpoint
+	API_VERSION = "v1.1"                         // Bittrex API version
+	API_V2_BASE = "https://bittrex.com/Api/v2.0" // Bittrex v2.0 API endpoint, used by a few calls
+	API_V3_BASE = "https://api.bittrex.com/v3"   // Bittrex v3 API endpoint
+
+	SOCKET_V3_BASE = "https://socket-v3.bittrex.com/signalr" // Bittrex v3 socket feed, a SignalR endpoint
 )
 
-// New returns an instantiated bittrex struct
-func New(apiKey, apiSecret string) *Bittrex {
-	client := NewClient(apiKey, apiSecret)
+// New returns an instantiated bittrex struct.
+// Options may be given to override the API roots, http client or timeout.
+func New(apiKey, apiSecret string, opts ...Option) *Bittrex {
+	client := newClient(apiKey, apiSecret, opts...)
 	return &Bittrex{client}
 }
 
@@ -35,14 +41,6 @@ func NewWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) *Bitt
 	return &Bittrex{client}
 }
 
-// handleErr gets JSON response from Bittrex API en deal with error
-func handleErr(r jsonResponse) error {
-	if !r.Success {
-		return errors.New(r.Message)
-	}
-	return nil
-}
-
 // bittrex represent a bittrex client
 type Bittrex struct {
 	client *client
@@ -50,7 +48,12 @@ type Bittrex struct {
 
 // GetDistribution is used to get the distribution.
 func (b *Bittrex) GetDistribution(market string) (*Distribution, error) {
-	r, err := b.client.do("GET", "https://bittrex.com/Api/v2.0/pub/currency/GetBalanceDistribution?currencyName="+strings.ToUpper(market), "", false)
+	return b.GetDistributionCtx(context.Background(), market)
+}
+
+// GetDistributionCtx is like GetDistribution but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetDistributionCtx(ctx context.Context, market string) (*Distribution, error) {
+	r, err := b.client.do(ctx, "GET", b.client.v2("pub/currency/GetBalanceDistribution?currencyName="+strings.ToUpper(market)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -60,10 +63,6 @@ func (b *Bittrex) GetDistribution(market string) (*Distribution, error) {
 		return nil, err
 	}
 
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
-
 	distribution := Distribution{}
 	if err = json.Unmarshal(response.Result, &distribution); err != nil {
 		return nil, err
@@ -74,7 +73,15 @@ func (b *Bittrex) GetDistribution(market string) (*Distribution, error) {
 
 // GetCurrencies is used to get all supported currencies at Bittrex along with other meta data.
 func (b *Bittrex) GetCurrencies() ([]*Currency, error) {
-	r, err := b.client.do("GET", "public/getcurrencies", "", false)
+	return b.GetCurrenciesCtx(context.Background())
+}
+
+// GetCurrenciesCtx is like GetCurrencies but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetCurrenciesCtx(ctx context.Context) ([]*Currency, error) {
+	if b.client.useV3 {
+		return b.v3GetCurrencies(ctx)
+	}
+	r, err := b.client.do(ctx, "GET", "public/getcurrencies", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -82,9 +89,6 @@ func (b *Bittrex) GetCurrencies() ([]*Currency, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	currencies := []*Currency{}
 	if err = json.Unmarshal(response.Result, &currencies); err != nil {
 		return nil, err
@@ -95,7 +99,15 @@ func (b *Bittrex) GetCurrencies() ([]*Currency, error) {
 
 // GetMarkets is used to get the open and available trading markets at Bittrex along with other meta data.
 func (b *Bittrex) GetMarkets() ([]*Market, error) {
-	r, err := b.client.do("GET", "public/getmarkets", "", false)
+	return b.GetMarketsCtx(context.Background())
+}
+
+// GetMarketsCtx is like GetMarkets but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetMarketsCtx(ctx context.Context) ([]*Market, error) {
+	if b.client.useV3 {
+		return b.v3GetMarkets(ctx)
+	}
+	r, err := b.client.do(ctx, "GET", "public/getmarkets", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -103,9 +115,6 @@ func (b *Bittrex) GetMarkets() ([]*Market, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 
 	markets := []*Market{}
 	if err = json.Unmarshal(response.Result, &markets); err != nil {
@@ -117,7 +126,15 @@ func (b *Bittrex) GetMarkets() ([]*Market, error) {
 
 // GetTicker is used to get the current ticker values for a market.
 func (b *Bittrex) GetTicker(market string) (*Ticker, error) {
-	r, err := b.client.do("GET", "public/getticker?market="+strings.ToUpper(market), "", false)
+	return b.GetTickerCtx(context.Background(), market)
+}
+
+// GetTickerCtx is like GetTicker but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetTickerCtx(ctx context.Context, market string) (*Ticker, error) {
+	if b.client.useV3 {
+		return b.v3GetTicker(ctx, market)
+	}
+	r, err := b.client.do(ctx, "GET", "public/getticker?market="+strings.ToUpper(market), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -125,9 +142,6 @@ func (b *Bittrex) GetTicker(market string) (*Ticker, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 
 	ticker := Ticker{}
 	if err = json.Unmarshal(response.Result, &ticker); err != nil {
@@ -138,7 +152,15 @@ func (b *Bittrex) GetTicker(market string) (*Ticker, error) {
 
 // GetMarketSummaries is used to get the last 24 hour summary of all active exchanges
 func (b *Bittrex) GetMarketSummaries() ([]*MarketSummary, error) {
-	r, err := b.client.do("GET", "public/getmarketsummaries", "", false)
+	return b.GetMarketSummariesCtx(context.Background())
+}
+
+// GetMarketSummariesCtx is like GetMarketSummaries but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetMarketSummariesCtx(ctx context.Context) ([]*MarketSummary, error) {
+	if b.client.useV3 {
+		return b.v3GetMarketSummaries(ctx)
+	}
+	r, err := b.client.do(ctx, "GET", "public/getmarketsummaries", "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +168,6 @@ func (b *Bittrex) GetMarketSummaries() ([]*MarketSummary, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	marketSummaries := []*MarketSummary{}
 	if err = json.Unmarshal(response.Result, &marketSummaries); err != nil {
 		return nil, err
@@ -158,7 +177,15 @@ func (b *Bittrex) GetMarketSummaries() ([]*MarketSummary, error) {
 
 // GetMarketSummary is used to get the last 24 hour summary for a given market
 func (b *Bittrex) GetMarketSummary(market string) ([]*MarketSummary, error) {
-	r, err := b.client.do("GET", fmt.Sprintf("public/getmarketsummary?market=%s", strings.ToUpper(market)), "", false)
+	return b.GetMarketSummaryCtx(context.Background(), market)
+}
+
+// GetMarketSummaryCtx is like GetMarketSummary but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetMarketSummaryCtx(ctx context.Context, market string) ([]*MarketSummary, error) {
+	if b.client.useV3 {
+		return b.v3GetMarketSummary(ctx, market)
+	}
+	r, err := b.client.do(ctx, "GET", fmt.Sprintf("public/getmarketsummary?market=%s", strings.ToUpper(market)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +193,6 @@ func (b *Bittrex) GetMarketSummary(market string) ([]*MarketSummary, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 
 	marketSummary := []*MarketSummary{}
 	if err = json.Unmarshal(response.Result, &marketSummary); err != nil {
@@ -183,6 +207,11 @@ func (b *Bittrex) GetMarketSummary(market string) ([]*MarketSummary, error) {
 // cat: buy, sell or both to identify the type of orderbook to return.
 // depth: how deep of an order book to retrieve. Max is 100
 func (b *Bittrex) GetOrderBook(market, cat string, depth int) (*OrderBook, error) {
+	return b.GetOrderBookCtx(context.Background(), market, cat, depth)
+}
+
+// GetOrderBookCtx is like GetOrderBook but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderBookCtx(ctx context.Context, market, cat string, depth int) (*OrderBook, error) {
 	if cat != "buy" && cat != "sell" && cat != "both" {
 		cat = "both"
 	}
@@ -192,7 +221,19 @@ func (b *Bittrex) GetOrderBook(market, cat string, depth int) (*OrderBook, error
 	if depth < 1 {
 		depth = 1
 	}
-	r, err := b.client.do("GET", fmt.Sprintf("public/getorderbook?market=%s&type=%s&depth=%d", strings.ToUpper(market), cat, depth), "", false)
+	if b.client.useV3 {
+		orderBook, err := b.v3GetOrderBook(ctx, market, depth)
+		if err != nil {
+			return nil, err
+		}
+		if cat == "buy" {
+			orderBook.Sell = nil
+		} else if cat == "sell" {
+			orderBook.Buy = nil
+		}
+		return orderBook, nil
+	}
+	r, err := b.client.do(ctx, "GET", fmt.Sprintf("public/getorderbook?market=%s&type=%s&depth=%d", strings.ToUpper(market), cat, depth), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -200,9 +241,6 @@ func (b *Bittrex) GetOrderBook(market, cat string, depth int) (*OrderBook, error
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 
 	orderBook := OrderBook{}
 	if cat == "buy" {
@@ -224,6 +262,11 @@ func (b *Bittrex) GetOrderBook(market, cat string, depth int) (*OrderBook, error
 // cat: buy or sell to identify the type of orderbook to return.
 // depth: how deep of an order book to retrieve. Max is 100
 func (b *Bittrex) GetOrderBookBuySell(market, cat string, depth int) ([]*Orderb, error) {
+	return b.GetOrderBookBuySellCtx(context.Background(), market, cat, depth)
+}
+
+// GetOrderBookBuySellCtx is like GetOrderBookBuySell but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderBookBuySellCtx(ctx context.Context, market, cat string, depth int) ([]*Orderb, error) {
 	if cat != "buy" && cat != "sell" {
 		cat = "buy"
 	}
@@ -233,7 +276,22 @@ func (b *Bittrex) GetOrderBookBuySell(market, cat string, depth int) ([]*Orderb,
 	if depth < 1 {
 		depth = 1
 	}
-	r, err := b.client.do("GET", fmt.Sprintf("public/getorderbook?market=%s&type=%s&depth=%d", strings.ToUpper(market), cat, depth), "", false)
+	if b.client.useV3 {
+		orderBook, err := b.v3GetOrderBook(ctx, market, depth)
+		if err != nil {
+			return nil, err
+		}
+		side := orderBook.Buy
+		if cat == "sell" {
+			side = orderBook.Sell
+		}
+		orderb := make([]*Orderb, len(side))
+		for i := range side {
+			orderb[i] = &side[i]
+		}
+		return orderb, nil
+	}
+	r, err := b.client.do(ctx, "GET", fmt.Sprintf("public/getorderbook?market=%s&type=%s&depth=%d", strings.ToUpper(market), cat, depth), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -241,9 +299,6 @@ func (b *Bittrex) GetOrderBookBuySell(market, cat string, depth int) ([]*Orderb,
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 
 	orderb := []*Orderb{}
 	if err = json.Unmarshal(response.Result, &orderb); err != nil {
@@ -256,7 +311,15 @@ func (b *Bittrex) GetOrderBookBuySell(market, cat string, depth int) ([]*Orderb,
 // GetMarketHistory is used to retrieve the latest trades that have occured for a specific market.
 // market a string literal for the market (ex: BTC-LTC)
 func (b *Bittrex) GetMarketHistory(market string) ([]*Trade, error) {
-	r, err := b.client.do("GET", fmt.Sprintf("public/getmarkethistory?market=%s", strings.ToUpper(market)), "", false)
+	return b.GetMarketHistoryCtx(context.Background(), market)
+}
+
+// GetMarketHistoryCtx is like GetMarketHistory but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetMarketHistoryCtx(ctx context.Context, market string) ([]*Trade, error) {
+	if b.client.useV3 {
+		return b.v3GetMarketHistory(ctx, market)
+	}
+	r, err := b.client.do(ctx, "GET", fmt.Sprintf("public/getmarkethistory?market=%s", strings.ToUpper(market)), "", false)
 	if err != nil {
 		return nil, err
 	}
@@ -264,9 +327,6 @@ func (b *Bittrex) GetMarketHistory(market string) ([]*Trade, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	trades := []*Trade{}
 	if err = json.Unmarshal(response.Result, &trades); err != nil {
 		return nil, err
@@ -278,7 +338,15 @@ func (b *Bittrex) GetMarketHistory(market string) ([]*Trade, error) {
 
 // BuyLimit is used to place a limited buy order in a specific market.
 func (b *Bittrex) BuyLimit(market string, quantity, rate float64) (uuid string, err error) {
-	r, err := b.client.do("GET", "market/buylimit?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&rate="+strconv.FormatFloat(rate, 'f', 8, 64), "", true)
+	return b.BuyLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// BuyLimitCtx is like BuyLimit but carries ctx to the underlying HTTP request.
+func (b *Bittrex) BuyLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrder(ctx, market, V3DirectionBuy, V3OrderTypeLimit, quantity, rate)
+	}
+	r, err := b.client.do(ctx, "GET", "market/buylimit?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&rate="+strconv.FormatFloat(rate, 'f', 8, 64), "", true)
 	if err != nil {
 		return
 	}
@@ -286,9 +354,6 @@ func (b *Bittrex) BuyLimit(market string, quantity, rate float64) (uuid string,
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
-		return
-	}
 	var u Uuid
 	err = json.Unmarshal(response.Result, &u)
 	uuid = u.Id
@@ -297,7 +362,15 @@ func (b *Bittrex) BuyLimit(market string, quantity, rate float64) (uuid string,
 
 // BuyMarket is used to place a market buy order in a spacific market.
 func (b *Bittrex) BuyMarket(market string, quantity float64) (uuid string, err error) {
-	r, err := b.client.do("GET", "market/buymarket?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64), "", true)
+	return b.BuyMarketCtx(context.Background(), market, quantity)
+}
+
+// BuyMarketCtx is like BuyMarket but carries ctx to the underlying HTTP request.
+func (b *Bittrex) BuyMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrder(ctx, market, V3DirectionBuy, V3OrderTypeMarket, quantity, 0)
+	}
+	r, err := b.client.do(ctx, "GET", "market/buymarket?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64), "", true)
 	if err != nil {
 		return
 	}
@@ -305,9 +378,6 @@ func (b *Bittrex) BuyMarket(market string, quantity float64) (uuid string, err e
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
-		return
-	}
 	var u Uuid
 	err = json.Unmarshal(response.Result, &u)
 	uuid = u.Id
@@ -316,7 +386,15 @@ func (b *Bittrex) BuyMarket(market string, quantity float64) (uuid string, err e
 
 // SellLimit is used to place a limited sell order in a specific market.
 func (b *Bittrex) SellLimit(market string, quantity, rate float64) (uuid string, err error) {
-	r, err := b.client.do("GET", "market/selllimit?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&rate="+strconv.FormatFloat(rate, 'f', 8, 64), "", true)
+	return b.SellLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// SellLimitCtx is like SellLimit but carries ctx to the underlying HTTP request.
+func (b *Bittrex) SellLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrder(ctx, market, V3DirectionSell, V3OrderTypeLimit, quantity, rate)
+	}
+	r, err := b.client.do(ctx, "GET", "market/selllimit?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&rate="+strconv.FormatFloat(rate, 'f', 8, 64), "", true)
 	if err != nil {
 		return
 	}
@@ -324,9 +402,6 @@ func (b *Bittrex) SellLimit(market string, quantity, rate float64) (uuid string,
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
-		return
-	}
 	var u Uuid
 	err = json.Unmarshal(response.Result, &u)
 	uuid = u.Id
@@ -335,7 +410,15 @@ func (b *Bittrex) SellLimit(market string, quantity, rate float64) (uuid string,
 
 // SellMarket is used to place a market sell order in a specific market.
 func (b *Bittrex) SellMarket(market string, quantity float64) (uuid string, err error) {
-	r, err := b.client.do("GET", "market/sellmarket?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64), "", true)
+	return b.SellMarketCtx(context.Background(), market, quantity)
+}
+
+// SellMarketCtx is like SellMarket but carries ctx to the underlying HTTP request.
+func (b *Bittrex) SellMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrder(ctx, market, V3DirectionSell, V3OrderTypeMarket, quantity, 0)
+	}
+	r, err := b.client.do(ctx, "GET", "market/sellmarket?market="+market+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64), "", true)
 	if err != nil {
 		return
 	}
@@ -343,9 +426,6 @@ func (b *Bittrex) SellMarket(market string, quantity float64) (uuid string, err
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
-		return
-	}
 	var u Uuid
 	err = json.Unmarshal(response.Result, &u)
 	uuid = u.Id
@@ -354,15 +434,16 @@ func (b *Bittrex) SellMarket(market string, quantity float64) (uuid string, err
 
 // CancelOrder is used to cancel a buy or sell order.
 func (b *Bittrex) CancelOrder(orderID string) (err error) {
-	r, err := b.client.do("GET", "market/cancel?uuid="+orderID, "", true)
-	if err != nil {
-		return
-	}
-	var response jsonResponse
-	if err = json.Unmarshal(r, &response); err != nil {
+	return b.CancelOrderCtx(context.Background(), orderID)
+}
+
+// CancelOrderCtx is like CancelOrder but carries ctx to the underlying HTTP request.
+func (b *Bittrex) CancelOrderCtx(ctx context.Context, orderID string) (err error) {
+	if b.client.useV3 {
+		_, err = b.V3().CancelOrder(ctx, orderID)
 		return
 	}
-	err = handleErr(response)
+	_, err = b.client.do(ctx, "GET", "market/cancel?uuid="+orderID, "", true)
 	return
 }
 
@@ -370,11 +451,19 @@ func (b *Bittrex) CancelOrder(orderID string) (err error) {
 // If market is set to "all", GetOpenOrders return all orders
 // If market is set to a specific order, GetOpenOrders return orders for this market
 func (b *Bittrex) GetOpenOrders(market string) ([]*OrderHistory, error) {
+	return b.GetOpenOrdersCtx(context.Background(), market)
+}
+
+// GetOpenOrdersCtx is like GetOpenOrders but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOpenOrdersCtx(ctx context.Context, market string) ([]*OrderHistory, error) {
+	if b.client.useV3 {
+		return b.v3GetOpenOrders(ctx, market)
+	}
 	ressource := "market/getopenorders"
 	if market != "all" {
 		ressource += "?market=" + strings.ToUpper(market)
 	}
-	r, err := b.client.do("GET", ressource, "", true)
+	r, err := b.client.do(ctx, "GET", ressource, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -396,7 +485,15 @@ func (b *Bittrex) GetOpenOrders(market string) ([]*OrderHistory, error) {
 
 // GetBalances is used to retrieve all balances from your account
 func (b *Bittrex) GetBalances() ([]*Balance, error) {
-	r, err := b.client.do("GET", "account/getbalances", "", true)
+	return b.GetBalancesCtx(context.Background())
+}
+
+// GetBalancesCtx is like GetBalances but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetBalancesCtx(ctx context.Context) ([]*Balance, error) {
+	if b.client.useV3 {
+		return b.v3GetBalances(ctx)
+	}
+	r, err := b.client.do(ctx, "GET", "account/getbalances", "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -404,9 +501,6 @@ func (b *Bittrex) GetBalances() ([]*Balance, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	balances := []*Balance{}
 	if err = json.Unmarshal(response.Result, &balances); err != nil {
 		return nil, err
@@ -417,7 +511,15 @@ func (b *Bittrex) GetBalances() ([]*Balance, error) {
 // Getbalance is used to retrieve the balance from your account for a specific currency.
 // currency: a string literal for the currency (ex: LTC)
 func (b *Bittrex) GetBalance(currency string) (*Balance, error) {
-	r, err := b.client.do("GET", "account/getbalance?currency="+strings.ToUpper(currency), "", true)
+	return b.GetBalanceCtx(context.Background(), currency)
+}
+
+// GetBalanceCtx is like GetBalance but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetBalanceCtx(ctx context.Context, currency string) (*Balance, error) {
+	if b.client.useV3 {
+		return b.v3GetBalance(ctx, currency)
+	}
+	r, err := b.client.do(ctx, "GET", "account/getbalance?currency="+strings.ToUpper(currency), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -425,9 +527,6 @@ func (b *Bittrex) GetBalance(currency string) (*Balance, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	balance := Balance{}
 	if err = json.Unmarshal(response.Result, &balance); err != nil {
 		return nil, err
@@ -438,7 +537,15 @@ func (b *Bittrex) GetBalance(currency string) (*Balance, error) {
 // GetDepositAddress is sed to generate or retrieve an address for a specific currency.
 // currency a string literal for the currency (ie. BTC)
 func (b *Bittrex) GetDepositAddress(currency string) (*Address, error) {
-	r, err := b.client.do("GET", "account/getdepositaddress?currency="+strings.ToUpper(currency), "", true)
+	return b.GetDepositAddressCtx(context.Background(), currency)
+}
+
+// GetDepositAddressCtx is like GetDepositAddress but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetDepositAddressCtx(ctx context.Context, currency string) (*Address, error) {
+	if b.client.useV3 {
+		return b.v3GetDepositAddress(ctx, currency)
+	}
+	r, err := b.client.do(ctx, "GET", "account/getdepositaddress?currency="+strings.ToUpper(currency), "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -446,9 +553,6 @@ func (b *Bittrex) GetDepositAddress(currency string) (*Address, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	address := Address{}
 	if err = json.Unmarshal(response.Result, &address); err != nil {
 		return nil, err
@@ -461,7 +565,15 @@ func (b *Bittrex) GetDepositAddress(currency string) (*Address, error) {
 // currency string literal for the currency (ie. BTC)
 // quantity float the quantity of coins to withdraw
 func (b *Bittrex) Withdraw(address, currency string, quantity float64) (withdrawUuid string, err error) {
-	r, err := b.client.do("GET", "account/withdraw?currency="+strings.ToUpper(currency)+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&address="+address, "", true)
+	return b.WithdrawCtx(context.Background(), address, currency, quantity)
+}
+
+// WithdrawCtx is like Withdraw but carries ctx to the underlying HTTP request.
+func (b *Bittrex) WithdrawCtx(ctx context.Context, address, currency string, quantity float64) (withdrawUuid string, err error) {
+	if b.client.useV3 {
+		return b.v3Withdraw(ctx, address, currency, quantity)
+	}
+	r, err := b.client.do(ctx, "GET", "account/withdraw?currency="+strings.ToUpper(currency)+"&quantity="+strconv.FormatFloat(quantity, 'f', 8, 64)+"&address="+address, "", true)
 	if err != nil {
 		return
 	}
@@ -469,9 +581,6 @@ func (b *Bittrex) Withdraw(address, currency string, quantity float64) (withdraw
 	if err = json.Unmarshal(r, &response); err != nil {
 		return
 	}
-	if err = handleErr(response); err != nil {
-		return
-	}
 	var u Uuid
 	err = json.Unmarshal(response.Result, &u)
 	withdrawUuid = u.Id
@@ -481,11 +590,19 @@ func (b *Bittrex) Withdraw(address, currency string, quantity float64) (withdraw
 // GetOrderHistory used to retrieve your order history.
 // market string literal for the market (ie. BTC-LTC). If set to "all", will return for all market
 func (b *Bittrex) GetOrderHistory(market string) ([]*OrderHistory, error) {
+	return b.GetOrderHistoryCtx(context.Background(), market)
+}
+
+// GetOrderHistoryCtx is like GetOrderHistory but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderHistoryCtx(ctx context.Context, market string) ([]*OrderHistory, error) {
+	if b.client.useV3 {
+		return b.v3GetOrderHistory(ctx, market)
+	}
 	ressource := "account/getorderhistory"
 	if market != "all" {
 		ressource += "?market=" + market
 	}
-	r, err := b.client.do("GET", ressource, "", true)
+	r, err := b.client.do(ctx, "GET", ressource, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -493,9 +610,6 @@ func (b *Bittrex) GetOrderHistory(market string) ([]*OrderHistory, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	orders := []*OrderHistory{}
 	if err = json.Unmarshal(response.Result, &orders); err != nil {
 		return nil, err
@@ -506,11 +620,19 @@ func (b *Bittrex) GetOrderHistory(market string) ([]*OrderHistory, error) {
 // GetWithdrawalHistory is used to retrieve your withdrawal history
 // currency string a string literal for the currency (ie. BTC). If set to "all", will return for all currencies
 func (b *Bittrex) GetWithdrawalHistory(currency string) ([]*Withdrawal, error) {
+	return b.GetWithdrawalHistoryCtx(context.Background(), currency)
+}
+
+// GetWithdrawalHistoryCtx is like GetWithdrawalHistory but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetWithdrawalHistoryCtx(ctx context.Context, currency string) ([]*Withdrawal, error) {
+	if b.client.useV3 {
+		return b.v3GetWithdrawalHistory(ctx, currency)
+	}
 	ressource := "account/getwithdrawalhistory"
 	if currency != "all" {
-		ressource += "currency=" + currency
+		ressource += "?currency=" + strings.ToUpper(currency)
 	}
-	r, err := b.client.do("GET", ressource, "", true)
+	r, err := b.client.do(ctx, "GET", ressource, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -518,9 +640,6 @@ func (b *Bittrex) GetWithdrawalHistory(currency string) ([]*Withdrawal, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	withdrawals := []*Withdrawal{}
 	if err = json.Unmarshal(response.Result, &withdrawals); err != nil {
 		return nil, err
@@ -531,11 +650,19 @@ func (b *Bittrex) GetWithdrawalHistory(currency string) ([]*Withdrawal, error) {
 // GetDepositHistory is used to retrieve your deposit history
 // currency string a string literal for the currency (ie. BTC). If set to "all", will return for all currencies
 func (b *Bittrex) GetDepositHistory(currency string) ([]*Deposit, error) {
+	return b.GetDepositHistoryCtx(context.Background(), currency)
+}
+
+// GetDepositHistoryCtx is like GetDepositHistory but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetDepositHistoryCtx(ctx context.Context, currency string) ([]*Deposit, error) {
+	if b.client.useV3 {
+		return b.v3GetDepositHistory(ctx, currency)
+	}
 	ressource := "account/getdeposithistory"
 	if currency != "all" {
-		ressource += "currency=" + currency
+		ressource += "?currency=" + strings.ToUpper(currency)
 	}
-	r, err := b.client.do("GET", ressource, "", true)
+	r, err := b.client.do(ctx, "GET", ressource, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -543,9 +670,6 @@ func (b *Bittrex) GetDepositHistory(currency string) ([]*Deposit, error) {
 	if err = json.Unmarshal(r, &response); err != nil {
 		return nil, err
 	}
-	if err = handleErr(response); err != nil {
-		return nil, err
-	}
 	deposits := []*Deposit{}
 	if err = json.Unmarshal(response.Result, &deposits); err != nil {
 		return nil, err
@@ -553,11 +677,20 @@ func (b *Bittrex) GetDepositHistory(currency string) ([]*Deposit, error) {
 	return deposits, nil
 }
 
+// GetOrder is used to retrieve a single order by uuid.
 func (b *Bittrex) GetOrder(order_uuid string) (*Order, error) {
+	return b.GetOrderCtx(context.Background(), order_uuid)
+}
+
+// GetOrderCtx is like GetOrder but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderCtx(ctx context.Context, order_uuid string) (*Order, error) {
+	if b.client.useV3 {
+		return b.v3GetOrder(ctx, order_uuid)
+	}
 
 	ressource := "account/getorder?uuid=" + order_uuid
 
-	r, err := b.client.do("GET", ressource, "", true)
+	r, err := b.client.do(ctx, "GET", ressource, "", true)
 	if err != nil {
 		return nil, err
 	}
@@ -578,18 +711,26 @@ func (b *Bittrex) GetOrder(order_uuid string) (*Order, error) {
 
 // GetTicks is used to get ticks history values for a market.
 func (b *Bittrex) GetTicks(market string, interval Interval) ([]*Candle, error) {
+	return b.GetTicksCtx(context.Background(), market, interval)
+}
+
+// GetTicksCtx is like GetTicks but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetTicksCtx(ctx context.Context, market string, interval Interval) ([]*Candle, error) {
 	_, ok := CANDLE_INTERVALS[interval]
 	if !ok {
 		return nil, errors.New("wrong interval")
 	}
+	if v3Interval, ok := v3CandleIntervals[interval]; ok && b.client.useV3 {
+		return b.v3GetTicks(ctx, market, v3Interval)
+	}
 
-	endpoint := fmt.Sprintf(
-		"https://bittrex.com/Api/v2.0/pub/market/GetTicks?tickInterval=%s&marketName=%s&_=%d",
+	endpoint := b.client.v2(fmt.Sprintf(
+		"pub/market/GetTicks?tickInterval=%s&marketName=%s&_=%d",
 		interval, strings.ToUpper(market), rand.Int(),
-	)
-	r, err := b.client.do("GET", endpoint, "", false)
+	))
+	r, err := b.client.do(ctx, "GET", endpoint, "", false)
 	if err != nil {
-		return nil, fmt.Errorf("could not get market ticks: %v", err)
+		return nil, fmt.Errorf("could not get market ticks: %w", err)
 	}
 
 	var response jsonResponse
@@ -597,10 +738,6 @@ func (b *Bittrex) GetTicks(market string, interval Interval) ([]*Candle, error)
 		return nil, err
 	}
 
-	if err := handleErr(response); err != nil {
-		return nil, err
-	}
-
 	candles := []*Candle{}
 	if err := json.Unmarshal(response.Result, &candles); err != nil {
 		return nil, fmt.Errorf("could not unmarshal candles: %v", err)
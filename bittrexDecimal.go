@@ -0,0 +1,304 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Decimal variants of the Bittrex methods. They read and send prices and
+// quantities as exact decimals instead of float64, whether the client is
+// backed by v1.1 or by v3, see v3Decimal.go.
+
+// getResult fetches ressource and decodes the result of the v1 envelope into v.
+func (b *Bittrex) getResult(ctx context.Context, ressource string, authNeeded bool, v interface{}) error {
+	r, err := b.client.do(ctx, "GET", ressource, "", authNeeded)
+	if err != nil {
+		return err
+	}
+	var response jsonResponse
+	if err = json.Unmarshal(r, &response); err != nil {
+		return err
+	}
+	return json.Unmarshal(response.Result, v)
+}
+
+// GetTickerDecimal is like GetTicker but returns exact decimals.
+func (b *Bittrex) GetTickerDecimal(market string) (*DecimalTicker, error) {
+	return b.GetTickerDecimalCtx(context.Background(), market)
+}
+
+// GetTickerDecimalCtx is like GetTickerDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetTickerDecimalCtx(ctx context.Context, market string) (*DecimalTicker, error) {
+	if b.client.useV3 {
+		return b.v3GetTickerDecimal(ctx, market)
+	}
+	ticker := DecimalTicker{}
+	if err := b.getResult(ctx, "public/getticker?market="+strings.ToUpper(market), false, &ticker); err != nil {
+		return nil, err
+	}
+	return &ticker, nil
+}
+
+// GetMarketSummariesDecimal is like GetMarketSummaries but returns exact decimals.
+func (b *Bittrex) GetMarketSummariesDecimal() ([]*DecimalMarketSummary, error) {
+	return b.GetMarketSummariesDecimalCtx(context.Background())
+}
+
+// GetMarketSummariesDecimalCtx is like GetMarketSummariesDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetMarketSummariesDecimalCtx(ctx context.Context) ([]*DecimalMarketSummary, error) {
+	if b.client.useV3 {
+		return b.v3GetMarketSummariesDecimal(ctx)
+	}
+	marketSummaries := []*DecimalMarketSummary{}
+	if err := b.getResult(ctx, "public/getmarketsummaries", false, &marketSummaries); err != nil {
+		return nil, err
+	}
+	return marketSummaries, nil
+}
+
+// GetOrderBookDecimal is like GetOrderBook but returns exact decimals.
+func (b *Bittrex) GetOrderBookDecimal(market, cat string, depth int) (*DecimalOrderBook, error) {
+	return b.GetOrderBookDecimalCtx(context.Background(), market, cat, depth)
+}
+
+// GetOrderBookDecimalCtx is like GetOrderBookDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderBookDecimalCtx(ctx context.Context, market, cat string, depth int) (*DecimalOrderBook, error) {
+	if cat != "buy" && cat != "sell" && cat != "both" {
+		cat = "both"
+	}
+	if depth > 100 {
+		depth = 100
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	if b.client.useV3 {
+		orderBook, err := b.v3GetOrderBookDecimal(ctx, market, depth)
+		if err != nil {
+			return nil, err
+		}
+		if cat == "buy" {
+			orderBook.Sell = nil
+		} else if cat == "sell" {
+			orderBook.Buy = nil
+		}
+		return orderBook, nil
+	}
+
+	ressource := fmt.Sprintf("public/getorderbook?market=%s&type=%s&depth=%d", strings.ToUpper(market), cat, depth)
+	orderBook := DecimalOrderBook{}
+	var err error
+	if cat == "buy" {
+		err = b.getResult(ctx, ressource, false, &orderBook.Buy)
+	} else if cat == "sell" {
+		err = b.getResult(ctx, ressource, false, &orderBook.Sell)
+	} else {
+		err = b.getResult(ctx, ressource, false, &orderBook)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &orderBook, nil
+}
+
+// Market
+
+// BuyLimitDecimal is like BuyLimit but sends the exact quantity and rate.
+func (b *Bittrex) BuyLimitDecimal(market string, quantity, rate Decimal) (uuid string, err error) {
+	return b.BuyLimitDecimalCtx(context.Background(), market, quantity, rate)
+}
+
+// BuyLimitDecimalCtx is like BuyLimitDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) BuyLimitDecimalCtx(ctx context.Context, market string, quantity, rate Decimal) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrderDecimal(ctx, market, V3DirectionBuy, V3OrderTypeLimit, quantity, rate)
+	}
+	var u Uuid
+	err = b.getResult(ctx, "market/buylimit?market="+market+"&quantity="+quantity.String()+"&rate="+rate.String(), true, &u)
+	return u.Id, err
+}
+
+// BuyMarketDecimal is like BuyMarket but sends the exact quantity.
+func (b *Bittrex) BuyMarketDecimal(market string, quantity Decimal) (uuid string, err error) {
+	return b.BuyMarketDecimalCtx(context.Background(), market, quantity)
+}
+
+// BuyMarketDecimalCtx is like BuyMarketDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) BuyMarketDecimalCtx(ctx context.Context, market string, quantity Decimal) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrderDecimal(ctx, market, V3DirectionBuy, V3OrderTypeMarket, quantity, Decimal{})
+	}
+	var u Uuid
+	err = b.getResult(ctx, "market/buymarket?market="+market+"&quantity="+quantity.String(), true, &u)
+	return u.Id, err
+}
+
+// SellLimitDecimal is like SellLimit but sends the exact quantity and rate.
+func (b *Bittrex) SellLimitDecimal(market string, quantity, rate Decimal) (uuid string, err error) {
+	return b.SellLimitDecimalCtx(context.Background(), market, quantity, rate)
+}
+
+// SellLimitDecimalCtx is like SellLimitDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) SellLimitDecimalCtx(ctx context.Context, market string, quantity, rate Decimal) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrderDecimal(ctx, market, V3DirectionSell, V3OrderTypeLimit, quantity, rate)
+	}
+	var u Uuid
+	err = b.getResult(ctx, "market/selllimit?market="+market+"&quantity="+quantity.String()+"&rate="+rate.String(), true, &u)
+	return u.Id, err
+}
+
+// SellMarketDecimal is like SellMarket but sends the exact quantity.
+func (b *Bittrex) SellMarketDecimal(market string, quantity Decimal) (uuid string, err error) {
+	return b.SellMarketDecimalCtx(context.Background(), market, quantity)
+}
+
+// SellMarketDecimalCtx is like SellMarketDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) SellMarketDecimalCtx(ctx context.Context, market string, quantity Decimal) (uuid string, err error) {
+	if b.client.useV3 {
+		return b.v3PlaceOrderDecimal(ctx, market, V3DirectionSell, V3OrderTypeMarket, quantity, Decimal{})
+	}
+	var u Uuid
+	err = b.getResult(ctx, "market/sellmarket?market="+market+"&quantity="+quantity.String(), true, &u)
+	return u.Id, err
+}
+
+// GetOpenOrdersDecimal is like GetOpenOrders but returns exact decimals.
+func (b *Bittrex) GetOpenOrdersDecimal(market string) ([]*DecimalOrderHistory, error) {
+	return b.GetOpenOrdersDecimalCtx(context.Background(), market)
+}
+
+// GetOpenOrdersDecimalCtx is like GetOpenOrdersDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOpenOrdersDecimalCtx(ctx context.Context, market string) ([]*DecimalOrderHistory, error) {
+	if b.client.useV3 {
+		return b.v3GetOrdersDecimal(ctx, "open", market)
+	}
+	ressource := "market/getopenorders"
+	if market != "all" {
+		ressource += "?market=" + strings.ToUpper(market)
+	}
+	openOrders := []*DecimalOrderHistory{}
+	if err := b.getResult(ctx, ressource, true, &openOrders); err != nil {
+		return nil, err
+	}
+	return openOrders, nil
+}
+
+// Account
+
+// GetBalancesDecimal is like GetBalances but returns exact decimals.
+func (b *Bittrex) GetBalancesDecimal() ([]*DecimalBalance, error) {
+	return b.GetBalancesDecimalCtx(context.Background())
+}
+
+// GetBalancesDecimalCtx is like GetBalancesDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetBalancesDecimalCtx(ctx context.Context) ([]*DecimalBalance, error) {
+	if b.client.useV3 {
+		return b.v3GetBalancesDecimal(ctx)
+	}
+	balances := []*DecimalBalance{}
+	if err := b.getResult(ctx, "account/getbalances", true, &balances); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetBalanceDecimal is like GetBalance but returns exact decimals.
+func (b *Bittrex) GetBalanceDecimal(currency string) (*DecimalBalance, error) {
+	return b.GetBalanceDecimalCtx(context.Background(), currency)
+}
+
+// GetBalanceDecimalCtx is like GetBalanceDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetBalanceDecimalCtx(ctx context.Context, currency string) (*DecimalBalance, error) {
+	if b.client.useV3 {
+		return b.v3GetBalanceDecimal(ctx, currency)
+	}
+	balance := DecimalBalance{}
+	if err := b.getResult(ctx, "account/getbalance?currency="+strings.ToUpper(currency), true, &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// WithdrawDecimal is like Withdraw but sends the exact quantity.
+func (b *Bittrex) WithdrawDecimal(address, currency string, quantity Decimal) (withdrawUuid string, err error) {
+	return b.WithdrawDecimalCtx(context.Background(), address, currency, quantity)
+}
+
+// WithdrawDecimalCtx is like WithdrawDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) WithdrawDecimalCtx(ctx context.Context, address, currency string, quantity Decimal) (withdrawUuid string, err error) {
+	if b.client.useV3 {
+		return b.v3WithdrawDecimal(ctx, address, currency, quantity)
+	}
+	var u Uuid
+	err = b.getResult(ctx, "account/withdraw?currency="+strings.ToUpper(currency)+"&quantity="+quantity.String()+"&address="+address, true, &u)
+	return u.Id, err
+}
+
+// GetOrderHistoryDecimal is like GetOrderHistory but returns exact decimals.
+func (b *Bittrex) GetOrderHistoryDecimal(market string) ([]*DecimalOrderHistory, error) {
+	return b.GetOrderHistoryDecimalCtx(context.Background(), market)
+}
+
+// GetOrderHistoryDecimalCtx is like GetOrderHistoryDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderHistoryDecimalCtx(ctx context.Context, market string) ([]*DecimalOrderHistory, error) {
+	if b.client.useV3 {
+		return b.v3GetOrdersDecimal(ctx, "closed", market)
+	}
+	ressource := "account/getorderhistory"
+	if market != "all" {
+		ressource += "?market=" + market
+	}
+	orders := []*DecimalOrderHistory{}
+	if err := b.getResult(ctx, ressource, true, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetOrderDecimal is like GetOrder but returns exact decimals.
+func (b *Bittrex) GetOrderDecimal(order_uuid string) (*DecimalOrder, error) {
+	return b.GetOrderDecimalCtx(context.Background(), order_uuid)
+}
+
+// GetOrderDecimalCtx is like GetOrderDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetOrderDecimalCtx(ctx context.Context, order_uuid string) (*DecimalOrder, error) {
+	if b.client.useV3 {
+		return b.v3GetOrderDecimal(ctx, order_uuid)
+	}
+	order := DecimalOrder{}
+	if err := b.getResult(ctx, "account/getorder?uuid="+order_uuid, true, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetTicksDecimal is like GetTicks but returns exact decimals.
+func (b *Bittrex) GetTicksDecimal(market string, interval Interval) ([]*DecimalCandle, error) {
+	return b.GetTicksDecimalCtx(context.Background(), market, interval)
+}
+
+// GetTicksDecimalCtx is like GetTicksDecimal but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetTicksDecimalCtx(ctx context.Context, market string, interval Interval) ([]*DecimalCandle, error) {
+	_, ok := CANDLE_INTERVALS[interval]
+	if !ok {
+		return nil, errors.New("wrong interval")
+	}
+	if v3Interval, ok := v3CandleIntervals[interval]; ok && b.client.useV3 {
+		return b.v3GetTicksDecimal(ctx, market, v3Interval)
+	}
+
+	endpoint := b.client.v2(fmt.Sprintf(
+		"pub/market/GetTicks?tickInterval=%s&marketName=%s&_=%d",
+		interval, strings.ToUpper(market), rand.Int(),
+	))
+	candles := []*DecimalCandle{}
+	if err := b.getResult(ctx, endpoint, false, &candles); err != nil {
+		return nil, fmt.Errorf("could not get market ticks: %w", err)
+	}
+	return candles, nil
+}
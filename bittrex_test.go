@@ -0,0 +1,82 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+func TestHistoryFilteredByCurrency(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.AddDeposit("BTC", 2)
+	s.AddDeposit("LTC", 30)
+	b := s.Bittrex()
+	if _, err := b.Withdraw("btc-address", "BTC", 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Withdraw("ltc-address", "LTC", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, err := b.GetDepositHistory("btc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 1 || deposits[0].Currency != "BTC" || deposits[0].Amount != 2 {
+		t.Fatalf("BTC deposits %+v", deposits)
+	}
+	if deposits, err = b.GetDepositHistory("all"); err != nil || len(deposits) != 2 {
+		t.Fatalf("all deposits %+v, %v", deposits, err)
+	}
+
+	withdrawals, err := b.GetWithdrawalHistory("LTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withdrawals) != 1 || withdrawals[0].Currency != "LTC" || withdrawals[0].Amount != 10 {
+		t.Fatalf("LTC withdrawals %+v", withdrawals)
+	}
+	if withdrawals, err = b.GetWithdrawalHistory("all"); err != nil || len(withdrawals) != 2 {
+		t.Fatalf("all withdrawals %+v, %v", withdrawals, err)
+	}
+}
+
+func TestContextAbortsCalls(t *testing.T) {
+	// The server holds every request until the client goes away.
+	arrived := make(chan struct{}, 2)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer s.Close()
+	b := bittrex.New("key", "secret", bittrex.WithBaseURL(s.URL), bittrex.WithHTTPClient(s.Client()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-arrived
+		cancel()
+	}()
+	if _, err := b.GetTickerCtx(ctx, "BTC-LTC"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled call: got %v, want context.Canceled", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := b.GetBalancesCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expired call: got %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("expired call returned after %v", d)
+	}
+	if len(arrived) != 1 {
+		t.Fatal("the expired call never reached the server")
+	}
+}
@@ -0,0 +1,258 @@
+package bittrextest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoInteraction is returned by a replaying Cassette for a request it has no recording of.
+var ErrNoInteraction = errors.New("bittrextest: no recorded interaction matches the request")
+
+// Scrubbed replaces the secrets removed from the recordings.
+const Scrubbed = "SCRUBBED"
+
+// Query parameters and headers scrubbed on record. Volatile query parameters
+// are also ignored when matching a request on replay.
+var (
+	secretParams          = []string{"apikey"}
+	volatileParams        = []string{"nonce", "_"}
+	secretHeaders         = []string{"apisign", "Api-Key", "Api-Signature", "Authorization", "Cookie"}
+	ignoredHeaders        = []string{"Api-Timestamp"}
+	secretResponseHeaders = []string{"Set-Cookie"}
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a scrubbed request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response as received.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Cassette is an http.RoundTripper recording the interactions with the
+// exchange to a file, or replaying them from it. Use it through HTTPClient:
+//
+//	c := bittrextest.NewRecorder("testdata/buy.json", nil)
+//	b := bittrex.NewWithCustomHttpClient(key, secret, c.HTTPClient())
+//	... // talk to the exchange
+//	c.Save()
+//
+// and later, without network nor credentials:
+//
+//	c, _ := bittrextest.NewReplayer("testdata/buy.json")
+//	b := bittrex.NewWithCustomHttpClient("key", "secret", c.HTTPClient())
+//
+// The apikey parameter, the signature and cookie headers, and the cookies
+// set by the responses are scrubbed on record, and the nonce is dropped. On
+// replay, requests match on method, URL and body, ignoring the nonce and the
+// "_" cache buster of GetTicks. Identical requests replay their recordings in
+// order.
+type Cassette struct {
+	// Reuse makes a replaying cassette answer again with the last matching
+	// interaction once the matching ones are all used, ex: for a poller.
+	Reuse bool
+
+	path      string
+	recording bool
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewRecorder returns a cassette sending the requests through transport,
+// http.DefaultTransport if nil, and recording them for Save to write to path.
+func NewRecorder(path string, transport http.RoundTripper) *Cassette {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Cassette{path: path, recording: true, transport: transport}
+}
+
+// NewReplayer returns a cassette answering the requests with the interactions recorded at path.
+func NewReplayer(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{path: path}
+	if err = json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("bittrextest: %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// HTTPClient returns an http.Client using the cassette.
+func (c *Cassette) HTTPClient() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Interactions returns the interactions recorded, or loaded, so far.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interactions := make([]Interaction, len(c.interactions))
+	for i, interaction := range c.interactions {
+		interactions[i] = *interaction
+	}
+	return interactions
+}
+
+// Save writes the recorded interactions to the path of the cassette.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := scrub(req, body)
+	if c.recording {
+		return c.record(req, recorded)
+	}
+	return c.replay(req, recorded)
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header.Clone(), secretResponseHeaders),
+			Body:       string(body),
+		},
+	})
+	c.used = append(c.used, true)
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	key := matchKey(recorded)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last := -1
+	for i, interaction := range c.interactions {
+		if matchKey(interaction.Request) != key {
+			continue
+		}
+		last = i
+		if !c.used[i] {
+			c.used[i] = true
+			return response(req, &interaction.Response), nil
+		}
+	}
+	if last >= 0 && c.Reuse {
+		return response(req, &c.interactions[last].Response), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+func response(req *http.Request, recorded *RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
+
+// scrub returns req as recorded: without secrets nor nonce.
+func scrub(req *http.Request, body []byte) RecordedRequest {
+	u := *req.URL
+	q := u.Query()
+	for _, name := range secretParams {
+		if q.Has(name) {
+			q.Set(name, Scrubbed)
+		}
+	}
+	q.Del("nonce")
+	u.RawQuery = q.Encode()
+
+	header := scrubHeader(req.Header.Clone(), secretHeaders)
+	for _, name := range ignoredHeaders {
+		header.Del(name)
+	}
+	if len(header) == 0 {
+		header = nil
+	}
+	return RecordedRequest{Method: req.Method, URL: u.String(), Header: header, Body: string(body)}
+}
+
+// scrubHeader replaces the values of the names present in header, and returns header.
+func scrubHeader(header http.Header, names []string) http.Header {
+	for _, name := range names {
+		if header.Get(name) != "" {
+			header.Set(name, Scrubbed)
+		}
+	}
+	return header
+}
+
+// matchKey identifies a recorded request regardless of its volatile parts.
+func matchKey(r RecordedRequest) string {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return r.Method + " " + r.URL + "\n" + r.Body
+	}
+	q := u.Query()
+	for _, name := range volatileParams {
+		q.Del(name)
+	}
+	u.RawQuery = q.Encode() // Encode sorts by key
+	return r.Method + " " + strings.ToLower(u.Scheme+"://"+u.Host) + u.Path + "?" + u.RawQuery + "\n" + r.Body
+}
@@ -0,0 +1,154 @@
+package bittrextest_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+// record plays scenario against a fake server through a recording cassette,
+// saves it and returns its path.
+func record(t *testing.T, scenario func(s *bittrextest.Server, b *bittrex.Bittrex)) string {
+	t.Helper()
+	s := bittrextest.NewServer()
+	defer s.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := bittrextest.NewRecorder(path, nil)
+	scenario(s, s.Bittrex(bittrex.WithHTTPClient(c.HTTPClient())))
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func replayer(t *testing.T, path string) (*bittrextest.Cassette, *bittrex.Bittrex) {
+	t.Helper()
+	c, err := bittrextest.NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server is gone: the base URL only has to match the recordings.
+	b := bittrex.New("another key", "another secret", bittrex.WithBaseURL(recordedBaseURL(c)), bittrex.WithHTTPClient(c.HTTPClient()))
+	return c, b
+}
+
+func recordedBaseURL(c *bittrextest.Cassette) string {
+	u := c.Interactions()[0].Request.URL
+	return u[:strings.Index(u, "/api/v1.1")+len("/api/v1.1")]
+}
+
+func TestCassetteScrubsSecrets(t *testing.T) {
+	path := record(t, func(s *bittrextest.Server, b *bittrex.Bittrex) {
+		s.SetBalance("BTC", 1)
+		if _, err := b.GetBalance("BTC"); err != nil {
+			t.Fatal(err)
+		}
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{bittrextest.APIKey, "nonce="} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+	c, _ := replayer(t, path)
+	request := c.Interactions()[0].Request
+	if !strings.Contains(request.URL, "apikey="+bittrextest.Scrubbed) || request.Header.Get("apisign") != bittrextest.Scrubbed {
+		t.Fatalf("request not scrubbed: %+v", request)
+	}
+}
+
+func TestCassetteScrubsCookies(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "__cf_bm", Value: "session-secret"})
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	defer s.Close()
+	c := bittrextest.NewRecorder(filepath.Join(t.TempDir(), "cassette.json"), nil)
+	req, _ := http.NewRequest("GET", s.URL+"/api/v1.1/public/getmarkets", nil)
+	req.Header.Set("Cookie", "__cf_bm=previous-secret")
+	resp, err := c.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(resp.Cookies()) != 1 || resp.Cookies()[0].Value != "session-secret" {
+		t.Fatalf("the live response lost its cookie: %v", resp.Header)
+	}
+	interaction := c.Interactions()[0]
+	if got := interaction.Response.Header.Values("Set-Cookie"); len(got) != 1 || got[0] != bittrextest.Scrubbed {
+		t.Fatalf("Set-Cookie recorded as %v", got)
+	}
+	if got := interaction.Request.Header.Get("Cookie"); got != bittrextest.Scrubbed {
+		t.Fatalf("Cookie recorded as %q", got)
+	}
+}
+
+func TestCassetteReplays(t *testing.T) {
+	var uuid string
+	path := record(t, func(s *bittrextest.Server, b *bittrex.Bittrex) {
+		s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+		s.SetBalance("BTC", 1)
+		uuid, _ = b.BuyLimit("BTC-LTC", 10, 0.01)
+		s.Fill(uuid, 4, 0.01)
+		b.GetOrder(uuid)
+		s.Fill(uuid, 6, 0.01)
+		b.GetOrder(uuid)
+	})
+
+	c, b := replayer(t, path)
+	// Other credentials and nonces, same answers, in order.
+	got, err := b.BuyLimit("BTC-LTC", 10, 0.01)
+	if err != nil || got != uuid {
+		t.Fatalf("got %q, %v, want %q", got, err, uuid)
+	}
+	o, err := b.GetOrder(uuid)
+	if err != nil || o.QuantityRemaining != 6 {
+		t.Fatalf("first GetOrder: %+v, %v", o, err)
+	}
+	if o, err = b.GetOrder(uuid); err != nil || o.QuantityRemaining != 0 || o.IsOpen {
+		t.Fatalf("second GetOrder: %+v, %v", o, err)
+	}
+
+	// Once used up, or never recorded, requests miss.
+	if _, err = b.GetOrder(uuid); !errors.Is(err, bittrextest.ErrNoInteraction) {
+		t.Fatalf("used up: got %v, want ErrNoInteraction", err)
+	}
+	if _, err = b.BuyLimit("BTC-LTC", 11, 0.01); !errors.Is(err, bittrextest.ErrNoInteraction) {
+		t.Fatalf("other quantity: got %v, want ErrNoInteraction", err)
+	}
+	c.Reuse = true
+	if o, err = b.GetOrder(uuid); err != nil || o.QuantityRemaining != 0 {
+		t.Fatalf("reused: %+v, %v", o, err)
+	}
+}
+
+func TestCassetteIgnoresCacheBuster(t *testing.T) {
+	path := record(t, func(s *bittrextest.Server, b *bittrex.Bittrex) {
+		s.SetCandles("BTC-LTC", bittrex.OneMin, &bittrex.Candle{Close: 0.01})
+		if _, err := b.GetTicks("BTC-LTC", bittrex.OneMin); err != nil {
+			t.Fatal(err)
+		}
+	})
+	c, err := bittrextest.NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := c.Interactions()[0].Request.URL
+	root := u[:strings.Index(u, "/Api/v2.0")]
+	b := bittrex.New("", "", bittrex.WithV2BaseURL(root+"/Api/v2.0"), bittrex.WithHTTPClient(c.HTTPClient()))
+	// GetTicks sends a random "_" parameter.
+	candles, err := b.GetTicks("BTC-LTC", bittrex.OneMin)
+	if err != nil || len(candles) != 1 || candles[0].Close != 0.01 {
+		t.Fatalf("got %+v, %v", candles, err)
+	}
+}
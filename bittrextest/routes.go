@@ -0,0 +1,326 @@
+package bittrextest
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// handler answers a route, with s.mu held. An errResponse is sent as a failed envelope.
+type handler func(s *Server, q url.Values) (interface{}, error)
+
+var routes = map[string]handler{
+	"public/getmarkets":                   getMarkets,
+	"public/getcurrencies":                getCurrencies,
+	"public/getticker":                    getTicker,
+	"public/getmarketsummaries":           getMarketSummaries,
+	"public/getmarketsummary":             getMarketSummary,
+	"public/getorderbook":                 getOrderBook,
+	"public/getmarkethistory":             getMarketHistory,
+	"market/buylimit":                     buyLimit,
+	"market/buymarket":                    buyMarket,
+	"market/selllimit":                    sellLimit,
+	"market/sellmarket":                   sellMarket,
+	"market/cancel":                       cancel,
+	"market/getopenorders":                getOpenOrders,
+	"account/getbalances":                 getBalances,
+	"account/getbalance":                  getBalance,
+	"account/getdepositaddress":           getDepositAddress,
+	"account/withdraw":                    withdraw,
+	"account/getorder":                    getOrder,
+	"account/getorderhistory":             getOrderHistory,
+	"account/getwithdrawalhistory":        getWithdrawalHistory,
+	"account/getdeposithistory":           getDepositHistory,
+	"pub/market/GetTicks":                 getTicks,
+	"pub/currency/GetBalanceDistribution": getBalanceDistribution,
+	"key/market/TradeBuy":                 tradeBuy,
+	"key/market/TradeSell":                tradeSell,
+}
+
+// Public
+
+func getMarkets(s *Server, q url.Values) (interface{}, error) {
+	markets := []*bittrex.Market{}
+	names := make([]string, 0, len(s.markets))
+	for name := range s.markets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		markets = append(markets, s.markets[name])
+	}
+	return markets, nil
+}
+
+func getCurrencies(s *Server, q url.Values) (interface{}, error) {
+	currencies := []*bittrex.Currency{}
+	names := make([]string, 0, len(s.currencies))
+	for name := range s.currencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		currencies = append(currencies, s.currencies[name])
+	}
+	return currencies, nil
+}
+
+func getTicker(s *Server, q url.Values) (interface{}, error) {
+	market := strings.ToUpper(q.Get("market"))
+	if t, ok := s.tickers[market]; ok {
+		return t, nil
+	}
+	// Derive the ticker from the order book or the summary.
+	if book, ok := s.books[market]; ok {
+		t := &bittrex.Ticker{}
+		if len(book.Buy) > 0 {
+			t.Bid = book.Buy[0].Rate
+		}
+		if len(book.Sell) > 0 {
+			t.Ask = book.Sell[0].Rate
+		}
+		if trades := s.trades[market]; len(trades) > 0 {
+			t.Last = trades[0].Price
+		}
+		return t, nil
+	}
+	if m, ok := s.summaries[market]; ok {
+		return &bittrex.Ticker{Bid: m.Bid, Ask: m.Ask, Last: m.Last}, nil
+	}
+	return nil, errResponse("INVALID_MARKET")
+}
+
+func getMarketSummaries(s *Server, q url.Values) (interface{}, error) {
+	summaries := []*bittrex.MarketSummary{}
+	names := make([]string, 0, len(s.summaries))
+	for name := range s.summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		summaries = append(summaries, s.summaries[name])
+	}
+	return summaries, nil
+}
+
+func getMarketSummary(s *Server, q url.Values) (interface{}, error) {
+	m, ok := s.summaries[strings.ToUpper(q.Get("market"))]
+	if !ok {
+		return nil, errResponse("INVALID_MARKET")
+	}
+	return []*bittrex.MarketSummary{m}, nil
+}
+
+func getOrderBook(s *Server, q url.Values) (interface{}, error) {
+	market := strings.ToUpper(q.Get("market"))
+	if !s.knownLocked(market) {
+		return nil, errResponse("INVALID_MARKET")
+	}
+	book := s.books[market]
+	if book == nil {
+		book = &bittrex.OrderBook{}
+	}
+	depth := int(parseFloat(q, "depth"))
+	if depth <= 0 || depth > 100 {
+		depth = 100
+	}
+	buy, sell := truncate(book.Buy, depth), truncate(book.Sell, depth)
+	switch q.Get("type") {
+	case "buy":
+		return buy, nil
+	case "sell":
+		return sell, nil
+	case "both":
+		return bittrex.OrderBook{Buy: buy, Sell: sell}, nil
+	}
+	return nil, errResponse("TYPE_INVALID")
+}
+
+func getMarketHistory(s *Server, q url.Values) (interface{}, error) {
+	market := strings.ToUpper(q.Get("market"))
+	if !s.knownLocked(market) {
+		return nil, errResponse("INVALID_MARKET")
+	}
+	trades := s.trades[market]
+	if trades == nil {
+		trades = []*bittrex.Trade{}
+	}
+	return trades, nil
+}
+
+// Market
+
+func buyLimit(s *Server, q url.Values) (interface{}, error) {
+	return s.placeLocked(q.Get("market"), true, parseFloat(q, "quantity"), parseFloat(q, "rate"), false)
+}
+
+func buyMarket(s *Server, q url.Values) (interface{}, error) {
+	return s.placeLocked(q.Get("market"), true, parseFloat(q, "quantity"), 0, true)
+}
+
+func sellLimit(s *Server, q url.Values) (interface{}, error) {
+	return s.placeLocked(q.Get("market"), false, parseFloat(q, "quantity"), parseFloat(q, "rate"), false)
+}
+
+func sellMarket(s *Server, q url.Values) (interface{}, error) {
+	return s.placeLocked(q.Get("market"), false, parseFloat(q, "quantity"), 0, true)
+}
+
+func cancel(s *Server, q url.Values) (interface{}, error) {
+	o, ok := s.orders[q.Get("uuid")]
+	if !ok {
+		return nil, errResponse("UUID_INVALID")
+	}
+	if !o.IsOpen {
+		return nil, errResponse("ORDER_NOT_OPEN")
+	}
+	o.CancelInitiated = true
+	s.closeLocked(o)
+	return nil, nil
+}
+
+func getOpenOrders(s *Server, q url.Values) (interface{}, error) {
+	return s.historyLocked(q.Get("market"), true), nil
+}
+
+// Account
+
+func getBalances(s *Server, q url.Values) (interface{}, error) {
+	balances := []*bittrex.Balance{}
+	names := make([]string, 0, len(s.balances))
+	for name := range s.balances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b := *s.balances[name]
+		balances = append(balances, &b)
+	}
+	return balances, nil
+}
+
+func getBalance(s *Server, q url.Values) (interface{}, error) {
+	currency := strings.ToUpper(q.Get("currency"))
+	if currency == "" {
+		return nil, errResponse("CURRENCY_NOT_PROVIDED")
+	}
+	b := *s.balanceLocked(currency)
+	return &b, nil
+}
+
+func getDepositAddress(s *Server, q url.Values) (interface{}, error) {
+	currency := strings.ToUpper(q.Get("currency"))
+	if currency == "" {
+		return nil, errResponse("CURRENCY_NOT_PROVIDED")
+	}
+	return &bittrex.Address{Currency: currency, Address: address(currency)}, nil
+}
+
+func withdraw(s *Server, q url.Values) (interface{}, error) {
+	currency := strings.ToUpper(q.Get("currency"))
+	quantity := parseFloat(q, "quantity")
+	if currency == "" {
+		return nil, errResponse("CURRENCY_NOT_PROVIDED")
+	}
+	if quantity <= 0 {
+		return nil, errResponse("QUANTITY_NOT_PROVIDED")
+	}
+	if q.Get("address") == "" {
+		return nil, errResponse("ADDRESS_NOT_PROVIDED")
+	}
+	b := s.balanceLocked(currency)
+	if b.Available < quantity {
+		return nil, errResponse("INSUFFICIENT_FUNDS")
+	}
+	b.Balance -= quantity
+	b.Available -= quantity
+	w := &bittrex.Withdrawal{
+		PaymentUuid: newUuid(),
+		Currency:    currency,
+		Amount:      quantity,
+		Address:     q.Get("address"),
+		Opened:      s.now().UTC(),
+		Authorized:  true,
+		TxId:        newUuid(),
+	}
+	s.withdrawals = append(s.withdrawals, w)
+	return bittrex.Uuid{Id: w.PaymentUuid}, nil
+}
+
+func getOrder(s *Server, q url.Values) (interface{}, error) {
+	o, ok := s.orders[q.Get("uuid")]
+	if !ok {
+		return nil, errResponse("UUID_INVALID")
+	}
+	order := o.Order
+	return &order, nil
+}
+
+func getOrderHistory(s *Server, q url.Values) (interface{}, error) {
+	return s.historyLocked(q.Get("market"), false), nil
+}
+
+func getWithdrawalHistory(s *Server, q url.Values) (interface{}, error) {
+	currency := q.Get("currency")
+	withdrawals := []*bittrex.Withdrawal{}
+	for i := len(s.withdrawals) - 1; i >= 0; i-- {
+		if w := s.withdrawals[i]; currency == "" || strings.EqualFold(currency, w.Currency) {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	return withdrawals, nil
+}
+
+func getDepositHistory(s *Server, q url.Values) (interface{}, error) {
+	currency := q.Get("currency")
+	deposits := []*bittrex.Deposit{}
+	for i := len(s.deposits) - 1; i >= 0; i-- {
+		if d := s.deposits[i]; currency == "" || strings.EqualFold(currency, d.Currency) {
+			deposits = append(deposits, d)
+		}
+	}
+	return deposits, nil
+}
+
+// v2.0
+
+func getTicks(s *Server, q url.Values) (interface{}, error) {
+	market := strings.ToUpper(q.Get("marketName"))
+	interval := bittrex.Interval(q.Get("tickInterval"))
+	if !bittrex.CANDLE_INTERVALS[interval] {
+		return nil, errResponse("INVALID_TICK_INTERVAL")
+	}
+	if !s.knownLocked(market) && s.candles[market] == nil {
+		return nil, errResponse("INVALID_MARKET")
+	}
+	candles := s.candles[market][interval]
+	if candles == nil {
+		candles = []*bittrex.Candle{}
+	}
+	return candles, nil
+}
+
+func getBalanceDistribution(s *Server, q url.Values) (interface{}, error) {
+	d, ok := s.distributions[strings.ToUpper(q.Get("currencyName"))]
+	if !ok {
+		return nil, errResponse("INVALID_CURRENCY")
+	}
+	return d, nil
+}
+
+func tradeBuy(s *Server, q url.Values) (interface{}, error) {
+	return s.tradeLocked(q, true)
+}
+
+func tradeSell(s *Server, q url.Values) (interface{}, error) {
+	return s.tradeLocked(q, false)
+}
+
+func truncate(orderbs []bittrex.Orderb, depth int) []bittrex.Orderb {
+	if len(orderbs) > depth {
+		orderbs = orderbs[:depth]
+	}
+	return append([]bittrex.Orderb{}, orderbs...)
+}
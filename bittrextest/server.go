@@ -0,0 +1,766 @@
+// Package bittrextest provides an in-process fake of the Bittrex HTTP API, to
+// test code built on the bittrex package without network nor funds.
+//
+// The server answers the v1.1 public/*, market/* and account/* routes and the
+// v2.0 GetTicks, GetBalanceDistribution, TradeBuy and TradeSell routes with the
+// envelopes of the exchange. Private routes check the apikey, nonce and apisign parameters.
+// Market data, balances and orders are mutable, so that a test can script a
+// scenario:
+//
+//	s := bittrextest.NewServer()
+//	defer s.Close()
+//	s.SetBalance("BTC", 1)
+//	b := s.Bittrex()
+//	uuid, _ := b.BuyLimit("BTC-LTC", 10, 0.01)
+//	s.Fill(uuid, 4, 0.01) // partial fill
+//	s.FailNext("market/buylimit", "INSUFFICIENT_FUNDS")
+//
+// StreamServer is the same for the v3 socket feed.
+package bittrextest
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// Credentials accepted by a new Server.
+const (
+	APIKey    = "bittrextest-key"
+	APISecret = "bittrextest-secret"
+)
+
+// Fee is the commission rate charged on fills.
+const Fee = 0.0025
+
+// Server is a fake Bittrex. Its methods are safe for concurrent use, while
+// the client under test is calling it.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	apiKey    string
+	apiSecret string
+	now       func() time.Time
+
+	markets       map[string]*bittrex.Market
+	currencies    map[string]*bittrex.Currency
+	tickers       map[string]*bittrex.Ticker
+	summaries     map[string]*bittrex.MarketSummary
+	books         map[string]*bittrex.OrderBook
+	trades        map[string][]*bittrex.Trade
+	candles       map[string]map[bittrex.Interval][]*bittrex.Candle
+	distributions map[string]*bittrex.Distribution
+
+	balances    map[string]*bittrex.Balance
+	orders      map[string]*order
+	sequence    []*order // in placement order
+	deposits    []*bittrex.Deposit
+	withdrawals []*bittrex.Withdrawal
+
+	failures map[string][]failure // endpoint -> failures of the next calls
+	requests []string             // endpoints called, in order
+}
+
+// order is an order placed on the server.
+type order struct {
+	bittrex.Order
+	base, currency string
+	buy            bool
+	opened         time.Time
+	reserve        float64 // funds still held by the order
+}
+
+type failure struct {
+	status  int
+	message string
+}
+
+// NewServer starts and returns a new Server, which accepts the APIKey and
+// APISecret credentials. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		apiKey:        APIKey,
+		apiSecret:     APISecret,
+		now:           time.Now,
+		markets:       make(map[string]*bittrex.Market),
+		currencies:    make(map[string]*bittrex.Currency),
+		tickers:       make(map[string]*bittrex.Ticker),
+		summaries:     make(map[string]*bittrex.MarketSummary),
+		books:         make(map[string]*bittrex.OrderBook),
+		trades:        make(map[string][]*bittrex.Trade),
+		candles:       make(map[string]map[bittrex.Interval][]*bittrex.Candle),
+		distributions: make(map[string]*bittrex.Distribution),
+		balances:      make(map[string]*bittrex.Balance),
+		orders:        make(map[string]*order),
+		failures:      make(map[string][]failure),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL returns the v1.1 root of the server, for bittrex.WithBaseURL.
+func (s *Server) BaseURL() string {
+	return s.URL + "/api/v1.1"
+}
+
+// V2BaseURL returns the v2.0 root of the server, for bittrex.WithV2BaseURL.
+func (s *Server) V2BaseURL() string {
+	return s.URL + "/Api/v2.0"
+}
+
+// Bittrex returns a client of the server, using the accepted credentials.
+func (s *Server) Bittrex(opts ...bittrex.Option) *bittrex.Bittrex {
+	s.mu.Lock()
+	key, secret := s.apiKey, s.apiSecret
+	s.mu.Unlock()
+	opts = append([]bittrex.Option{
+		bittrex.WithBaseURL(s.BaseURL()),
+		bittrex.WithV2BaseURL(s.V2BaseURL()),
+		bittrex.WithHTTPClient(s.Client()),
+	}, opts...)
+	return bittrex.New(key, secret, opts...)
+}
+
+// SetCredentials changes the credentials accepted by the private routes.
+func (s *Server) SetCredentials(apiKey, apiSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey, s.apiSecret = apiKey, apiSecret
+}
+
+// SetClock sets the clock used to timestamp orders and withdrawals.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Market data
+
+// SetMarkets adds markets, or replaces those of the same name.
+func (s *Server) SetMarkets(markets ...*bittrex.Market) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range markets {
+		market := *m
+		s.markets[strings.ToUpper(m.MarketName)] = &market
+	}
+}
+
+// SetCurrencies adds currencies, or replaces those of the same name.
+func (s *Server) SetCurrencies(currencies ...*bittrex.Currency) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range currencies {
+		currency := *c
+		s.currencies[strings.ToUpper(c.Currency)] = &currency
+	}
+}
+
+// SetTicker sets the ticker of market.
+func (s *Server) SetTicker(market string, ticker *bittrex.Ticker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := *ticker
+	s.tickers[strings.ToUpper(market)] = &t
+}
+
+// SetMarketSummary sets the summary of summary.MarketName.
+func (s *Server) SetMarketSummary(summary *bittrex.MarketSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := *summary
+	s.summaries[strings.ToUpper(summary.MarketName)] = &m
+}
+
+// SetOrderBook sets the order book of market, best rates first. Market
+// orders fill against it.
+func (s *Server) SetOrderBook(market string, book *bittrex.OrderBook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books[strings.ToUpper(market)] = &bittrex.OrderBook{
+		Buy:  append([]bittrex.Orderb(nil), book.Buy...),
+		Sell: append([]bittrex.Orderb(nil), book.Sell...),
+	}
+}
+
+// SetTrades sets the market history of market, latest first.
+func (s *Server) SetTrades(market string, trades ...*bittrex.Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copies := make([]*bittrex.Trade, len(trades))
+	for i, t := range trades {
+		trade := *t
+		copies[i] = &trade
+	}
+	s.trades[strings.ToUpper(market)] = copies
+}
+
+// SetCandles sets the ticks of market at interval, oldest first.
+func (s *Server) SetCandles(market string, interval bittrex.Interval, candles ...*bittrex.Candle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	market = strings.ToUpper(market)
+	if s.candles[market] == nil {
+		s.candles[market] = make(map[bittrex.Interval][]*bittrex.Candle)
+	}
+	copies := make([]*bittrex.Candle, len(candles))
+	for i, c := range candles {
+		candle := *c
+		copies[i] = &candle
+	}
+	s.candles[market][interval] = copies
+}
+
+// SetDistribution sets the balance distribution of currency.
+func (s *Server) SetDistribution(currency string, distribution *bittrex.Distribution) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := *distribution
+	s.distributions[strings.ToUpper(currency)] = &d
+}
+
+// Account
+
+// SetBalance sets the total of currency. What open orders hold stays held.
+func (s *Server) SetBalance(currency string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.balanceLocked(strings.ToUpper(currency))
+	held := b.Balance - b.Available
+	b.Balance = amount
+	b.Available = amount - held
+}
+
+// Balance returns the balance of currency.
+func (s *Server) Balance(currency string) bittrex.Balance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.balanceLocked(strings.ToUpper(currency))
+}
+
+// AddDeposit credits a deposit of amount of currency.
+func (s *Server) AddDeposit(currency string, amount float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	currency = strings.ToUpper(currency)
+	b := s.balanceLocked(currency)
+	b.Balance += amount
+	b.Available += amount
+	s.deposits = append(s.deposits, &bittrex.Deposit{
+		Id:            int64(len(s.deposits) + 1),
+		Amount:        amount,
+		Currency:      currency,
+		Confirmations: 6,
+		LastUpdated:   s.now().UTC(),
+		TxId:          newUuid(),
+		CryptoAddress: address(currency),
+	})
+}
+
+// Withdrawals returns the withdrawals made through the server.
+func (s *Server) Withdrawals() []bittrex.Withdrawal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	withdrawals := make([]bittrex.Withdrawal, len(s.withdrawals))
+	for i, w := range s.withdrawals {
+		withdrawals[i] = *w
+	}
+	return withdrawals
+}
+
+// Orders
+
+// Order returns the order uuid, ok is false if there is none.
+func (s *Server) Order(uuid string) (o bittrex.Order, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o, ok := s.orders[uuid]; ok {
+		return o.Order, true
+	}
+	return o, false
+}
+
+// Orders returns the orders placed on the server, in placement order.
+func (s *Server) Orders() []bittrex.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orders := make([]bittrex.Order, len(s.sequence))
+	for i, o := range s.sequence {
+		orders[i] = o.Order
+	}
+	return orders
+}
+
+// Fill executes quantity of the open order uuid at rate, updating the
+// balances. The order closes once its whole quantity is filled.
+func (s *Server) Fill(uuid string, quantity, rate float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[uuid]
+	if !ok {
+		return fmt.Errorf("bittrextest: no order %s", uuid)
+	}
+	if !o.IsOpen {
+		return fmt.Errorf("bittrextest: order %s is not open", uuid)
+	}
+	if quantity <= 0 || quantity > o.QuantityRemaining+1e-12 {
+		return fmt.Errorf("bittrextest: cannot fill %v of order %s, %v remaining", quantity, uuid, o.QuantityRemaining)
+	}
+	s.fillLocked(o, quantity, rate)
+	return nil
+}
+
+// Cancel closes the open order uuid, as if canceled on the website.
+func (s *Server) Cancel(uuid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.orders[uuid]
+	if !ok || !o.IsOpen {
+		return fmt.Errorf("bittrextest: no open order %s", uuid)
+	}
+	o.CancelInitiated = true
+	s.closeLocked(o)
+	return nil
+}
+
+// Failures
+
+// FailNext makes the next call to endpoint answer with a failed envelope
+// carrying message, ex: FailNext("market/buylimit", "INSUFFICIENT_FUNDS").
+// Endpoints are named like bittrex.APIError.Endpoint. Calls to FailNext queue up.
+// Private endpoints check the credentials first: a request failing on them
+// does not consume the failure.
+func (s *Server) FailNext(endpoint, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{status: http.StatusOK, message: message})
+}
+
+// FailNextStatus makes the next call to endpoint answer with the HTTP status code.
+func (s *Server) FailNextStatus(endpoint string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[endpoint] = append(s.failures[endpoint], failure{status: status, message: http.StatusText(status)})
+}
+
+// Requests returns the endpoints called so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// HTTP
+
+// errResponse is returned by the handlers to answer a failed envelope.
+type errResponse string
+
+func (e errResponse) Error() string { return string(e) }
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var endpoint string
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v1.1/"):
+		endpoint = strings.ToLower(strings.TrimPrefix(r.URL.Path, "/api/v1.1/"))
+	case strings.HasPrefix(r.URL.Path, "/Api/v2.0/"):
+		endpoint = strings.TrimPrefix(r.URL.Path, "/Api/v2.0/")
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, endpoint)
+	s.mu.Unlock()
+
+	// Like Bittrex, a request with bad credentials fails on them before anything else.
+	if strings.HasPrefix(endpoint, "market/") || strings.HasPrefix(endpoint, "account/") || strings.HasPrefix(endpoint, "key/") {
+		if msg := s.authenticate(r); msg != "" {
+			writeEnvelope(w, nil, msg)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	if failures := s.failures[endpoint]; len(failures) > 0 {
+		f := failures[0]
+		s.failures[endpoint] = failures[1:]
+		s.mu.Unlock()
+		if f.status != http.StatusOK {
+			http.Error(w, f.message, f.status)
+			return
+		}
+		writeEnvelope(w, nil, f.message)
+		return
+	}
+	s.mu.Unlock()
+
+	handler, ok := routes[endpoint]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	result, err := handler(s, r.URL.Query())
+	s.mu.Unlock()
+	if err != nil {
+		writeEnvelope(w, nil, err.Error())
+		return
+	}
+	writeEnvelope(w, result, "")
+}
+
+// authenticate checks the credentials of a private request the way Bittrex
+// does, it returns the error message or "".
+func (s *Server) authenticate(r *http.Request) string {
+	s.mu.Lock()
+	key, secret := s.apiKey, s.apiSecret
+	s.mu.Unlock()
+
+	q := r.URL.Query()
+	if q.Get("apikey") == "" {
+		return "APIKEY_NOT_PROVIDED"
+	}
+	if q.Get("apikey") != key {
+		return "APIKEY_INVALID"
+	}
+	if q.Get("nonce") == "" {
+		return "NONCE_NOT_PROVIDED"
+	}
+	sign := r.Header.Get("apisign")
+	if sign == "" {
+		return "APISIGN_NOT_PROVIDED"
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	mac := hmac.New(sha512.New, []byte(secret))
+	mac.Write([]byte(scheme + "://" + r.Host + r.URL.RequestURI()))
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.ToLower(sign)), []byte(expected)) {
+		return "INVALID_SIGNATURE"
+	}
+	return ""
+}
+
+func writeEnvelope(w http.ResponseWriter, result interface{}, message string) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(struct {
+		Success bool            `json:"success"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}{message == "", message, raw})
+}
+
+// Helpers, called with s.mu held.
+
+func (s *Server) balanceLocked(currency string) *bittrex.Balance {
+	b, ok := s.balances[currency]
+	if !ok {
+		b = &bittrex.Balance{Currency: currency, CryptoAddress: address(currency)}
+		s.balances[currency] = b
+	}
+	return b
+}
+
+// knownLocked reports whether market was given to the server in any way.
+func (s *Server) knownLocked(market string) bool {
+	_, ok := s.markets[market]
+	if !ok {
+		_, ok = s.tickers[market]
+	}
+	if !ok {
+		_, ok = s.summaries[market]
+	}
+	if !ok {
+		_, ok = s.books[market]
+	}
+	return ok
+}
+
+// placeLocked places a limit order, or fills a market order (rate 0) against the book.
+func (s *Server) placeLocked(market string, buy bool, quantity, rate float64, isMarket bool) (interface{}, error) {
+	market = strings.ToUpper(market)
+	if !s.knownLocked(market) {
+		return nil, errResponse("INVALID_MARKET")
+	}
+	if quantity <= 0 {
+		return nil, errResponse("QUANTITY_NOT_PROVIDED")
+	}
+	if !isMarket && rate <= 0 {
+		return nil, errResponse("RATE_NOT_PROVIDED")
+	}
+	if m, ok := s.markets[market]; ok && quantity < m.MinTradeSize {
+		return nil, errResponse("MIN_TRADE_REQUIREMENT_NOT_MET")
+	}
+	parts := strings.SplitN(market, "-", 2)
+	if len(parts) != 2 {
+		return nil, errResponse("INVALID_MARKET")
+	}
+
+	o := &order{
+		Order: bittrex.Order{
+			OrderUuid:         newUuid(),
+			Exchange:          market,
+			Type:              orderType(buy, isMarket),
+			Quantity:          quantity,
+			QuantityRemaining: quantity,
+			Limit:             rate,
+			IsOpen:            true,
+		},
+		base:     parts[0],
+		currency: parts[1],
+		buy:      buy,
+		opened:   s.now().UTC(),
+	}
+	o.Opened = o.opened.Format(bittrex.TIME_FORMAT)
+
+	var levels []bittrex.Orderb
+	if isMarket {
+		if book := s.books[market]; book != nil {
+			levels = book.Sell
+			if !buy {
+				levels = book.Buy
+			}
+		}
+		// A market order holds what the book can fill of it.
+		remaining := quantity
+		for _, level := range levels {
+			dq := math.Min(remaining, level.Quantity)
+			if buy {
+				o.reserve += dq * level.Rate * (1 + Fee)
+			} else {
+				o.reserve += dq
+			}
+			if remaining -= dq; remaining <= 0 {
+				break
+			}
+		}
+	} else if buy {
+		o.reserve = quantity * rate * (1 + Fee)
+		o.Reserved = quantity * rate
+		o.CommissionReserved = quantity * rate * Fee
+	} else {
+		o.reserve = quantity
+		o.Reserved = quantity
+	}
+
+	held := o.currency
+	if buy {
+		held = o.base
+	}
+	b := s.balanceLocked(held)
+	if b.Available < o.reserve-1e-12 {
+		return nil, errResponse("INSUFFICIENT_FUNDS")
+	}
+	b.Available -= o.reserve
+	o.ReserveRemaining = o.Reserved
+	o.CommissionReserveRemaining = o.CommissionReserved
+	s.orders[o.OrderUuid] = o
+	s.sequence = append(s.sequence, o)
+
+	if isMarket {
+		remaining := quantity
+		for _, level := range levels {
+			dq := math.Min(remaining, level.Quantity)
+			s.fillLocked(o, dq, level.Rate)
+			if remaining -= dq; remaining <= 0 {
+				break
+			}
+		}
+		if o.IsOpen {
+			s.closeLocked(o)
+		}
+	}
+	return bittrex.Uuid{Id: o.OrderUuid}, nil
+}
+
+// tradeLocked places an order of the v2.0 trade endpoints. Conditional orders
+// rest like limit orders, whatever the price: trigger them with Fill. Limit
+// orders never cross on placement, so immediate-or-cancel and fill-or-kill
+// ones are canceled right away.
+func (s *Server) tradeLocked(q url.Values, buy bool) (interface{}, error) {
+	orderType := strings.ToUpper(q.Get("OrderType"))
+	if orderType != "LIMIT" && orderType != "MARKET" {
+		return nil, errResponse("ORDERTYPE_INVALID")
+	}
+	timeInForce := q.Get("TimeInEffect")
+	switch timeInForce {
+	case bittrex.GoodTilCancelled, bittrex.ImmediateOrCancel, bittrex.FillOrKill:
+	default:
+		return nil, errResponse("TIMEINEFFECT_INVALID")
+	}
+	condition := q.Get("ConditionType")
+	switch condition {
+	case bittrex.ConditionNone, bittrex.ConditionGreaterThan, bittrex.ConditionLessThan:
+	default:
+		return nil, errResponse("CONDITIONTYPE_INVALID")
+	}
+	target := parseFloat(q, "Target")
+	if condition != bittrex.ConditionNone && target <= 0 {
+		return nil, errResponse("TARGET_INVALID")
+	}
+	isMarket := orderType == "MARKET"
+	result, err := s.placeLocked(q.Get("MarketName"), buy, parseFloat(q, "Quantity"), parseFloat(q, "Rate"), isMarket)
+	if err != nil {
+		return nil, err
+	}
+	o := s.orders[result.(bittrex.Uuid).Id]
+	o.ImmediateOrCancel = timeInForce != bittrex.GoodTilCancelled
+	if condition != bittrex.ConditionNone {
+		o.IsConditional = true
+		o.Condition = condition
+		o.ConditionTarget = strconv.FormatFloat(target, 'f', -1, 64)
+	}
+	if o.IsOpen && o.ImmediateOrCancel {
+		s.closeLocked(o)
+	}
+
+	side := "Sell"
+	if buy {
+		side = "Buy"
+	}
+	return map[string]interface{}{
+		"OrderId":        o.OrderUuid,
+		"MarketName":     o.Exchange,
+		"MarketCurrency": o.currency,
+		"BuyOrSell":      side,
+		"OrderType":      orderType,
+		"Quantity":       o.Quantity,
+		"Rate":           o.Limit,
+	}, nil
+}
+
+func (s *Server) fillLocked(o *order, quantity, rate float64) {
+	total := quantity * rate
+	commission := total * Fee
+	base, currency := s.balanceLocked(o.base), s.balanceLocked(o.currency)
+	if o.buy {
+		spent := total + commission
+		base.Balance -= spent
+		o.reserve -= spent
+		currency.Balance += quantity
+		currency.Available += quantity
+		o.ReserveRemaining = math.Max(0, o.ReserveRemaining-total)
+		o.CommissionReserveRemaining = math.Max(0, o.CommissionReserveRemaining-commission)
+	} else {
+		currency.Balance -= quantity
+		o.reserve -= quantity
+		base.Balance += total - commission
+		base.Available += total - commission
+		o.ReserveRemaining = math.Max(0, o.ReserveRemaining-quantity)
+	}
+	round8(&base.Balance, &base.Available, &currency.Balance, &currency.Available)
+	o.QuantityRemaining -= quantity
+	o.Price += total
+	o.CommissionPaid += commission
+	o.PricePerUnit = o.Price / (o.Quantity - o.QuantityRemaining)
+	round8(&o.QuantityRemaining, &o.Price, &o.CommissionPaid, &o.PricePerUnit, &o.ReserveRemaining, &o.CommissionReserveRemaining)
+	if o.QuantityRemaining <= 1e-12 {
+		o.QuantityRemaining = 0
+		s.closeLocked(o)
+	}
+}
+
+// closeLocked closes o and releases the funds it still holds.
+func (s *Server) closeLocked(o *order) {
+	held := o.currency
+	if o.buy {
+		held = o.base
+	}
+	b := s.balanceLocked(held)
+	b.Available += o.reserve
+	round8(&b.Available)
+	o.reserve = 0
+	o.IsOpen = false
+	o.Closed = s.now().UTC().Format(bittrex.TIME_FORMAT)
+}
+
+func (s *Server) historyLocked(market string, open bool) []*bittrex.OrderHistory {
+	orders := []*bittrex.OrderHistory{}
+	for _, o := range s.sequence {
+		if o.IsOpen != open || (market != "" && !strings.EqualFold(market, o.Exchange)) {
+			continue
+		}
+		orders = append(orders, &bittrex.OrderHistory{
+			OrderUuid:         o.OrderUuid,
+			Exchange:          o.Exchange,
+			TimeStamp:         o.opened,
+			OrderType:         o.Type,
+			Limit:             o.Limit,
+			Quantity:          o.Quantity,
+			QuantityRemaining: o.QuantityRemaining,
+			Commission:        o.CommissionPaid,
+			Price:             o.Price,
+			PricePerUnit:      o.PricePerUnit,
+		})
+	}
+	if !open {
+		// Bittrex lists the history latest first.
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+	return orders
+}
+
+func orderType(buy, isMarket bool) string {
+	kind := "LIMIT"
+	if isMarket {
+		kind = "MARKET"
+	}
+	if buy {
+		return kind + "_BUY"
+	}
+	return kind + "_SELL"
+}
+
+func address(currency string) string {
+	return "bittrextest-" + strings.ToLower(currency)
+}
+
+func newUuid() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// round8 rounds values to the 8 decimals of the exchange, dropping the
+// float64 noise of the balance arithmetic.
+func round8(values ...*float64) {
+	for _, v := range values {
+		*v = math.Round(*v*1e8) / 1e8
+	}
+}
+
+func parseFloat(q map[string][]string, name string) float64 {
+	if v := q[name]; len(v) > 0 {
+		f, _ := strconv.ParseFloat(v[0], 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,218 @@
+package bittrextest_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+func TestLimitOrderFills(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Bid: 0.009, Ask: 0.011, Last: 0.01})
+	s.SetBalance("BTC", 1)
+	b := s.Bittrex()
+
+	uuid, err := b.BuyLimit("BTC-LTC", 10, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The whole order and its commission are held.
+	if got := s.Balance("BTC"); got.Balance != 1 || got.Available != 1-0.1*(1+bittrextest.Fee) {
+		t.Fatalf("BTC %+v after placement", got)
+	}
+	if o, _ := s.Order(uuid); !o.IsOpen || o.QuantityRemaining != 10 || o.Reserved != 0.1 {
+		t.Fatalf("order %+v after placement", o)
+	}
+
+	if err = s.Fill(uuid, 4, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	o, err := b.GetOrder(uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !o.IsOpen || o.QuantityRemaining != 6 || o.Price != 0.04 || o.PricePerUnit != 0.01 || o.CommissionPaid != 0.0001 {
+		t.Fatalf("order %+v after a partial fill", o)
+	}
+	if got := s.Balance("LTC"); got.Balance != 4 || got.Available != 4 {
+		t.Fatalf("LTC %+v", got)
+	}
+
+	if err = s.Fill(uuid, 6, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ = b.GetOrder(uuid); o.IsOpen || o.QuantityRemaining != 0 || o.Closed == "" {
+		t.Fatalf("order %+v after the last fill", o)
+	}
+	spent := 0.1 * (1 + bittrextest.Fee)
+	if got := s.Balance("BTC"); got.Balance != 1-spent || got.Available != 1-spent {
+		t.Fatalf("BTC %+v after the last fill", got)
+	}
+	if history, _ := b.GetOrderHistory("BTC-LTC"); len(history) != 1 || history[0].OrderUuid != uuid {
+		t.Fatalf("history %+v", history)
+	}
+	if err = s.Fill(uuid, 1, 0.01); err == nil {
+		t.Fatal("filled a closed order")
+	}
+}
+
+func TestMarketOrderWalksTheBook(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetOrderBook("BTC-LTC", &bittrex.OrderBook{
+		Buy: []bittrex.Orderb{{Quantity: 2, Rate: 0.01}, {Quantity: 5, Rate: 0.009}},
+	})
+	s.SetBalance("LTC", 10)
+	b := s.Bittrex()
+
+	uuid, err := b.SellMarket("BTC-LTC", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, _ := s.Order(uuid)
+	// The book holds 7, the rest is canceled.
+	if o.IsOpen || o.QuantityRemaining != 3 || o.Price != 0.065 {
+		t.Fatalf("order %+v", o)
+	}
+	if got := s.Balance("LTC"); got.Balance != 3 || got.Available != 3 {
+		t.Fatalf("LTC %+v", got)
+	}
+	if got := s.Balance("BTC"); got.Balance != 0.065*(1-bittrextest.Fee) {
+		t.Fatalf("BTC %+v", got)
+	}
+
+	if _, err = b.SellLimit("BTC-DOGE", 1, 1); !errors.Is(err, bittrex.ErrInvalidMarket) {
+		t.Fatalf("got %v, want ErrInvalidMarket", err)
+	}
+	if _, err = b.SellLimit("BTC-LTC", 100, 0.01); !errors.Is(err, bittrex.ErrInsufficientFunds) {
+		t.Fatalf("got %v, want ErrInsufficientFunds", err)
+	}
+}
+
+func TestCancelReleasesFunds(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetBalance("LTC", 10)
+	b := s.Bittrex()
+
+	uuid, _ := b.SellLimit("BTC-LTC", 6, 0.02)
+	if got := s.Balance("LTC"); got.Available != 4 {
+		t.Fatalf("LTC %+v while the order is open", got)
+	}
+	if open, _ := b.GetOpenOrders("BTC-LTC"); len(open) != 1 {
+		t.Fatalf("open orders %+v", open)
+	}
+	if err := b.CancelOrder(uuid); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Balance("LTC"); got.Balance != 10 || got.Available != 10 {
+		t.Fatalf("LTC %+v after cancel", got)
+	}
+	if err := b.CancelOrder(uuid); !errors.Is(err, bittrex.ErrOrderNotOpen) {
+		t.Fatalf("got %v, want ErrOrderNotOpen", err)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetBalance("BTC", 1.5)
+	s.AddDeposit("ltc", 3)
+	b := s.Bittrex()
+
+	balances, err := b.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, balance := range balances {
+		got[balance.Currency] = balance.Available
+	}
+	if want := map[string]float64{"BTC": 1.5, "LTC": 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("balances %v, want %v", got, want)
+	}
+	if _, err = b.Withdraw("somewhere", "LTC", 5); !errors.Is(err, bittrex.ErrInsufficientFunds) {
+		t.Fatalf("got %v, want ErrInsufficientFunds", err)
+	}
+	if _, err = b.Withdraw("somewhere", "LTC", 1); err != nil {
+		t.Fatal(err)
+	}
+	if balance, _ := b.GetBalance("ltc"); balance.Balance != 2 {
+		t.Fatalf("LTC %+v after withdrawal", balance)
+	}
+	if w := s.Withdrawals(); len(w) != 1 || w[0].Address != "somewhere" || w[0].Amount != 1 {
+		t.Fatalf("withdrawals %+v", w)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	opts := []bittrex.Option{bittrex.WithBaseURL(s.BaseURL()), bittrex.WithHTTPClient(s.Client())}
+
+	if _, err := bittrex.New("wrong", bittrextest.APISecret, opts...).GetBalances(); !errors.Is(err, bittrex.ErrAPIKeyInvalid) {
+		t.Fatalf("got %v, want ErrAPIKeyInvalid", err)
+	}
+	_, err := bittrex.New(bittrextest.APIKey, "wrong", opts...).GetBalances()
+	if apiErr := (*bittrex.APIError)(nil); !errors.As(err, &apiErr) || apiErr.Message != "INVALID_SIGNATURE" {
+		t.Fatalf("got %v, want INVALID_SIGNATURE", err)
+	}
+	// Public routes need no credentials.
+	if _, err = bittrex.New("", "", opts...).GetTicker("BTC-LTC"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetCredentials("other key", "other secret")
+	if _, err = s.Bittrex().GetBalances(); err != nil {
+		t.Fatalf("new credentials: %v", err)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetBalance("BTC", 1)
+	b := s.Bittrex()
+
+	s.FailNext("market/buylimit", "INSUFFICIENT_FUNDS")
+	s.FailNextStatus("market/buylimit", http.StatusServiceUnavailable)
+	if _, err := b.BuyLimit("BTC-LTC", 1, 0.01); !errors.Is(err, bittrex.ErrInsufficientFunds) {
+		t.Fatalf("first call: got %v, want ErrInsufficientFunds", err)
+	}
+	_, err := b.BuyLimit("BTC-LTC", 1, 0.01)
+	if apiErr := (*bittrex.APIError)(nil); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second call: got %v, want a 503", err)
+	}
+	if _, err = b.BuyLimit("BTC-LTC", 1, 0.01); err != nil {
+		t.Fatalf("third call: %v", err)
+	}
+	if len(s.Orders()) != 1 {
+		t.Fatalf("orders %+v, want only the third one", s.Orders())
+	}
+
+	// A request with bad credentials fails on them, and leaves the failure queued.
+	s.FailNext("account/getbalances", "THROTTLED")
+	bad := bittrex.New("wrong", "wrong", bittrex.WithBaseURL(s.BaseURL()), bittrex.WithHTTPClient(s.Client()))
+	if _, err = bad.GetBalances(); !errors.Is(err, bittrex.ErrAPIKeyInvalid) {
+		t.Fatalf("bad credentials: got %v, want ErrAPIKeyInvalid", err)
+	}
+	if _, err = b.GetBalances(); !errors.Is(err, bittrex.ErrRateLimited) {
+		t.Fatalf("got %v, want the queued failure", err)
+	}
+	if _, err = b.GetBalances(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"market/buylimit", "market/buylimit", "market/buylimit", "account/getbalances", "account/getbalances", "account/getbalances"}
+	if got := s.Requests(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,352 @@
+package bittrextest
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/internal/ws"
+)
+
+// StreamServer is a fake of the Bittrex v3 socket feed: a SignalR endpoint
+// answering the negotiate, connect and start requests, the Authenticate,
+// Subscribe and Unsubscribe invocations of the c3 hub, and sending the events
+// given to Publish to the connections subscribed to their channel:
+//
+//	s := bittrextest.NewStreamServer()
+//	defer s.Close()
+//	stream := s.Stream()
+//	stream.Subscribe(ctx, bittrex.TickerChannel("LTC-BTC"))
+//	go stream.Run(ctx)
+//	s.Publish(bittrex.TickerChannel("LTC-BTC"), &bittrex.V3Ticker{Symbol: "LTC-BTC"})
+//
+// The order and balance channels need an authenticated connection.
+type StreamServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	apiKey    string
+	apiSecret string
+	conns     map[*streamConn]bool
+	accepted  int
+	failures  map[string][]string // channel -> error codes of the next subscriptions
+}
+
+// streamConn is a connection to the StreamServer.
+type streamConn struct {
+	*ws.Conn
+	authenticated bool
+	channels      map[string]bool
+}
+
+// NewStreamServer starts and returns a new StreamServer, which accepts the
+// APIKey and APISecret credentials. The caller should call Close when finished.
+func NewStreamServer() *StreamServer {
+	s := &StreamServer{
+		apiKey:    APIKey,
+		apiSecret: APISecret,
+		conns:     make(map[*streamConn]bool),
+		failures:  make(map[string][]string),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// StreamURL returns the SignalR root of the server, for bittrex.WithStreamURL.
+func (s *StreamServer) StreamURL() string {
+	return s.URL + "/signalr"
+}
+
+// Stream returns a stream of the server, using the accepted credentials and
+// reconnecting after 10ms.
+func (s *StreamServer) Stream(opts ...bittrex.StreamOption) *bittrex.Stream {
+	s.mu.Lock()
+	key, secret := s.apiKey, s.apiSecret
+	s.mu.Unlock()
+	opts = append([]bittrex.StreamOption{
+		bittrex.WithStreamURL(s.StreamURL()),
+		bittrex.WithStreamHTTPClient(s.Client()),
+		bittrex.WithStreamReconnect(10*time.Millisecond, 100*time.Millisecond),
+	}, opts...)
+	return bittrex.NewStream(key, secret, opts...)
+}
+
+// SetCredentials changes the credentials accepted by Authenticate.
+func (s *StreamServer) SetCredentials(apiKey, apiSecret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey, s.apiSecret = apiKey, apiSecret
+}
+
+// Publish sends v, as the event of channel, to the connections subscribed to
+// channel, and returns their number. The event is named after the channel,
+// ex: orderBook for orderbook_LTC-BTC_25, and encoded like Bittrex does: JSON
+// compressed with raw deflate, then base64.
+func (s *StreamServer) Publish(channel string, v interface{}) (int, error) {
+	payload, err := encodeStreamPayload(v)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := json.Marshal(map[string]interface{}{
+		"C": "d-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		"M": []interface{}{map[string]interface{}{"H": "C3", "M": streamMethod(channel), "A": []string{payload}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	var conns []*streamConn
+	for c := range s.conns {
+		if c.channels[channel] {
+			conns = append(conns, c)
+		}
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		if err = c.WriteMessage(msg); err != nil {
+			return 0, err
+		}
+	}
+	return len(conns), nil
+}
+
+// Heartbeat sends a heartbeat to every connection.
+func (s *StreamServer) Heartbeat() {
+	msg := []byte(`{"M":[{"H":"C3","M":"heartbeat","A":[]}]}`)
+	for _, c := range s.connections() {
+		c.WriteMessage(msg)
+	}
+}
+
+// Disconnect closes the open connections, as a network failure would.
+func (s *StreamServer) Disconnect() {
+	for _, c := range s.connections() {
+		c.Close()
+	}
+}
+
+// Connections returns the number of connections accepted so far.
+func (s *StreamServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Subscriptions returns the channels the open connections are subscribed to, sorted.
+func (s *StreamServer) Subscriptions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := map[string]bool{}
+	channels := []string{}
+	for c := range s.conns {
+		for channel := range c.channels {
+			if !seen[channel] {
+				seen[channel] = true
+				channels = append(channels, channel)
+			}
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// FailSubscribe makes the next subscription to channel fail with code, ex:
+// FailSubscribe("ticker_LTC-BTC", "INVALID_CHANNEL"). Calls queue up.
+func (s *StreamServer) FailSubscribe(channel, code string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[channel] = append(s.failures[channel], code)
+}
+
+func (s *StreamServer) connections() []*streamConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*streamConn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	return conns
+}
+
+// HTTP
+
+func (s *StreamServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/signalr/negotiate":
+		writeJSON(w, map[string]interface{}{
+			"ConnectionToken":   newUuid(),
+			"ConnectionId":      newUuid(),
+			"ProtocolVersion":   r.URL.Query().Get("clientProtocol"),
+			"TryWebSockets":     true,
+			"KeepAliveTimeout":  20.0,
+			"DisconnectTimeout": 30.0,
+		})
+	case "/signalr/start":
+		if r.URL.Query().Get("connectionToken") == "" {
+			http.Error(w, "missing connection token", http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]string{"Response": "started"})
+	case "/signalr/connect":
+		if r.URL.Query().Get("connectionToken") == "" {
+			http.Error(w, "missing connection token", http.StatusBadRequest)
+			return
+		}
+		conn, err := ws.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		c := &streamConn{Conn: conn, channels: map[string]bool{}}
+		s.mu.Lock()
+		s.conns[c] = true
+		s.accepted++
+		s.mu.Unlock()
+		s.serveConn(c)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveConn answers the invocations of c until it is closed.
+func (s *StreamServer) serveConn(c *streamConn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+	for {
+		data, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		var call struct {
+			H string            `json:"H"`
+			M string            `json:"M"`
+			A []json.RawMessage `json:"A"`
+			I json.RawMessage   `json:"I"`
+		}
+		if err = json.Unmarshal(data, &call); err != nil {
+			continue
+		}
+		id := strings.Trim(string(call.I), `"`)
+		result, hubErr := s.invoke(c, strings.ToLower(call.M), call.A)
+		answer := map[string]interface{}{"I": id}
+		if hubErr != "" {
+			answer["E"] = hubErr
+		} else {
+			answer["R"] = result
+		}
+		msg, _ := json.Marshal(answer)
+		if err = c.WriteMessage(msg); err != nil {
+			return
+		}
+	}
+}
+
+// streamResponse is the result of Authenticate, and of each channel of Subscribe.
+type streamResponse struct {
+	Success   bool   `json:"Success"`
+	ErrorCode string `json:"ErrorCode"`
+}
+
+func (s *StreamServer) invoke(c *streamConn, method string, args []json.RawMessage) (interface{}, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch method {
+	case "authenticate":
+		var key, random, signature string
+		var timestamp int64
+		if len(args) != 4 || json.Unmarshal(args[0], &key) != nil || json.Unmarshal(args[1], &timestamp) != nil ||
+			json.Unmarshal(args[2], &random) != nil || json.Unmarshal(args[3], &signature) != nil {
+			return nil, "Authenticate takes 4 arguments"
+		}
+		if key != s.apiKey {
+			return streamResponse{ErrorCode: "APIKEY_INVALID"}, ""
+		}
+		mac := hmac.New(sha512.New, []byte(s.apiSecret))
+		mac.Write([]byte(strconv.FormatInt(timestamp, 10) + random))
+		if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+			return streamResponse{ErrorCode: "INVALID_SIGNATURE"}, ""
+		}
+		c.authenticated = true
+		return streamResponse{Success: true}, ""
+	case "subscribe", "unsubscribe":
+		var channels []string
+		if len(args) != 1 || json.Unmarshal(args[0], &channels) != nil {
+			return nil, method + " takes a list of channels"
+		}
+		responses := make([]streamResponse, len(channels))
+		for i, channel := range channels {
+			if method == "unsubscribe" {
+				delete(c.channels, channel)
+				responses[i].Success = true
+				continue
+			}
+			if failures := s.failures[channel]; len(failures) > 0 {
+				s.failures[channel] = failures[1:]
+				responses[i].ErrorCode = failures[0]
+				continue
+			}
+			if (channel == bittrex.OrderChannel || channel == bittrex.BalanceChannel) && !c.authenticated {
+				responses[i].ErrorCode = "UNAUTHORIZED"
+				continue
+			}
+			c.channels[channel] = true
+			responses[i].Success = true
+		}
+		return responses, ""
+	}
+	return nil, fmt.Sprintf("unknown method %q", method)
+}
+
+// streamMethod returns the name of the events of channel.
+func streamMethod(channel string) string {
+	kind := channel
+	if i := strings.IndexByte(channel, '_'); i >= 0 {
+		kind = channel[:i]
+	}
+	if kind == "orderbook" {
+		return "orderBook"
+	}
+	return kind
+}
+
+// encodeStreamPayload encodes v like the hub messages of Bittrex: JSON
+// compressed with raw deflate, then base64.
+func encodeStreamPayload(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(data); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,125 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CancelFilter selects the open orders canceled by CancelAllOrders. The zero
+// value cancels every open order.
+type CancelFilter struct {
+	Market    string        // only orders of this market (ex: BTC-LTC), all markets if empty
+	Side      string        // only BUY or SELL orders, both if empty
+	OlderThan time.Duration // only orders opened for longer than this, all if 0
+
+	// Parallelism bounds the number of concurrent cancellations. Defaults to 8.
+	Parallelism int
+	// Retry is applied to each cancellation. Defaults to DefaultRetryPolicy.
+	// Cancellations are safe to retry: an order closed by a lost attempt is
+	// reported as AlreadyClosed.
+	Retry *RetryPolicy
+}
+
+func (f *CancelFilter) match(o *OrderHistory, now time.Time) bool {
+	if f.Market != "" && !strings.EqualFold(f.Market, o.Exchange) {
+		return false
+	}
+	if f.Side != "" && !strings.HasSuffix(strings.ToUpper(o.OrderType), "_"+strings.ToUpper(f.Side)) {
+		return false
+	}
+	return f.OlderThan <= 0 || now.Sub(o.TimeStamp) > f.OlderThan
+}
+
+// CancelResult is the outcome of the cancellation of an order.
+type CancelResult struct {
+	Order         *OrderHistory
+	Attempts      int
+	AlreadyClosed bool  // the order was closed before it could be canceled
+	Err           error // nil if the order is no longer open
+}
+
+// CancelAllOrders cancels the open orders of b selected by filter. See CancelAllOrders.
+func (b *Bittrex) CancelAllOrders(ctx context.Context, filter CancelFilter) ([]*CancelResult, error) {
+	return CancelAllOrders(ctx, b, filter)
+}
+
+// CancelAllOrders lists the open orders of t, and cancels concurrently the
+// ones selected by filter, retrying on transient errors. It returns a result
+// per selected order, in the listing order, and an error if the listing
+// failed or some orders could not be canceled.
+func CancelAllOrders(ctx context.Context, t Trading, filter CancelFilter) ([]*CancelResult, error) {
+	market := filter.Market
+	if market == "" {
+		market = "all"
+	}
+	orders, err := t.GetOpenOrdersCtx(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+	policy := DefaultRetryPolicy()
+	if filter.Retry != nil {
+		policy = *filter.Retry
+	}
+	parallelism := filter.Parallelism
+	if parallelism <= 0 {
+		parallelism = 8
+	}
+
+	now := time.Now()
+	var results []*CancelResult
+	for _, o := range orders {
+		if filter.match(o, now) {
+			results = append(results, &CancelResult{Order: o})
+		}
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+	for _, r := range results {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(r *CancelResult) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			cancelWithRetry(ctx, t, policy, r)
+		}(r)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("bittrex: %d of %d orders not canceled", failed, len(results))
+	}
+	return results, nil
+}
+
+func cancelWithRetry(ctx context.Context, t Trading, policy RetryPolicy, r *CancelResult) {
+	for {
+		r.Attempts++
+		r.Err = t.CancelOrderCtx(ctx, r.Order.OrderUuid)
+		if errors.Is(r.Err, ErrOrderNotOpen) {
+			r.AlreadyClosed, r.Err = true, nil
+		}
+		if r.Err == nil || !policy.shouldRetry(ctx, r.Attempts, "market/cancel", r.Err) {
+			return
+		}
+		delay := policy.delay(r.Attempts)
+		if policy.OnRetry != nil {
+			policy.OnRetry(r.Attempts, "market/cancel", r.Err, delay)
+		}
+		if sleepCtx(ctx, delay) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+func TestCancelAllOrders(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetTicker("BTC-ETH", &bittrex.Ticker{Last: 0.05})
+	s.SetBalance("BTC", 10)
+	s.SetBalance("LTC", 10)
+	b := s.Bittrex()
+	ltcBuy, _ := b.BuyLimit("BTC-LTC", 1, 0.009)
+	ltcSell, _ := b.SellLimit("BTC-LTC", 1, 0.011)
+	ethBuy, _ := b.BuyLimit("BTC-ETH", 1, 0.04)
+
+	results, err := b.CancelAllOrders(context.Background(), bittrex.CancelFilter{Market: "btc-ltc", Side: "SELL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Order.OrderUuid != ltcSell || results[0].Err != nil {
+		t.Fatalf("results %+v", results)
+	}
+
+	// The zero filter cancels the orders of every market.
+	results, err = b.CancelAllOrders(context.Background(), bittrex.CancelFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled := map[string]bool{}
+	for _, r := range results {
+		canceled[r.Order.OrderUuid] = r.Err == nil
+	}
+	if len(canceled) != 2 || !canceled[ltcBuy] || !canceled[ethBuy] {
+		t.Fatalf("results %+v", results)
+	}
+	if open, _ := b.GetOpenOrders("all"); len(open) != 0 {
+		t.Fatalf("open orders %+v", open)
+	}
+}
+
+func TestCancelAllOrdersRetries(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetBalance("BTC", 10)
+	b := s.Bittrex()
+	b.BuyLimit("BTC-LTC", 1, 0.009)
+
+	s.FailNextStatus("market/cancel", http.StatusServiceUnavailable)
+	retry := bittrex.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	results, err := bittrex.CancelAllOrders(context.Background(), b, bittrex.CancelFilter{Retry: &retry})
+	if err != nil || len(results) != 1 || results[0].Attempts != 2 {
+		t.Fatalf("results %+v, %v", results, err)
+	}
+
+	// PaperBittrex lists no orders for an empty market: the zero filter has to ask for "all".
+	p := bittrex.NewPaperBittrex(b, bittrex.WithPaperBalance("BTC", 1))
+	uuid, _ := p.BuyLimit("BTC-LTC", 1, 0.001)
+	if results, err = bittrex.CancelAllOrders(context.Background(), p, bittrex.CancelFilter{}); err != nil || len(results) != 1 || results[0].Order.OrderUuid != uuid {
+		t.Fatalf("paper results %+v, %v", results, err)
+	}
+	if err = p.CancelOrder(uuid); !errors.Is(err, bittrex.ErrOrderNotOpen) {
+		t.Fatalf("got %v, want ErrOrderNotOpen", err)
+	}
+}
@@ -0,0 +1,149 @@
+package bittrex
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kinds of CandleIssue.
+const (
+	CandleGap         = "GAP"          // bars missing before this one
+	CandleDuplicate   = "DUPLICATE"    // same time as a previous bar
+	CandleOutOfOrder  = "OUT_OF_ORDER" // older than the previous bar
+	CandleMisaligned  = "MISALIGNED"   // time not on an interval boundary
+	CandleInvalidOHLC = "INVALID_OHLC" // ex: High < Low, Close above High, negative volume
+)
+
+// CandleIssue is a problem found by ValidateCandles.
+type CandleIssue struct {
+	Kind      string
+	Index     int       // index of the bar in the validated slice
+	TimeStamp time.Time // time of the bar
+	Missing   int       // number of bars missing, for a CandleGap
+	Detail    string
+}
+
+func (i CandleIssue) String() string {
+	s := fmt.Sprintf("%s at %d (%s)", i.Kind, i.Index, i.TimeStamp.UTC().Format(TIME_FORMAT))
+	if i.Detail != "" {
+		s += ": " + i.Detail
+	}
+	return s
+}
+
+// ValidateCandles checks candles, oldest first, against interval and returns
+// the issues found, in the order of the candles. Gaps are measured between
+// consecutive bars in order, ignoring duplicated and out of order ones.
+func ValidateCandles(candles []*Candle, interval Interval) ([]CandleIssue, error) {
+	step := interval.Duration()
+	if step == 0 {
+		return nil, fmt.Errorf("bittrex: unknown interval %q", interval)
+	}
+	var issues []CandleIssue
+	seen := map[time.Time]bool{}
+	var prev time.Time
+	for i, c := range candles {
+		t := c.TimeStamp.UTC()
+		issue := CandleIssue{Index: i, TimeStamp: t}
+		if !t.Truncate(step).Equal(t) {
+			issue.Kind = CandleMisaligned
+			issues = append(issues, issue)
+		}
+		if detail := checkOHLC(c); detail != "" {
+			issue.Kind, issue.Detail = CandleInvalidOHLC, detail
+			issues = append(issues, issue)
+			issue.Detail = ""
+		}
+		switch {
+		case seen[t]:
+			issue.Kind = CandleDuplicate
+			issues = append(issues, issue)
+			continue
+		case i > 0 && t.Before(prev):
+			issue.Kind = CandleOutOfOrder
+			issue.Detail = "after " + prev.Format(TIME_FORMAT)
+			issues = append(issues, issue)
+			seen[t] = true
+			continue
+		case i > 0:
+			if missing := int(t.Truncate(step).Sub(prev.Truncate(step))/step) - 1; missing > 0 {
+				issue.Kind, issue.Missing = CandleGap, missing
+				issue.Detail = fmt.Sprintf("%d bars missing after %s", missing, prev.Format(TIME_FORMAT))
+				issues = append(issues, issue)
+			}
+		}
+		seen[t] = true
+		prev = t
+	}
+	return issues, nil
+}
+
+// checkOHLC returns what is wrong with the prices and volumes of c, or "".
+func checkOHLC(c *Candle) string {
+	switch {
+	case c.High < c.Low:
+		return fmt.Sprintf("high %v below low %v", c.High, c.Low)
+	case c.Open > c.High || c.Open < c.Low:
+		return fmt.Sprintf("open %v out of [%v, %v]", c.Open, c.Low, c.High)
+	case c.Close > c.High || c.Close < c.Low:
+		return fmt.Sprintf("close %v out of [%v, %v]", c.Close, c.Low, c.High)
+	case c.Low <= 0:
+		return fmt.Sprintf("low %v not positive", c.Low)
+	case c.Volume < 0 || c.BaseVolume < 0:
+		return "negative volume"
+	}
+	return ""
+}
+
+// RepairPolicy tells RepairCandles what to do of the bars it cannot keep.
+type RepairPolicy int
+
+const (
+	// RepairDrop drops the misaligned and invalid bars, and leaves the gaps.
+	RepairDrop RepairPolicy = iota
+	// RepairForwardFill replaces the misaligned and invalid bars, and fills
+	// the gaps, with flat bars at the previous close and no volume.
+	RepairForwardFill
+)
+
+// RepairCandles returns candles sorted, without duplicates (the last one of
+// a time wins), and with the misaligned, invalid and missing bars handled by
+// policy. The candles given are not modified.
+func RepairCandles(candles []*Candle, interval Interval, policy RepairPolicy) ([]*Candle, error) {
+	step := interval.Duration()
+	if step == 0 {
+		return nil, fmt.Errorf("bittrex: unknown interval %q", interval)
+	}
+	if policy != RepairDrop && policy != RepairForwardFill {
+		return nil, fmt.Errorf("bittrex: unknown repair policy %d", policy)
+	}
+
+	byTime := map[time.Time]*Candle{}
+	for _, c := range candles {
+		t := c.TimeStamp.UTC()
+		if !t.Truncate(step).Equal(t) || checkOHLC(c) != "" {
+			continue
+		}
+		byTime[t] = c
+	}
+	times := make([]time.Time, 0, len(byTime))
+	for t := range byTime {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	repaired := make([]*Candle, 0, len(times))
+	for _, t := range times {
+		if policy == RepairForwardFill && len(repaired) > 0 {
+			prev := repaired[len(repaired)-1]
+			for missing := prev.TimeStamp.Add(step); missing.Before(t); missing = missing.Add(step) {
+				repaired = append(repaired, &Candle{TimeStamp: missing, Open: prev.Close, High: prev.Close, Low: prev.Close, Close: prev.Close})
+			}
+		}
+		c := *byTime[t]
+		c.TimeStamp = t
+		repaired = append(repaired, &c)
+	}
+	return repaired, nil
+}
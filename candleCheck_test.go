@@ -0,0 +1,128 @@
+package bittrex_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+var checkStart = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+// bar returns a valid hour candle, hours after checkStart, closing at price.
+func bar(hours float64, price float64) *bittrex.Candle {
+	return &bittrex.Candle{
+		TimeStamp: checkStart.Add(time.Duration(hours * float64(time.Hour))),
+		Open:      price, High: price + 1, Low: price - 1, Close: price, Volume: 1, BaseVolume: price,
+	}
+}
+
+type issue struct {
+	Kind    string
+	Index   int
+	Missing int
+}
+
+func issues(t *testing.T, candles []*bittrex.Candle) []issue {
+	t.Helper()
+	found, err := bittrex.ValidateCandles(candles, bittrex.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []issue{}
+	for _, i := range found {
+		if !i.TimeStamp.Equal(candles[i.Index].TimeStamp.UTC()) {
+			t.Errorf("issue %s stamped %v", i, i.TimeStamp)
+		}
+		got = append(got, issue{i.Kind, i.Index, i.Missing})
+	}
+	return got
+}
+
+func TestValidateCandles(t *testing.T) {
+	invalid := func(c *bittrex.Candle, change func(c *bittrex.Candle)) *bittrex.Candle {
+		change(c)
+		return c
+	}
+	for _, c := range []struct {
+		name    string
+		candles []*bittrex.Candle
+		want    []issue
+	}{
+		{"valid", []*bittrex.Candle{bar(0, 10), bar(1, 11), bar(2, 12)}, []issue{}},
+		{"gap", []*bittrex.Candle{bar(0, 10), bar(3, 11), bar(4, 12), bar(6, 13)},
+			[]issue{{bittrex.CandleGap, 1, 2}, {bittrex.CandleGap, 3, 1}}},
+		{"duplicate", []*bittrex.Candle{bar(0, 10), bar(1, 11), bar(1, 12), bar(2, 13)},
+			[]issue{{Kind: bittrex.CandleDuplicate, Index: 2}}},
+		// The gap is measured from the last bar in order, 2.
+		{"out of order", []*bittrex.Candle{bar(0, 10), bar(2, 11), bar(1, 12), bar(4, 13)},
+			[]issue{{bittrex.CandleGap, 1, 1}, {Kind: bittrex.CandleOutOfOrder, Index: 2}, {bittrex.CandleGap, 3, 1}}},
+		{"misaligned", []*bittrex.Candle{bar(0, 10), bar(1.5, 11), bar(2, 12)},
+			[]issue{{Kind: bittrex.CandleMisaligned, Index: 1}}},
+		{"invalid OHLC", []*bittrex.Candle{
+			bar(0, 10),
+			invalid(bar(1, 11), func(c *bittrex.Candle) { c.High, c.Low = 9, 12 }),
+			invalid(bar(2, 12), func(c *bittrex.Candle) { c.Close = 14 }),
+			invalid(bar(3, 13), func(c *bittrex.Candle) { c.Open = 11 }),
+			invalid(bar(4, 14), func(c *bittrex.Candle) { c.Low = 0 }),
+			invalid(bar(5, 15), func(c *bittrex.Candle) { c.Volume = -1 }),
+		}, []issue{
+			{Kind: bittrex.CandleInvalidOHLC, Index: 1}, {Kind: bittrex.CandleInvalidOHLC, Index: 2}, {Kind: bittrex.CandleInvalidOHLC, Index: 3},
+			{Kind: bittrex.CandleInvalidOHLC, Index: 4}, {Kind: bittrex.CandleInvalidOHLC, Index: 5},
+		}},
+	} {
+		if got := issues(t, c.candles); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: issues %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	if _, err := bittrex.ValidateCandles(nil, "week"); err == nil {
+		t.Error("no error for an unknown interval")
+	}
+}
+
+func TestRepairCandles(t *testing.T) {
+	bad := bar(3, 13)
+	bad.High = 5
+	candles := []*bittrex.Candle{bar(2, 12), bar(0, 10), bar(1, 11), bar(1.5, 99), bad, bar(5, 15), bar(1, 21)}
+
+	dropped, err := bittrex.RepairCandles(candles, bittrex.Hour, bittrex.RepairDrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sorted, the last bar of 01:00 wins, 01:30 and 03:00 are dropped, the gaps stay.
+	closes := func(candles []*bittrex.Candle) (hours, prices []float64) {
+		for _, c := range candles {
+			hours = append(hours, c.TimeStamp.Sub(checkStart).Hours())
+			prices = append(prices, c.Close)
+		}
+		return hours, prices
+	}
+	if hours, prices := closes(dropped); !reflect.DeepEqual(hours, []float64{0, 1, 2, 5}) || !reflect.DeepEqual(prices, []float64{10, 21, 12, 15}) {
+		t.Fatalf("dropped: hours %v, closes %v", hours, prices)
+	}
+
+	filled, err := bittrex.RepairCandles(candles, bittrex.Hour, bittrex.RepairForwardFill)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hours, prices := closes(filled); !reflect.DeepEqual(hours, []float64{0, 1, 2, 3, 4, 5}) || !reflect.DeepEqual(prices, []float64{10, 21, 12, 12, 12, 15}) {
+		t.Fatalf("filled: hours %v, closes %v", hours, prices)
+	}
+	// The filling bars are flat, without volume.
+	if want := (bittrex.Candle{TimeStamp: checkStart.Add(3 * time.Hour), Open: 12, High: 12, Low: 12, Close: 12}); *filled[3] != want {
+		t.Fatalf("filling bar %+v, want %+v", *filled[3], want)
+	}
+	if found := issues(t, filled); len(found) != 0 {
+		t.Fatalf("repaired candles have issues %+v", found)
+	}
+
+	// The candles given are not modified.
+	if candles[1].Close != 10 || candles[6].Close != 21 || candles[4].High != 5 {
+		t.Fatal("RepairCandles modified its input")
+	}
+	if _, err = bittrex.RepairCandles(candles, bittrex.Hour, bittrex.RepairPolicy(7)); err == nil {
+		t.Error("no error for an unknown policy")
+	}
+}
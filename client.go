@@ -1,13 +1,17 @@
 package bittrex
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,60 +21,109 @@ type client struct {
 	apiSecret   string
 	httpClient  *http.Client
 	httpTimeout time.Duration
+	baseURL     string // root of v1.1 resources, without trailing slash
+	v2BaseURL   string // root of v2.0 resources, without trailing slash
+	v3BaseURL   string // root of v3 resources, without trailing slash
+	useV3       bool   // back Bittrex methods by v3 where an equivalent exists
+	retry       RetryPolicy
+	limiter     RateLimiter
 }
 
 // NewClient return a new Bittrex HTTP client
 func NewClient(apiKey, apiSecret string) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, 30 * time.Second}
+	return newClient(apiKey, apiSecret)
 }
 
 // NewClientWithCustomHttpConfig returns a new Bittrex HTTP client using the predefined http client
 func NewClientWithCustomHttpConfig(apiKey, apiSecret string, httpClient *http.Client) (c *client) {
-	timeout := httpClient.Timeout
-	if timeout <= 0 {
-		timeout = 30 * time.Second
-	}
-	return &client{apiKey, apiSecret, httpClient, timeout}
+	return newClient(apiKey, apiSecret, WithHTTPClient(httpClient))
 }
 
 // NewClient returns a new Bittrex HTTP client with custom timeout
 func NewClientWithCustomTimeout(apiKey, apiSecret string, timeout time.Duration) (c *client) {
-	return &client{apiKey, apiSecret, &http.Client{}, timeout}
+	return newClient(apiKey, apiSecret, WithTimeout(timeout))
 }
 
-// doTimeoutRequest do a HTTP request with timeout
-func (c *client) doTimeoutRequest(timer *time.Timer, req *http.Request) (*http.Response, error) {
-	// Do the request in the background so we can check the timeout
-	type result struct {
-		resp *http.Response
-		err  error
-	}
-	done := make(chan result, 1)
-	go func() {
-		resp, err := c.httpClient.Do(req)
-		done <- result{resp, err}
-	}()
-	// Wait for the read or the timeout
-	select {
-	case r := <-done:
-		return r.resp, r.err
-	case <-timer.C:
-		return nil, errors.New("timeout on reading data from Bittrex API")
+// newClient returns a client with the default settings overridden by opts.
+func newClient(apiKey, apiSecret string, opts ...Option) *client {
+	c := &client{
+		apiKey:      apiKey,
+		apiSecret:   apiSecret,
+		httpClient:  &http.Client{},
+		httpTimeout: 30 * time.Second,
+		baseURL:     API_BASE + API_VERSION,
+		v2BaseURL:   API_V2_BASE,
+		v3BaseURL:   API_V3_BASE,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
-// do prepare and process HTTP request to Bittrex API
-func (c *client) do(method string, ressource string, payload string, authNeeded bool) (response []byte, err error) {
-	connectTimer := time.NewTimer(c.httpTimeout)
+// v2 returns the absolute URL of a v2.0 resource.
+func (c *client) v2(ressource string) string {
+	return c.v2BaseURL + "/" + ressource
+}
 
+// do prepare and process HTTP request to Bittrex API.
+// The request is bound to ctx, and each attempt is additionally limited by the
+// client timeout. Failed attempts are retried according to the client retry policy.
+func (c *client) do(ctx context.Context, method string, ressource string, payload string, authNeeded bool) (response []byte, err error) {
 	var rawurl string
 	if strings.HasPrefix(ressource, "http") {
 		rawurl = ressource
 	} else {
-		rawurl = fmt.Sprintf("%s%s/%s", API_BASE, API_VERSION, ressource)
+		rawurl = c.baseURL + "/" + ressource
+	}
+	endpoint := c.endpoint(rawurl)
+
+	response, _, err = c.send(ctx, endpoint, authNeeded, IsIdempotent(endpoint), func(ctx context.Context) ([]byte, http.Header, error) {
+		return c.doOnce(ctx, method, rawurl, payload, authNeeded, endpoint)
+	})
+	return response, err
+}
+
+// send runs attempt until it succeeds or the retry policy gives up. It waits
+// on the rate limiter before each attempt and reports throttling back to it.
+func (c *client) send(ctx context.Context, endpoint string, authNeeded, idempotent bool, attempt func(ctx context.Context) ([]byte, http.Header, error)) (response []byte, header http.Header, err error) {
+	for n := 1; ; n++ {
+		if c.limiter != nil {
+			if err = c.limiter.Wait(ctx, endpoint, authNeeded); err != nil {
+				return nil, nil, err
+			}
+		}
+		response, header, err = c.attempt(ctx, attempt)
+		var apiErr *APIError
+		if c.limiter != nil && errors.As(err, &apiErr) && errors.Is(apiErr, ErrRateLimited) {
+			c.limiter.Throttled(endpoint, authNeeded, apiErr.RetryAfter)
+		}
+		if err == nil || !idempotent || !c.retry.shouldRetry(ctx, n, endpoint, err) {
+			return response, header, err
+		}
+		delay := c.retry.delay(n)
+		if c.retry.OnRetry != nil {
+			c.retry.OnRetry(n, endpoint, err, delay)
+		}
+		if werr := sleepCtx(ctx, delay); werr != nil {
+			return response, header, err
+		}
 	}
+}
 
-	req, err := http.NewRequest(method, rawurl, strings.NewReader(payload))
+// attempt runs a single attempt, limited by the client timeout.
+func (c *client) attempt(ctx context.Context, attempt func(ctx context.Context) ([]byte, http.Header, error)) ([]byte, http.Header, error) {
+	if c.httpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.httpTimeout)
+		defer cancel()
+	}
+	return attempt(ctx)
+}
+
+// doOnce performs a single v1.1 or v2.0 request to Bittrex API.
+func (c *client) doOnce(ctx context.Context, method, rawurl, payload string, authNeeded bool, endpoint string) (response []byte, header http.Header, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawurl, strings.NewReader(payload))
 	if err != nil {
 		return
 	}
@@ -96,7 +149,7 @@ func (c *client) do(method string, ressource string, payload string, authNeeded
 		req.Header.Add("apisign", sig)
 	}
 
-	resp, err := c.doTimeoutRequest(connectTimer, req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return
 	}
@@ -105,10 +158,110 @@ func (c *client) do(method string, ressource string, payload string, authNeeded
 	response, err = ioutil.ReadAll(resp.Body)
 	//fmt.Println(fmt.Sprintf("reponse %s", response), err)
 	if err != nil {
-		return response, err
+		return response, resp.Header, err
 	}
-	if resp.StatusCode != 200 {
-		err = errors.New(resp.Status)
+	err = handleErr(resp.StatusCode, endpoint, response)
+	if apiErr, ok := err.(*APIError); ok {
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
 	}
-	return response, err
+	return response, resp.Header, err
+}
+
+// doV3 prepare and process HTTP request to Bittrex API v3.
+// body, if not nil, is sent JSON encoded, and the response is decoded into result if not nil.
+// The response headers are returned as some resources carry data in them (ex: Sequence).
+func (c *client) doV3(ctx context.Context, method, ressource string, body, result interface{}, authNeeded bool) (http.Header, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	rawurl := c.v3BaseURL + "/" + ressource
+	endpoint := c.endpoint(rawurl)
+	idempotent := method == "GET" || method == "HEAD"
+
+	response, header, err := c.send(ctx, endpoint, authNeeded, idempotent, func(ctx context.Context) ([]byte, http.Header, error) {
+		return c.doOnceV3(ctx, method, rawurl, payload, authNeeded, endpoint)
+	})
+	if err != nil {
+		return header, err
+	}
+	if result != nil && len(response) > 0 {
+		err = json.Unmarshal(response, result)
+	}
+	return header, err
+}
+
+// doOnceV3 performs a single request to Bittrex API v3, signed with the v3
+// scheme when authNeeded is set.
+func (c *client) doOnceV3(ctx context.Context, method, rawurl string, payload []byte, authNeeded bool, endpoint string) (response []byte, header http.Header, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawurl, bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+	if len(payload) > 0 {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("Accept", "application/json")
+
+	if authNeeded {
+		if len(c.apiKey) == 0 || len(c.apiSecret) == 0 {
+			err = errors.New("You need to set API Key and API Secret to call this method")
+			return
+		}
+		timestamp := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+		hash := sha512.Sum512(payload)
+		contentHash := hex.EncodeToString(hash[:])
+		mac := hmac.New(sha512.New, []byte(c.apiSecret))
+		mac.Write([]byte(timestamp + req.URL.String() + method + contentHash))
+		req.Header.Add("Api-Key", c.apiKey)
+		req.Header.Add("Api-Timestamp", timestamp)
+		req.Header.Add("Api-Content-Hash", contentHash)
+		req.Header.Add("Api-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+	response, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return response, resp.Header, err
+	}
+	err = handleErrV3(resp.StatusCode, endpoint, response)
+	if apiErr, ok := err.(*APIError); ok {
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return response, resp.Header, err
+}
+
+// parseRetryAfter decodes a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// endpoint returns the resource part of rawurl, without API root nor query string.
+func (c *client) endpoint(rawurl string) string {
+	if i := strings.IndexByte(rawurl, '?'); i >= 0 {
+		rawurl = rawurl[:i]
+	}
+	for _, root := range []string{c.baseURL, c.v2BaseURL, c.v3BaseURL} {
+		if strings.HasPrefix(rawurl, root+"/") {
+			return rawurl[len(root)+1:]
+		}
+	}
+	return rawurl
 }
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"markets":         {"", "list the markets", cmdMarkets},
+		"currencies":      {"", "list the currencies", cmdCurrencies},
+		"ticker":          {"MARKET", "show the ticker of a market", cmdTicker},
+		"summary":         {"[MARKET]", "show the 24h summary of a market, or of all markets", cmdSummary},
+		"book":            {"[-type buy|sell|both] [-depth N] MARKET", "show the order book of a market", cmdBook},
+		"trades":          {"MARKET", "list the latest trades of a market", cmdTrades},
+		"candles":         {"[-interval INTERVAL|TIMEFRAME] MARKET", "list the candles of a market, ex: -interval 4h", cmdCandles},
+		"record":          {"[-dir DIR] [-summaries D] [-book D] [-trades D] [-candles D] [-depth N] [-interval INTERVAL] [-format ndjson|csv] [-gzip=false] [-max-size BYTES] MARKET...", "record market data to files until interrupted", cmdRecord},
+		"balances":        {"[CURRENCY]", "show your balances, or the balance of a currency", cmdBalances},
+		"orders":          {"open [MARKET] | history [MARKET] | get UUID", "list or show your orders", cmdOrders},
+		"buy":             {"MARKET QUANTITY [RATE]", "place a buy order, a market order if RATE is omitted", cmdBuy},
+		"sell":            {"MARKET QUANTITY [RATE]", "place a sell order, a market order if RATE is omitted", cmdSell},
+		"cancel":          {"UUID", "cancel an order", cmdCancel},
+		"cancel-all":      {"[-market MARKET] [-side buy|sell] [-older-than DURATION]", "cancel your open orders", cmdCancelAll},
+		"deposit-address": {"CURRENCY", "show or generate your deposit address of a currency", cmdDepositAddress},
+		"withdraw":        {"CURRENCY QUANTITY ADDRESS", "withdraw funds to an address", cmdWithdraw},
+		"deposits":        {"[CURRENCY]", "list your deposits", cmdDeposits},
+		"withdrawals":     {"[CURRENCY]", "list your withdrawals", cmdWithdrawals},
+	}
+}
+
+// Public
+
+func cmdMarkets(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	markets, err := e.b.GetMarketsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.print(markets)
+}
+
+func cmdCurrencies(ctx context.Context, e *env, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	currencies, err := e.b.GetCurrenciesCtx(ctx)
+	if err != nil {
+		return err
+	}
+	return e.print(currencies)
+}
+
+func cmdTicker(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	ticker, err := e.b.GetTickerCtx(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.print(ticker)
+}
+
+func cmdSummary(ctx context.Context, e *env, args []string) error {
+	var summaries []*bittrex.MarketSummary
+	var err error
+	switch len(args) {
+	case 0:
+		summaries, err = e.b.GetMarketSummariesCtx(ctx)
+	case 1:
+		summaries, err = e.b.GetMarketSummaryCtx(ctx, args[0])
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+	return e.print(summaries)
+}
+
+func cmdBook(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("book", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	cat := fs.String("type", "both", "buy, sell or both")
+	depth := fs.Int("depth", 20, "number of entries, at most 100")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	book, err := e.b.GetOrderBookCtx(ctx, fs.Arg(0), *cat, *depth)
+	if err != nil {
+		return err
+	}
+	return e.print(bookEntries(book))
+}
+
+// bookEntry is a row of the book command: one price level of a side.
+type bookEntry struct {
+	Side     string
+	Quantity float64
+	Rate     float64
+}
+
+// bookEntries flattens both sides of book into rows, bids first.
+func bookEntries(book *bittrex.OrderBook) []bookEntry {
+	entries := make([]bookEntry, 0, len(book.Buy)+len(book.Sell))
+	for _, o := range book.Buy {
+		entries = append(entries, bookEntry{Side: "buy", Quantity: o.Quantity, Rate: o.Rate})
+	}
+	for _, o := range book.Sell {
+		entries = append(entries, bookEntry{Side: "sell", Quantity: o.Quantity, Rate: o.Rate})
+	}
+	return entries
+}
+
+func cmdTrades(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	trades, err := e.b.GetMarketHistoryCtx(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.print(trades)
+}
+
+func cmdCandles(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("candles", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	interval := fs.String("interval", string(bittrex.Hour), "oneMin, fiveMin, thirtyMin, hour, day, or a timeframe like 15m, 4h or 1w")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	var candles []*bittrex.Candle
+	var err error
+	if bittrex.CANDLE_INTERVALS[bittrex.Interval(*interval)] {
+		candles, err = e.b.GetTicksCtx(ctx, fs.Arg(0), bittrex.Interval(*interval))
+	} else {
+		candles, _, err = e.b.GetTicksResampledCtx(ctx, fs.Arg(0), *interval)
+	}
+	if err != nil {
+		return err
+	}
+	return e.print(candles)
+}
+
+func cmdRecord(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	dir := fs.String("dir", "bittrex-data", "directory of the recorded files")
+	summaries := fs.Duration("summaries", time.Minute, "how often to record the summaries, 0 to disable")
+	book := fs.Duration("book", time.Minute, "how often to record the order books, 0 to disable")
+	trades := fs.Duration("trades", time.Minute, "how often to record the trades, 0 to disable")
+	candles := fs.Duration("candles", time.Hour, "how often to record the candles, 0 to disable")
+	depth := fs.Int("depth", 20, "number of order book entries per side")
+	interval := fs.String("interval", string(bittrex.OneMin), "interval of the candles: oneMin, fiveMin, thirtyMin, hour or day")
+	format := fs.String("format", bittrex.RecordNDJSON, "file format: ndjson or csv")
+	compress := fs.Bool("gzip", true, "compress the files")
+	maxSize := fs.Int64("max-size", 0, "start a new file once one holds this many bytes, 0 for one file per day")
+	if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+		return errUsage
+	}
+	r, err := bittrex.NewRecorder(e.b, *dir, fs.Args(),
+		bittrex.WithRecorderFeed(bittrex.FeedSummaries, *summaries),
+		bittrex.WithRecorderFeed(bittrex.FeedOrderBook, *book),
+		bittrex.WithRecorderFeed(bittrex.FeedTrades, *trades),
+		bittrex.WithRecorderFeed(bittrex.FeedCandles, *candles),
+		bittrex.WithRecorderBookDepth(*depth),
+		bittrex.WithRecorderCandleInterval(bittrex.Interval(*interval)),
+		bittrex.WithRecorderFormat(*format),
+		bittrex.WithRecorderCompression(*compress),
+		bittrex.WithRecorderMaxFileSize(*maxSize))
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case err := <-r.Errors():
+				fmt.Fprintln(e.stderr, err)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	fmt.Fprintf(e.stderr, "recording %s to %s, interrupt to stop\n", strings.Join(fs.Args(), ", "), *dir)
+	if err = r.Run(ctx); errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// Account
+
+func cmdBalances(ctx context.Context, e *env, args []string) error {
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	switch len(args) {
+	case 0:
+		balances, err := e.b.GetBalancesCtx(ctx)
+		if err != nil {
+			return err
+		}
+		return e.print(balances)
+	case 1:
+		balance, err := e.b.GetBalanceCtx(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return e.print(balance)
+	}
+	return errUsage
+}
+
+func cmdOrders(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	sub, args := args[0], args[1:]
+	switch {
+	case sub == "open" && len(args) <= 1:
+		market := "all"
+		if len(args) == 1 {
+			market = args[0]
+		}
+		orders, err := e.b.GetOpenOrdersCtx(ctx, market)
+		if err != nil {
+			return err
+		}
+		return e.print(orders)
+	case sub == "history" && len(args) <= 1:
+		market := "all"
+		if len(args) == 1 {
+			market = args[0]
+		}
+		orders, err := e.b.GetOrderHistoryCtx(ctx, market)
+		if err != nil {
+			return err
+		}
+		return e.print(orders)
+	case sub == "get" && len(args) == 1:
+		order, err := e.b.GetOrderCtx(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return e.print(order)
+	}
+	return errUsage
+}
+
+func cmdBuy(ctx context.Context, e *env, args []string) error {
+	return placeOrder(ctx, e, "buy", args)
+}
+
+func cmdSell(ctx context.Context, e *env, args []string) error {
+	return placeOrder(ctx, e, "sell", args)
+}
+
+// placeOrder places a limit order, or a market order when no rate is given.
+// Quantities and rates are sent exactly as typed.
+func placeOrder(ctx context.Context, e *env, side string, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	market := strings.ToUpper(args[0])
+	quantity, err := bittrex.NewDecimalFromString(args[1])
+	if err != nil || quantity.Sign() <= 0 {
+		return fmt.Errorf("invalid quantity %q", args[1])
+	}
+
+	var uuid string
+	if len(args) == 3 {
+		rate, err := bittrex.NewDecimalFromString(args[2])
+		if err != nil || rate.Sign() <= 0 {
+			return fmt.Errorf("invalid rate %q", args[2])
+		}
+		total := quantity.Mul(rate)
+		if err = e.confirm(fmt.Sprintf("%s %s %s at %s (total %s)", title(side), quantity, market, rate, total)); err != nil {
+			return err
+		}
+		if side == "buy" {
+			uuid, err = e.b.BuyLimitDecimalCtx(ctx, market, quantity, rate)
+		} else {
+			uuid, err = e.b.SellLimitDecimalCtx(ctx, market, quantity, rate)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		if err = e.confirm(fmt.Sprintf("%s %s %s at market price", title(side), quantity, market)); err != nil {
+			return err
+		}
+		if side == "buy" {
+			uuid, err = e.b.BuyMarketDecimalCtx(ctx, market, quantity)
+		} else {
+			uuid, err = e.b.SellMarketDecimalCtx(ctx, market, quantity)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return e.print(bittrex.Uuid{Id: uuid})
+}
+
+// title returns the label of side, ex: "Buy".
+func title(side string) string {
+	return strings.ToUpper(side[:1]) + side[1:]
+}
+
+func cmdCancel(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	return e.b.CancelOrderCtx(ctx, args[0])
+}
+
+func cmdCancelAll(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("cancel-all", flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	market := fs.String("market", "", "only the orders of this market")
+	side := fs.String("side", "", "only the buy or sell orders")
+	olderThan := fs.Duration("older-than", 0, "only the orders opened for longer than this")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+	if *side != "" && *side != "buy" && *side != "sell" {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	what := "Cancel all your open orders"
+	if *market != "" {
+		what += " on " + strings.ToUpper(*market)
+	}
+	if *side != "" {
+		what += ", " + *side + " side only"
+	}
+	if *olderThan > 0 {
+		what += ", opened more than " + olderThan.String() + " ago"
+	}
+	if err := e.confirm(what); err != nil {
+		return err
+	}
+	results, err := e.b.CancelAllOrders(ctx, bittrex.CancelFilter{Market: *market, Side: *side, OlderThan: *olderThan})
+	if results == nil && err != nil {
+		return err
+	}
+	if printErr := e.print(cancelRows(results)); printErr != nil {
+		return printErr
+	}
+	return err
+}
+
+// cancelRow is a row of the cancel-all command: the outcome for an order.
+type cancelRow struct {
+	OrderUuid string
+	Exchange  string
+	OrderType string
+	Attempts  int
+	Status    string
+	Error     string
+}
+
+func cancelRows(results []*bittrex.CancelResult) []cancelRow {
+	rows := make([]cancelRow, 0, len(results))
+	for _, r := range results {
+		row := cancelRow{OrderUuid: r.Order.OrderUuid, Exchange: r.Order.Exchange, OrderType: r.Order.OrderType, Attempts: r.Attempts, Status: "canceled"}
+		switch {
+		case r.Err != nil:
+			row.Status, row.Error = "failed", r.Err.Error()
+		case r.AlreadyClosed:
+			row.Status = "closed"
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func cmdDepositAddress(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	address, err := e.b.GetDepositAddressCtx(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return e.print(address)
+}
+
+func cmdWithdraw(ctx context.Context, e *env, args []string) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	currency, address := strings.ToUpper(args[0]), args[2]
+	quantity, err := bittrex.NewDecimalFromString(args[1])
+	if err != nil || quantity.Sign() <= 0 {
+		return fmt.Errorf("invalid quantity %q", args[1])
+	}
+	if err = e.confirm(fmt.Sprintf("Withdraw %s %s to %s", quantity, currency, address)); err != nil {
+		return err
+	}
+	uuid, err := e.b.WithdrawDecimalCtx(ctx, address, currency, quantity)
+	if err != nil {
+		return err
+	}
+	return e.print(bittrex.Uuid{Id: uuid})
+}
+
+func cmdDeposits(ctx context.Context, e *env, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	currency := "all"
+	if len(args) == 1 {
+		currency = args[0]
+	}
+	deposits, err := e.b.GetDepositHistoryCtx(ctx, currency)
+	if err != nil {
+		return err
+	}
+	return e.print(deposits)
+}
+
+func cmdWithdrawals(ctx context.Context, e *env, args []string) error {
+	if len(args) > 1 {
+		return errUsage
+	}
+	if err := e.needCredentials(); err != nil {
+		return err
+	}
+	currency := "all"
+	if len(args) == 1 {
+		currency = args[0]
+	}
+	withdrawals, err := e.b.GetWithdrawalHistoryCtx(ctx, currency)
+	if err != nil {
+		return err
+	}
+	return e.print(withdrawals)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// config holds the credentials and settings of the command.
+// Values are read from the config file, then overridden by the environment
+// and finally by the command line flags.
+type config struct {
+	APIKey    string `json:"apiKey"`
+	APISecret string `json:"apiSecret"`
+	BaseURL   string `json:"baseURL,omitempty"`
+	V3        bool   `json:"v3,omitempty"`
+}
+
+const (
+	envAPIKey    = "BITTREX_API_KEY"
+	envAPISecret = "BITTREX_API_SECRET"
+	envConfig    = "BITTREX_CONFIG"
+)
+
+// defaultConfigPath returns $BITTREX_CONFIG, or ~/.config/bittrex/config.json.
+func defaultConfigPath() string {
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bittrex", "config.json")
+}
+
+// loadConfig reads path, a missing file is not an error unless it was
+// explicitly requested.
+func loadConfig(path string, explicit bool) (*config, error) {
+	cfg := &config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+			return nil, err
+		}
+		if err == nil {
+			if err = json.Unmarshal(data, cfg); err != nil {
+				return nil, errors.New(path + ": " + err.Error())
+			}
+		}
+	}
+	if key := os.Getenv(envAPIKey); key != "" {
+		cfg.APIKey = key
+	}
+	if secret := os.Getenv(envAPISecret); secret != "" {
+		cfg.APISecret = secret
+	}
+	return cfg, nil
+}
@@ -0,0 +1,189 @@
+// Command bittrex is a command line client of the Bittrex exchange.
+//
+// Usage:
+//
+//	bittrex [flags] command [arguments]
+//
+// Credentials are read from the config file (see -config), then from the
+// BITTREX_API_KEY and BITTREX_API_SECRET environment variables, then from
+// the -key and -secret flags. Orders and withdrawals ask for a confirmation
+// unless -yes is given.
+//
+// Results are written as an aligned table, or with -o as json, csv or ndjson
+// (one JSON object per line). -columns selects and orders the columns and
+// -sort sorts the rows, ex:
+//
+//	bittrex -o csv -columns MarketName,BaseVolume -sort -BaseVolume summary
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	usage string // arguments, ex: "MARKET QUANTITY [RATE]"
+	help  string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// env is the state shared by the commands.
+type env struct {
+	b      *bittrex.Bittrex
+	cfg    *config
+	yes    bool
+	out    *renderer
+	stdin  *bufio.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bittrex", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "config file (default $BITTREX_CONFIG or ~/.config/bittrex/config.json)")
+	key := fs.String("key", "", "API key, overrides $BITTREX_API_KEY")
+	secret := fs.String("secret", "", "API secret, overrides $BITTREX_API_SECRET")
+	baseURL := fs.String("base-url", "", "v1.1 API root, ex: http://127.0.0.1:8080/api/v1.1")
+	v3 := fs.Bool("v3", false, "back the calls by the v3 API")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout of each request")
+	yes := fs.Bool("yes", false, "do not ask for confirmation before orders and withdrawals")
+	format := fs.String("o", formatTable, "output format: "+strings.Join(formats, ", "))
+	columns := fs.String("columns", "", "comma separated columns to output, ex: MarketName,Last")
+	sortBy := fs.String("sort", "", "column to sort the rows by, prefix with - for a descending order")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "bittrex: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	out, err := newRenderer(*format, *columns, *sortBy)
+	if err != nil {
+		fmt.Fprintln(stderr, "bittrex:", err)
+		return 2
+	}
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	cfg, err := loadConfig(path, explicit)
+	if err != nil {
+		fmt.Fprintln(stderr, "bittrex:", err)
+		return 1
+	}
+	if *key != "" {
+		cfg.APIKey = *key
+	}
+	if *secret != "" {
+		cfg.APISecret = *secret
+	}
+	if *baseURL != "" {
+		cfg.BaseURL = *baseURL
+	}
+	cfg.V3 = cfg.V3 || *v3
+
+	opts := []bittrex.Option{bittrex.WithTimeout(*timeout)}
+	if cfg.BaseURL != "" {
+		opts = append(opts, bittrex.WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.V3 {
+		opts = append(opts, bittrex.WithAPIV3())
+	}
+	e := &env{
+		b:      bittrex.New(cfg.APIKey, cfg.APISecret, opts...),
+		cfg:    cfg,
+		yes:    *yes,
+		out:    out,
+		stdin:  bufio.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = cmd.run(ctx, e, fs.Args()[1:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "usage: bittrex %s %s\n", name, cmd.usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "bittrex:", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "usage: bittrex [flags] command [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].help)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+}
+
+// needCredentials fails early when a private command runs without credentials.
+func (e *env) needCredentials() error {
+	if e.cfg.APIKey == "" || e.cfg.APISecret == "" {
+		return fmt.Errorf("missing credentials, set %s and %s or use a config file", envAPIKey, envAPISecret)
+	}
+	return nil
+}
+
+// confirm asks the user to approve what, unless -yes was given.
+func (e *env) confirm(what string) error {
+	if e.yes {
+		return nil
+	}
+	fmt.Fprintf(e.stderr, "%s\nProceed? [y/N] ", what)
+	answer, err := e.stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return errors.New("aborted")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errors.New("aborted")
+}
+
+// print writes v to stdout in the selected output format.
+func (e *env) print(v interface{}) error {
+	return e.out.render(e.stdout, v)
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// Output formats.
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+var formats = []string{formatTable, formatJSON, formatCSV, formatNDJSON}
+
+// renderer writes results, a struct or a slice of structs, in one of the
+// output formats. Columns are the exported fields of the structs.
+type renderer struct {
+	format  string
+	columns []string // selected columns, all of them if empty
+	sortBy  string   // column to sort the rows by, "" keeps the API order
+	desc    bool
+}
+
+// newRenderer checks the output flags. columns is a comma separated list of
+// column names, sortBy a column name, prefixed by - for a descending order.
+func newRenderer(format, columns, sortBy string) (*renderer, error) {
+	r := &renderer{format: strings.ToLower(format)}
+	ok := false
+	for _, f := range formats {
+		ok = ok || r.format == f
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, want one of %s", format, strings.Join(formats, ", "))
+	}
+	for _, c := range strings.Split(columns, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			r.columns = append(r.columns, c)
+		}
+	}
+	if strings.HasPrefix(sortBy, "-") {
+		r.desc = true
+		sortBy = sortBy[1:]
+	}
+	r.sortBy = sortBy
+	return r, nil
+}
+
+// column is an exported field of the rendered struct type.
+type column struct {
+	name  string
+	index int
+}
+
+// render writes v to w. v is a struct, a slice of structs, or pointers to them.
+func (r *renderer) render(w io.Writer, v interface{}) error {
+	rows, typ := rowsOf(reflect.ValueOf(v))
+	if typ == nil || typ.Kind() != reflect.Struct {
+		// Not tabular: only JSON makes sense.
+		return writeJSON(w, v, r.format != formatNDJSON)
+	}
+	columns, err := r.selectColumns(typ)
+	if err != nil {
+		return err
+	}
+	if r.sortBy != "" {
+		by, err := findColumn(typ, r.sortBy)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			c := compare(rows[i].Field(by.index), rows[j].Field(by.index))
+			if r.desc {
+				return c > 0
+			}
+			return c < 0
+		})
+	}
+
+	switch r.format {
+	case formatJSON:
+		if len(r.columns) == 0 {
+			// Keep the JSON encoding of the models, the rows may have been sorted.
+			if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Slice {
+				return writeJSON(w, v, true)
+			}
+			values := make([]interface{}, len(rows))
+			for i, row := range rows {
+				values[i] = row.Interface()
+			}
+			return writeJSON(w, values, true)
+		}
+		objects := make([]json.RawMessage, len(rows))
+		for i, row := range rows {
+			if objects[i], err = objectOf(row, columns); err != nil {
+				return err
+			}
+		}
+		if reflect.Indirect(reflect.ValueOf(v)).Kind() != reflect.Slice && len(objects) == 1 {
+			return writeJSON(w, objects[0], true)
+		}
+		return writeJSON(w, objects, true)
+	case formatNDJSON:
+		for _, row := range rows {
+			var line []byte
+			if len(r.columns) == 0 {
+				line, err = json.Marshal(row.Interface())
+			} else {
+				line, err = objectOf(row, columns)
+			}
+			if err != nil {
+				return err
+			}
+			if _, err = w.Write(append(line, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = c.name
+		}
+		cw.Write(record)
+		for _, row := range rows {
+			for i, c := range columns {
+				record[i] = cell(row.Field(c.index))
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for i, c := range columns {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, c.name)
+		}
+		fmt.Fprintln(tw)
+		for _, row := range rows {
+			for i, c := range columns {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, cell(row.Field(c.index)))
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	}
+}
+
+// rowsOf returns the struct values held by v, and their type.
+func rowsOf(v reflect.Value) ([]reflect.Value, reflect.Type) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return []reflect.Value{v}, v.Type()
+	}
+	typ := v.Type().Elem()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	rows := make([]reflect.Value, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		row := v.Index(i)
+		for row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				break
+			}
+			row = row.Elem()
+		}
+		if row.Kind() == reflect.Struct {
+			rows = append(rows, row)
+		}
+	}
+	return rows, typ
+}
+
+func (r *renderer) selectColumns(typ reflect.Type) ([]column, error) {
+	if len(r.columns) == 0 {
+		var columns []column
+		for i := 0; i < typ.NumField(); i++ {
+			if f := typ.Field(i); f.IsExported() {
+				columns = append(columns, column{name: f.Name, index: i})
+			}
+		}
+		return columns, nil
+	}
+	columns := make([]column, len(r.columns))
+	for i, name := range r.columns {
+		c, err := findColumn(typ, name)
+		if err != nil {
+			return nil, err
+		}
+		columns[i] = c
+	}
+	return columns, nil
+}
+
+// findColumn looks name up among the exported fields of typ, ignoring case.
+func findColumn(typ reflect.Type, name string) (column, error) {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if strings.EqualFold(f.Name, name) {
+			return column{name: f.Name, index: i}, nil
+		}
+		names = append(names, f.Name)
+	}
+	return column{}, fmt.Errorf("unknown column %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+// cell formats a field for the table and CSV outputs.
+func cell(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(bittrex.TIME_FORMAT)
+	case fmt.Stringer:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Map, reflect.Ptr, reflect.Interface:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return fmt.Sprint(v.Interface())
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// objectOf encodes the selected columns of row as a JSON object, in order.
+func objectOf(row reflect.Value, columns []column) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, c := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(c.name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value := row.Field(c.index).Interface()
+		if t, ok := value.(time.Time); ok {
+			value = cell(reflect.ValueOf(t))
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// compare orders two values of a column: numbers and times by value,
+// anything else by its formatted text.
+func compare(a, b reflect.Value) int {
+	switch x := a.Interface().(type) {
+	case time.Time:
+		y := b.Interface().(time.Time)
+		switch {
+		case x.Before(y):
+			return -1
+		case x.After(y):
+			return 1
+		}
+		return 0
+	case bittrex.Decimal:
+		return x.Cmp(b.Interface().(bittrex.Decimal))
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloat(float64(a.Int()), float64(b.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(a.Uint()), float64(b.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(a.Float(), b.Float())
+	case reflect.Bool:
+		return compareFloat(boolFloat(a.Bool()), boolFloat(b.Bool()))
+	case reflect.String:
+		// timestamps kept as strings, ex: MarketSummary.TimeStamp, sort fine as text
+		return strings.Compare(a.String(), b.String())
+	}
+	return strings.Compare(cell(a), cell(b))
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func writeJSON(w io.Writer, v interface{}, indent bool) error {
+	enc := json.NewEncoder(w)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+var at = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func history() []*bittrex.OrderHistory {
+	return []*bittrex.OrderHistory{
+		{OrderUuid: "a", Exchange: "BTC-LTC", TimeStamp: at, OrderType: "LIMIT_SELL", Quantity: 9, Limit: 0.01},
+		{OrderUuid: "b", Exchange: "BTC-LTC", TimeStamp: at.Add(time.Minute), OrderType: "LIMIT_BUY", Quantity: 100, Limit: 0.009},
+		{OrderUuid: "c", Exchange: "BTC-ETH", TimeStamp: at.Add(-time.Minute), OrderType: "MARKET_SELL", Quantity: 10},
+	}
+}
+
+func render(t *testing.T, format, columns, sortBy string, v interface{}) string {
+	t.Helper()
+	r, err := newRenderer(format, columns, sortBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = r.render(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRenderCSV(t *testing.T) {
+	records, err := csv.NewReader(strings.NewReader(render(t, formatCSV, "", "", history()))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"OrderUuid", "Exchange", "TimeStamp", "OrderType", "Limit", "Quantity", "QuantityRemaining", "Commission", "Price", "PricePerUnit"}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Fatalf("header %v, want %v", records[0], want)
+	}
+	if len(records) != 4 || !reflect.DeepEqual(records[1][:6], []string{"a", "BTC-LTC", at.Format(bittrex.TIME_FORMAT), "LIMIT_SELL", "0.01", "9"}) {
+		t.Fatalf("records %v", records)
+	}
+
+	// Selected columns, in their order, whatever their case.
+	records, _ = csv.NewReader(strings.NewReader(render(t, formatCSV, "quantity, orderuuid", "", history()))).ReadAll()
+	if want := [][]string{{"Quantity", "OrderUuid"}, {"9", "a"}, {"100", "b"}, {"10", "c"}}; !reflect.DeepEqual(records, want) {
+		t.Fatalf("records %v, want %v", records, want)
+	}
+}
+
+func TestRenderNDJSON(t *testing.T) {
+	out := render(t, formatNDJSON, "OrderUuid,Quantity,TimeStamp", "", history())
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("%d lines, want one per row:\n%s", len(lines), out)
+	}
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"OrderUuid": "a", "Quantity": 9.0, "TimeStamp": at.Format(bittrex.TIME_FORMAT)}
+	if !reflect.DeepEqual(first, want) {
+		t.Fatalf("first line %v, want %v", first, want)
+	}
+	// Without columns, each line is the model.
+	for _, line := range strings.Split(strings.TrimSuffix(render(t, formatNDJSON, "", "", history()), "\n"), "\n") {
+		var o bittrex.OrderHistory
+		if err := json.Unmarshal([]byte(line), &o); err != nil || o.OrderUuid == "" {
+			t.Fatalf("line %s: %+v, %v", line, o, err)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	if _, err := newRenderer("xml", "", ""); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+	for _, test := range []struct{ columns, sortBy string }{{"OrderUuid,Nope", ""}, {"", "-nope"}} {
+		r, err := newRenderer(formatCSV, test.columns, test.sortBy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.render(&bytes.Buffer{}, history())
+		if err == nil || !strings.Contains(strings.ToLower(err.Error()), `unknown column "nope"`) {
+			t.Fatalf("%+v: got %v, want an unknown column error", test, err)
+		}
+	}
+}
+
+func TestRenderSort(t *testing.T) {
+	column := func(out string, i int) []string {
+		records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values []string
+		for _, record := range records[1:] {
+			values = append(values, record[i])
+		}
+		return values
+	}
+	// Numbers by value, not as text.
+	if got := column(render(t, formatCSV, "OrderUuid,Quantity", "quantity", history()), 1); !reflect.DeepEqual(got, []string{"9", "10", "100"}) {
+		t.Errorf("by quantity %v", got)
+	}
+	if got := column(render(t, formatCSV, "OrderUuid,Quantity", "-Quantity", history()), 1); !reflect.DeepEqual(got, []string{"100", "10", "9"}) {
+		t.Errorf("by descending quantity %v", got)
+	}
+	// Strings as text, and times by value.
+	if got := column(render(t, formatCSV, "OrderType", "OrderType", history()), 0); !reflect.DeepEqual(got, []string{"LIMIT_BUY", "LIMIT_SELL", "MARKET_SELL"}) {
+		t.Errorf("by order type %v", got)
+	}
+	if got := column(render(t, formatCSV, "OrderUuid", "TimeStamp", history()), 0); !reflect.DeepEqual(got, []string{"c", "a", "b"}) {
+		t.Errorf("by time %v", got)
+	}
+
+	candles := []*bittrex.Candle{
+		{TimeStamp: at, Close: 2.5, Volume: 20},
+		{TimeStamp: at.Add(time.Hour), Close: 10, Volume: 3},
+		{TimeStamp: at.Add(2 * time.Hour), Close: 9.75, Volume: 100},
+	}
+	if got := column(render(t, formatCSV, "Close", "close", candles), 0); !reflect.DeepEqual(got, []string{"2.5", "9.75", "10"}) {
+		t.Errorf("candles by close %v", got)
+	}
+	if got := column(render(t, formatCSV, "Volume", "-volume", candles), 0); !reflect.DeepEqual(got, []string{"100", "20", "3"}) {
+		t.Errorf("candles by descending volume %v", got)
+	}
+	// Sorting leaves the input alone, and the JSON output keeps the models.
+	var sorted []bittrex.Candle
+	if err := json.Unmarshal([]byte(render(t, formatJSON, "", "-TimeStamp", candles)), &sorted); err != nil {
+		t.Fatal(err)
+	}
+	if len(sorted) != 3 || !sorted[0].TimeStamp.Equal(at.Add(2*time.Hour)) || candles[0].Close != 2.5 {
+		t.Fatalf("sorted %+v", sorted)
+	}
+}
@@ -0,0 +1,319 @@
+package bittrex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact, arbitrary precision decimal number, suitable for prices
+// and quantities. It keeps the digits it was parsed from, trailing zeros
+// included, so that the exchange strings round-trip unchanged.
+//
+// Decimal values are immutable. The zero value is 0.
+type Decimal struct {
+	coef  *big.Int // nil means 0
+	scale int32    // number of digits after the decimal point, >= 0
+}
+
+var bigTen = big.NewInt(10)
+
+// maxDecimalExponent bounds the exponents accepted by NewDecimalFromString, so
+// that a hostile "1e2000000000" cannot make it allocate gigabytes of digits.
+const maxDecimalExponent = 1000
+
+// NewDecimal returns unscaled * 10^-scale, ex: NewDecimal(12345, 8) is 0.00012345.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// NewDecimalFromInt returns i as a Decimal.
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns the shortest decimal representation of f.
+// Floats read from exchange strings of up to 15 significant digits convert back exactly.
+func NewDecimalFromFloat(f float64) Decimal {
+	d, err := NewDecimalFromString(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Decimal{} // NaN or infinity
+	}
+	return d
+}
+
+// NewDecimalFromString parses s, ex: "0.00012345", "-12", "1E-08". Exponents
+// are limited to ±1000.
+func NewDecimalFromString(s string) (Decimal, error) {
+	orig := s
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("bittrex: invalid decimal %q", orig)
+		}
+		if exp > maxDecimalExponent || exp < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("bittrex: decimal exponent out of range %q", orig)
+		}
+		s = s[:i]
+	}
+	digits := s
+	scale := int64(0)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		scale = int64(len(s) - i - 1)
+	}
+	trimmed := strings.TrimLeft(digits, "+-")
+	if trimmed == "" || strings.IndexFunc(trimmed, func(r rune) bool { return r < '0' || r > '9' }) >= 0 || len(digits)-len(trimmed) > 1 {
+		return Decimal{}, fmt.Errorf("bittrex: invalid decimal %q", orig)
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("bittrex: invalid decimal %q", orig)
+	}
+	if scale -= exp; scale > math.MaxInt32 {
+		return Decimal{}, fmt.Errorf("bittrex: decimal exponent out of range %q", orig)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return newDecimal(coef, int32(scale)), nil
+}
+
+// MustDecimal is like NewDecimalFromString but panics if s is not a valid decimal.
+func MustDecimal(s string) Decimal {
+	d, err := NewDecimalFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		coef = new(big.Int).Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescaled returns the coefficient of d expressed with scale digits, scale >= d.scale.
+func (d Decimal) rescaled(scale int32) *big.Int {
+	c := new(big.Int).Set(d.coefficient())
+	if scale > d.scale {
+		c.Mul(c, pow10(scale-d.scale))
+	}
+	return c
+}
+
+func maxScale(a, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 { return d.scale }
+
+// Add returns d + d2.
+func (d Decimal) Add(d2 Decimal) Decimal {
+	s := maxScale(d, d2)
+	return newDecimal(new(big.Int).Add(d.rescaled(s), d2.rescaled(s)), s)
+}
+
+// Sub returns d - d2.
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	s := maxScale(d, d2)
+	return newDecimal(new(big.Int).Sub(d.rescaled(s), d2.rescaled(s)), s)
+}
+
+// Mul returns d * d2, exactly.
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return newDecimal(new(big.Int).Mul(d.coefficient(), d2.coefficient()), d.scale+d2.scale)
+}
+
+// Div returns d / d2 rounded half away from zero to places digits.
+// It panics if d2 is zero.
+func (d Decimal) Div(d2 Decimal, places int32) Decimal {
+	if d2.IsZero() {
+		panic("bittrex: decimal division by zero")
+	}
+	// d / d2 = (d.coef / d2.coef) * 10^(d2.scale - d.scale), computed with one extra digit for rounding.
+	num := new(big.Int).Set(d.coefficient())
+	den := new(big.Int).Set(d2.coefficient())
+	if e := places + 1 - d.scale + d2.scale; e >= 0 {
+		num.Mul(num, pow10(e))
+	} else {
+		den.Mul(den, pow10(-e))
+	}
+	q := new(big.Int).Quo(num, den)
+	return newDecimal(q, places+1).Round(places)
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return newDecimal(new(big.Int).Neg(d.coefficient()), d.scale)
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	return newDecimal(new(big.Int).Abs(d.coefficient()), d.scale)
+}
+
+// Round returns d rounded half away from zero to places digits after the decimal point.
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return d
+	}
+	div := pow10(d.scale - places)
+	q, r := new(big.Int).QuoRem(d.coefficient(), div, new(big.Int))
+	// |r| * 2 >= div means rounding away from zero
+	if r.Abs(r).Lsh(r, 1).Cmp(div) >= 0 {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return newDecimal(q, places)
+}
+
+// Truncate returns d truncated toward zero to places digits after the decimal point.
+func (d Decimal) Truncate(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return d
+	}
+	return newDecimal(new(big.Int).Quo(d.coefficient(), pow10(d.scale-places)), places)
+}
+
+// RoundDown returns d rounded toward negative infinity to a multiple of step,
+// ex: the largest quantity not above d allowed by a step size.
+func (d Decimal) RoundDown(step Decimal) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	s := maxScale(d, step)
+	c := d.rescaled(s)
+	st := step.rescaled(s)
+	q := new(big.Int).Div(c, st) // Euclidean division, floor for a positive step
+	return newDecimal(q.Mul(q, st), s)
+}
+
+// Cmp compares d and d2 and returns -1, 0 or +1.
+func (d Decimal) Cmp(d2 Decimal) int {
+	s := maxScale(d, d2)
+	return d.rescaled(s).Cmp(d2.rescaled(s))
+}
+
+// Equal reports whether d and d2 are the same number, whatever their scales.
+func (d Decimal) Equal(d2 Decimal) bool { return d.Cmp(d2) == 0 }
+
+// LessThan reports whether d < d2.
+func (d Decimal) LessThan(d2 Decimal) bool { return d.Cmp(d2) < 0 }
+
+// GreaterThan reports whether d > d2.
+func (d Decimal) GreaterThan(d2 Decimal) bool { return d.Cmp(d2) > 0 }
+
+// Sign returns -1, 0 or +1 according to the sign of d.
+func (d Decimal) Sign() int { return d.coefficient().Sign() }
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool { return d.Sign() == 0 }
+
+// Float64 returns the nearest float64 to d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain notation, with exactly Scale digits after the decimal point.
+func (d Decimal) String() string {
+	c := d.coefficient()
+	digits := new(big.Int).Abs(c).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(d.scale)] + "." + digits[len(digits)-int(d.scale):]
+	}
+	if c.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed returns d rounded to places digits, padded with zeros if needed.
+func (d Decimal) StringFixed(places int32) string {
+	r := d.Round(places)
+	if r.scale < places {
+		r = newDecimal(r.rescaled(places), places)
+	}
+	return r.String()
+}
+
+// MarshalJSON encodes d as a JSON number with its exact digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or a JSON string holding a number.
+// null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+	parsed, err := NewDecimalFromString(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes d like String.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a number written like String does.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("bittrex: empty decimal")
+	}
+	parsed, err := NewDecimalFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,228 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Decimal flavoured models. They decode the same JSON as their float64
+// counterparts, keeping the exact digits sent by Bittrex.
+
+type DecimalTicker struct {
+	Bid  Decimal `json:"Bid"`
+	Ask  Decimal `json:"Ask"`
+	Last Decimal `json:"Last"`
+}
+
+type DecimalOrderb struct {
+	Quantity Decimal `json:"Quantity"`
+	Rate     Decimal `json:"Rate"`
+}
+
+type DecimalOrderBook struct {
+	Buy  []DecimalOrderb `json:"buy"`
+	Sell []DecimalOrderb `json:"sell"`
+}
+
+type DecimalBalance struct {
+	Currency      string  `json:"Currency"`
+	Balance       Decimal `json:"Balance"`
+	Available     Decimal `json:"Available"`
+	Pending       Decimal `json:"Pending"`
+	CryptoAddress string  `json:"CryptoAddress"`
+	Requested     bool    `json:"Requested"`
+	Uuid          string  `json:"Uuid"`
+}
+
+type DecimalMarketSummary struct {
+	MarketName     string  `json:"MarketName"`
+	High           Decimal `json:"High"`
+	Low            Decimal `json:"Low"`
+	Ask            Decimal `json:"Ask"`
+	Bid            Decimal `json:"Bid"`
+	OpenBuyOrders  int     `json:"OpenBuyOrders"`
+	OpenSellOrders int     `json:"OpenSellOrders"`
+	Volume         Decimal `json:"Volume"`
+	Last           Decimal `json:"Last"`
+	BaseVolume     Decimal `json:"BaseVolume"`
+	PrevDay        Decimal `json:"PrevDay"`
+	TimeStamp      string  `json:"TimeStamp"`
+}
+
+// For getorder
+type DecimalOrder struct {
+	AccountId                  string
+	OrderUuid                  string `json:"OrderUuid"`
+	Exchange                   string `json:"Exchange"`
+	Type                       string
+	Quantity                   Decimal `json:"Quantity"`
+	QuantityRemaining          Decimal `json:"QuantityRemaining"`
+	Limit                      Decimal `json:"Limit"`
+	Reserved                   Decimal
+	ReserveRemaining           Decimal
+	CommissionReserved         Decimal
+	CommissionReserveRemaining Decimal
+	CommissionPaid             Decimal
+	Price                      Decimal `json:"Price"`
+	PricePerUnit               Decimal `json:"PricePerUnit"`
+	Opened                     string
+	Closed                     string
+	IsOpen                     bool
+	Sentinel                   string
+	CancelInitiated            bool
+	ImmediateOrCancel          bool
+	IsConditional              bool
+	Condition                  string
+	ConditionTarget            string
+}
+
+type DecimalOrderHistory struct {
+	OrderUuid         string
+	Exchange          string
+	TimeStamp         time.Time
+	OrderType         string
+	Limit             Decimal
+	Quantity          Decimal
+	QuantityRemaining Decimal
+	Commission        Decimal
+	Price             Decimal
+	PricePerUnit      Decimal
+}
+
+func (o *DecimalOrderHistory) UnmarshalJSON(data []byte) (err error) {
+	s := struct {
+		OrderUuid         string  `json:"OrderUuid"`
+		Exchange          string  `json:"Exchange"`
+		TimeStamp         string  `json:"TimeStamp"`
+		OrderType         string  `json:"OrderType"`
+		Limit             Decimal `json:"Limit"`
+		Quantity          Decimal `json:"Quantity"`
+		QuantityRemaining Decimal `json:"QuantityRemaining"`
+		Commission        Decimal `json:"Commission"`
+		Price             Decimal `json:"Price"`
+		PricePerUnit      Decimal `json:"PricePerUnit"`
+	}{}
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	var t time.Time
+	if s.TimeStamp != "" {
+		t, err = time.Parse(TIME_FORMAT, s.TimeStamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	*o = DecimalOrderHistory{
+		OrderUuid:         s.OrderUuid,
+		Exchange:          s.Exchange,
+		TimeStamp:         t,
+		OrderType:         s.OrderType,
+		Limit:             s.Limit,
+		Quantity:          s.Quantity,
+		QuantityRemaining: s.QuantityRemaining,
+		Commission:        s.Commission,
+		Price:             s.Price,
+		PricePerUnit:      s.PricePerUnit,
+	}
+	return nil
+}
+
+func (o DecimalOrderHistory) MarshalJSON() ([]byte, error) {
+	t := ""
+	if !o.TimeStamp.IsZero() {
+		t = o.TimeStamp.Format(TIME_FORMAT)
+	}
+	return json.Marshal(
+		struct {
+			OrderUuid         string  `json:"OrderUuid"`
+			Exchange          string  `json:"Exchange"`
+			TimeStamp         string  `json:"TimeStamp"`
+			OrderType         string  `json:"OrderType"`
+			Limit             Decimal `json:"Limit"`
+			Quantity          Decimal `json:"Quantity"`
+			QuantityRemaining Decimal `json:"QuantityRemaining"`
+			Commission        Decimal `json:"Commission"`
+			Price             Decimal `json:"Price"`
+			PricePerUnit      Decimal `json:"PricePerUnit"`
+		}{
+			OrderUuid:         o.OrderUuid,
+			Exchange:          o.Exchange,
+			TimeStamp:         t,
+			OrderType:         o.OrderType,
+			Limit:             o.Limit,
+			Quantity:          o.Quantity,
+			QuantityRemaining: o.QuantityRemaining,
+			Commission:        o.Commission,
+			Price:             o.Price,
+			PricePerUnit:      o.PricePerUnit,
+		})
+}
+
+type DecimalCandle struct {
+	TimeStamp  time.Time
+	Open       Decimal
+	Close      Decimal
+	High       Decimal
+	Low        Decimal
+	Volume     Decimal
+	BaseVolume Decimal
+}
+
+func (c *DecimalCandle) UnmarshalJSON(data []byte) (err error) {
+	s := struct {
+		TimeStamp  string  `json:"T"`
+		Open       Decimal `json:"O"`
+		Close      Decimal `json:"C"`
+		High       Decimal `json:"H"`
+		Low        Decimal `json:"L"`
+		Volume     Decimal `json:"V"`
+		BaseVolume Decimal `json:"BV"`
+	}{}
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	var t time.Time
+	if s.TimeStamp != "" {
+		t, err = time.Parse(TIME_FORMAT, s.TimeStamp)
+		if err != nil {
+			return err
+		}
+	}
+
+	*c = DecimalCandle{
+		TimeStamp:  t,
+		Open:       s.Open,
+		Close:      s.Close,
+		High:       s.High,
+		Low:        s.Low,
+		Volume:     s.Volume,
+		BaseVolume: s.BaseVolume,
+	}
+	return nil
+}
+
+func (c DecimalCandle) MarshalJSON() ([]byte, error) {
+	t := ""
+	if !c.TimeStamp.IsZero() {
+		t = c.TimeStamp.Format(TIME_FORMAT)
+	}
+	return json.Marshal(
+		struct {
+			TimeStamp  string  `json:"T"`
+			Open       Decimal `json:"O"`
+			Close      Decimal `json:"C"`
+			High       Decimal `json:"H"`
+			Low        Decimal `json:"L"`
+			Volume     Decimal `json:"V"`
+			BaseVolume Decimal `json:"BV"`
+		}{
+			TimeStamp:  t,
+			Open:       c.Open,
+			Close:      c.Close,
+			High:       c.High,
+			Low:        c.Low,
+			Volume:     c.Volume,
+			BaseVolume: c.BaseVolume,
+		})
+}
@@ -0,0 +1,98 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewDecimalFromString(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+		scale    int32
+	}{
+		{"0", "0", 0},
+		{"12", "12", 0},
+		{"-12", "-12", 0},
+		{"+12", "12", 0},
+		{"0.00012345", "0.00012345", 8},
+		{"1.50000000", "1.50000000", 8},
+		{".5", "0.5", 1},
+		{"5.", "5", 0},
+		{"-0.001", "-0.001", 3},
+		{"1E-08", "0.00000001", 8},
+		{"1.5e3", "1500", 0},
+		{"1.5e-3", "0.0015", 4},
+		{"-2.5E+2", "-250", 0},
+		{"1e1000", "1" + strings.Repeat("0", 1000), 0},
+		{"1e-1000", "0." + strings.Repeat("0", 999) + "1", 1000},
+		{"123456789012345678901234567890.123456789", "123456789012345678901234567890.123456789", 9},
+	} {
+		d, err := NewDecimalFromString(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if got := d.String(); got != tt.want || d.Scale() != tt.scale {
+			t.Errorf("%q: got %s (scale %d), want %s (scale %d)", tt.in, got, d.Scale(), tt.want, tt.scale)
+		}
+	}
+}
+
+func TestNewDecimalFromStringInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", "-", "+", ".", "abc", "1.2.3", "--1", "+-1", "1e", "1e+", "1ex", "0x10", "1,5", " 1", "NaN", "Inf",
+		"1e1001", "1e-1001", "1e2000000000", "1e-2000000000", "1e99999999999",
+	} {
+		if d, err := NewDecimalFromString(in); err == nil {
+			t.Errorf("%q: parsed as %s", in, d)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		A, B, C Decimal
+	}
+	if err := json.Unmarshal([]byte(`{"A":"0.10000000","B":1e-8,"C":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A.String() != "0.10000000" || v.B.String() != "0.00000001" || !v.C.IsZero() {
+		t.Fatalf("got %s %s %s", v.A, v.B, v.C)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"A":0.10000000,"B":0.00000001,"C":0}` {
+		t.Fatalf("got %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"A":"1e5000"}`), &v); err == nil {
+		t.Fatal("decoded an out of range exponent")
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	for _, tt := range []struct {
+		name, got, want string
+	}{
+		{"add", MustDecimal("0.1").Add(MustDecimal("0.2")).String(), "0.3"},
+		{"sub", MustDecimal("1").Sub(MustDecimal("0.00000001")).String(), "0.99999999"},
+		{"mul", MustDecimal("1.5").Mul(MustDecimal("0.002")).String(), "0.0030"},
+		{"div", MustDecimal("1").Div(MustDecimal("3"), 8).String(), "0.33333333"},
+		{"div rounds", MustDecimal("2").Div(MustDecimal("3"), 8).String(), "0.66666667"},
+		{"round half away", MustDecimal("-0.125").Round(2).String(), "-0.13"},
+		{"truncate", MustDecimal("-0.129").Truncate(2).String(), "-0.12"},
+		{"round down", MustDecimal("1.23456789").RoundDown(MustDecimal("0.001")).String(), "1.23400000"},
+		{"round down negative", MustDecimal("-1.2345").RoundDown(MustDecimal("0.01")).String(), "-1.2400"},
+		{"fixed", MustDecimal("0.5").StringFixed(8), "0.50000000"},
+		{"float", NewDecimalFromFloat(0.1).String(), "0.1"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+	if !MustDecimal("1.10").Equal(MustDecimal("1.1")) || !MustDecimal("-1").LessThan(MustDecimal("0")) {
+		t.Error("comparison ignores the scale")
+	}
+}
@@ -0,0 +1,103 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Well-known errors returned by Bittrex. Compare with errors.Is:
+//
+//	if errors.Is(err, bittrex.ErrInsufficientFunds) { ... }
+var (
+	ErrInsufficientFunds         = &APIError{Message: "INSUFFICIENT_FUNDS"}
+	ErrInvalidMarket             = &APIError{Message: "INVALID_MARKET"}
+	ErrOrderNotOpen              = &APIError{Message: "ORDER_NOT_OPEN"}
+	ErrMinTradeRequirementNotMet = &APIError{Message: "MIN_TRADE_REQUIREMENT_NOT_MET"}
+	ErrAPIKeyInvalid             = &APIError{Message: "APIKEY_INVALID"}
+	ErrRateLimited               = &APIError{StatusCode: http.StatusTooManyRequests, Message: "THROTTLED"}
+)
+
+// APIError is returned when Bittrex answers with a non-200 HTTP status
+// or with a JSON envelope whose success field is false.
+type APIError struct {
+	StatusCode int           // HTTP status code of the response
+	Message    string        // message as sent by Bittrex (ex: INSUFFICIENT_FUNDS), or the HTTP status text
+	Endpoint   string        // called resource without API root nor query string (ex: market/buylimit)
+	Body       []byte        // raw response body
+	RetryAfter time.Duration // delay asked by the Retry-After header, if any
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("bittrex: %s: %d %s", e.Endpoint, e.StatusCode, msg)
+	}
+	return fmt.Sprintf("bittrex: %s: %s", e.Endpoint, msg)
+}
+
+// Is reports whether e matches target. An *APIError target matches when its
+// Message equals e.Message, or when it has a StatusCode equal to e.StatusCode.
+// This makes the sentinel values above usable with errors.Is.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	if t.Message != "" && strings.EqualFold(t.Message, canonicalMessage(e.Message)) {
+		return true
+	}
+	return t.StatusCode != 0 && t.StatusCode != http.StatusOK && t.StatusCode == e.StatusCode
+}
+
+// handleErr turns a Bittrex response into an *APIError, or returns nil on success.
+// A 200 response which is not JSON, ex: a proxy error page, is an error too.
+func handleErr(statusCode int, endpoint string, body []byte) error {
+	var r jsonResponse
+	decoded := json.Unmarshal(body, &r) == nil
+	if statusCode == http.StatusOK && decoded && r.Success {
+		return nil
+	}
+	e := &APIError{StatusCode: statusCode, Message: r.Message, Endpoint: endpoint, Body: body}
+	if e.Message == "" && statusCode != http.StatusOK {
+		e.Message = http.StatusText(statusCode)
+	} else if e.Message == "" && !decoded {
+		e.Message = "invalid JSON response"
+	}
+	return e
+}
+
+// messageAliases maps v3 error codes to their v1.1 equivalent, so that the
+// sentinel values match errors from both API versions.
+var messageAliases = map[string]string{
+	"MARKET_DOES_NOT_EXIST": "INVALID_MARKET",
+}
+
+func canonicalMessage(msg string) string {
+	if alias, ok := messageAliases[strings.ToUpper(msg)]; ok {
+		return alias
+	}
+	return msg
+}
+
+// handleErrV3 turns a Bittrex v3 response into an *APIError, or returns nil on success.
+// v3 reports errors with a non-2xx status and a body like {"code":"INSUFFICIENT_FUNDS"}.
+func handleErrV3(statusCode int, endpoint string, body []byte) error {
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+	var r struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(body, &r)
+	e := &APIError{StatusCode: statusCode, Message: r.Code, Endpoint: endpoint, Body: body}
+	if e.Message == "" {
+		e.Message = http.StatusText(statusCode)
+	}
+	return e
+}
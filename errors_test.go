@@ -0,0 +1,68 @@
+package bittrex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSentinels(t *testing.T) {
+	sentinels := []*APIError{ErrInsufficientFunds, ErrInvalidMarket, ErrOrderNotOpen, ErrMinTradeRequirementNotMet, ErrAPIKeyInvalid, ErrRateLimited}
+	for _, test := range []struct {
+		err  error
+		want *APIError // the sentinel err matches, nil for none
+	}{
+		{handleErr(http.StatusOK, "market/buylimit", []byte(`{"success":false,"message":"INSUFFICIENT_FUNDS"}`)), ErrInsufficientFunds},
+		{handleErrV3(http.StatusBadRequest, "orders", []byte(`{"code":"INSUFFICIENT_FUNDS"}`)), ErrInsufficientFunds},
+		{handleErr(http.StatusOK, "public/getticker", []byte(`{"success":false,"message":"INVALID_MARKET"}`)), ErrInvalidMarket},
+		// v3 names it differently.
+		{handleErrV3(http.StatusNotFound, "markets/X-Y/ticker", []byte(`{"code":"MARKET_DOES_NOT_EXIST"}`)), ErrInvalidMarket},
+		{handleErr(http.StatusOK, "market/cancel", []byte(`{"success":false,"message":"ORDER_NOT_OPEN"}`)), ErrOrderNotOpen},
+		{handleErr(http.StatusOK, "market/selllimit", []byte(`{"success":false,"message":"MIN_TRADE_REQUIREMENT_NOT_MET"}`)), ErrMinTradeRequirementNotMet},
+		{handleErr(http.StatusOK, "account/getbalances", []byte(`{"success":false,"message":"APIKEY_INVALID"}`)), ErrAPIKeyInvalid},
+		// Throttling matches by message, or by status code.
+		{handleErr(http.StatusOK, "public/getticker", []byte(`{"success":false,"message":"THROTTLED"}`)), ErrRateLimited},
+		{handleErr(http.StatusTooManyRequests, "public/getticker", []byte(`<html>slow down</html>`)), ErrRateLimited},
+		{handleErrV3(http.StatusTooManyRequests, "balances", nil), ErrRateLimited},
+		{fmt.Errorf("wrapped: %w", handleErr(http.StatusOK, "market/buylimit", []byte(`{"success":false,"message":"insufficient_funds"}`))), ErrInsufficientFunds},
+		{handleErr(http.StatusOK, "market/buylimit", []byte(`{"success":false,"message":"DUST_TRADE_DISALLOWED_MIN_VALUE_50K_SAT"}`)), nil},
+		{handleErr(http.StatusServiceUnavailable, "public/getticker", nil), nil},
+		{errors.New("INSUFFICIENT_FUNDS"), nil},
+	} {
+		for _, sentinel := range sentinels {
+			if got := errors.Is(test.err, sentinel); got != (sentinel == test.want) {
+				t.Errorf("errors.Is(%v, %s) = %v", test.err, sentinel.Message, got)
+			}
+		}
+	}
+}
+
+func TestHandleErr(t *testing.T) {
+	for _, test := range []struct {
+		status  int
+		body    string
+		message string // "" for no error
+	}{
+		{http.StatusOK, `{"success":true,"message":"","result":[]}`, ""},
+		{http.StatusOK, `{"success":false,"message":"INVALID_MARKET","result":null}`, "INVALID_MARKET"},
+		// A proxy page instead of the envelope.
+		{http.StatusOK, `<html><body>Attention Required! | Cloudflare</body></html>`, "invalid JSON response"},
+		{http.StatusOK, ``, "invalid JSON response"},
+		{http.StatusBadGateway, `<html>Bad gateway</html>`, "Bad Gateway"},
+		{http.StatusServiceUnavailable, `{"success":false,"message":"MAINTENANCE"}`, "MAINTENANCE"},
+	} {
+		err := handleErr(test.status, "public/getticker", []byte(test.body))
+		if test.message == "" {
+			if err != nil {
+				t.Errorf("%d %s: %v", test.status, test.body, err)
+			}
+			continue
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status || apiErr.Message != test.message ||
+			apiErr.Endpoint != "public/getticker" || string(apiErr.Body) != test.body {
+			t.Errorf("%d %s: got %#v, want %s", test.status, test.body, err, test.message)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package bittrex
+
+import "context"
+
+// The interfaces below describe the Bittrex API by area. *Bittrex and
+// *PaperBittrex satisfy all of them, so that callers may depend on an
+// interface and swap the live client for the paper exchange, a test double or
+// a decorator (caching, logging, risk checks...).
+
+// MarketData is the public, read only, part of the Bittrex API.
+type MarketData interface {
+	GetDistribution(market string) (*Distribution, error)
+	GetDistributionCtx(ctx context.Context, market string) (*Distribution, error)
+	GetCurrencies() ([]*Currency, error)
+	GetCurrenciesCtx(ctx context.Context) ([]*Currency, error)
+	GetMarkets() ([]*Market, error)
+	GetMarketsCtx(ctx context.Context) ([]*Market, error)
+	GetTicker(market string) (*Ticker, error)
+	GetTickerCtx(ctx context.Context, market string) (*Ticker, error)
+	GetMarketSummaries() ([]*MarketSummary, error)
+	GetMarketSummariesCtx(ctx context.Context) ([]*MarketSummary, error)
+	GetMarketSummary(market string) ([]*MarketSummary, error)
+	GetMarketSummaryCtx(ctx context.Context, market string) ([]*MarketSummary, error)
+	GetOrderBook(market, cat string, depth int) (*OrderBook, error)
+	GetOrderBookCtx(ctx context.Context, market, cat string, depth int) (*OrderBook, error)
+	GetOrderBookBuySell(market, cat string, depth int) ([]*Orderb, error)
+	GetOrderBookBuySellCtx(ctx context.Context, market, cat string, depth int) ([]*Orderb, error)
+	GetMarketHistory(market string) ([]*Trade, error)
+	GetMarketHistoryCtx(ctx context.Context, market string) ([]*Trade, error)
+	GetTicks(market string, interval Interval) ([]*Candle, error)
+	GetTicksCtx(ctx context.Context, market string, interval Interval) ([]*Candle, error)
+}
+
+// Trading is the part of the Bittrex API placing and following orders.
+type Trading interface {
+	BuyLimit(market string, quantity, rate float64) (uuid string, err error)
+	BuyLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error)
+	BuyMarket(market string, quantity float64) (uuid string, err error)
+	BuyMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error)
+	SellLimit(market string, quantity, rate float64) (uuid string, err error)
+	SellLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error)
+	SellMarket(market string, quantity float64) (uuid string, err error)
+	SellMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error)
+	CancelOrder(orderID string) (err error)
+	CancelOrderCtx(ctx context.Context, orderID string) (err error)
+	GetOpenOrders(market string) ([]*OrderHistory, error)
+	GetOpenOrdersCtx(ctx context.Context, market string) ([]*OrderHistory, error)
+	GetOrderHistory(market string) ([]*OrderHistory, error)
+	GetOrderHistoryCtx(ctx context.Context, market string) ([]*OrderHistory, error)
+	GetOrder(order_uuid string) (*Order, error)
+	GetOrderCtx(ctx context.Context, order_uuid string) (*Order, error)
+}
+
+// Account is the part of the Bittrex API managing balances and funds.
+type Account interface {
+	GetBalances() ([]*Balance, error)
+	GetBalancesCtx(ctx context.Context) ([]*Balance, error)
+	GetBalance(currency string) (*Balance, error)
+	GetBalanceCtx(ctx context.Context, currency string) (*Balance, error)
+	GetDepositAddress(currency string) (*Address, error)
+	GetDepositAddressCtx(ctx context.Context, currency string) (*Address, error)
+	Withdraw(address, currency string, quantity float64) (withdrawUuid string, err error)
+	WithdrawCtx(ctx context.Context, address, currency string, quantity float64) (withdrawUuid string, err error)
+	GetWithdrawalHistory(currency string) ([]*Withdrawal, error)
+	GetWithdrawalHistoryCtx(ctx context.Context, currency string) ([]*Withdrawal, error)
+	GetDepositHistory(currency string) ([]*Deposit, error)
+	GetDepositHistoryCtx(ctx context.Context, currency string) ([]*Deposit, error)
+}
+
+// Exchange is the whole Bittrex API.
+type Exchange interface {
+	MarketData
+	Trading
+	Account
+}
+
+var (
+	_ Exchange = (*Bittrex)(nil)
+	_ Exchange = (*PaperBittrex)(nil)
+	_ Trading  = (*ValidatingTrading)(nil)
+)
@@ -0,0 +1,116 @@
+// Package indicators computes technical indicators over Bittrex candles.
+//
+// Every indicator comes in two modes. The streaming mode is a value updated
+// with one price, or one candle, at a time, which reports whether it is warmed
+// up:
+//
+//	rsi := indicators.NewRSI(14)
+//	for _, c := range candles {
+//		if v, ok := rsi.Add(c.Close); ok {
+//			...
+//		}
+//	}
+//
+// The batch mode is a function over a candle series, oldest first, returning
+// a value per candle, NaN while the indicator warms up:
+//
+//	rsi := indicators.RSISeries(candles, 14)
+//
+// Price based indicators (SMA, EMA, WMA, RSI, MACD, Bollinger) use the close
+// of the candles. Warm-up periods follow the usual references: an SMA of
+// period n has its first value on the n-th price, an EMA is seeded with the
+// SMA of its first n prices, RSI and ATR use Wilder's smoothing seeded with a
+// simple average.
+package indicators
+
+import (
+	"math"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// window is a fixed size ring buffer of the last values added.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+}
+
+func newWindow(size int) *window {
+	if size <= 0 {
+		panic("indicators: period must be positive")
+	}
+	return &window{values: make([]float64, size)}
+}
+
+// add appends x, dropping the oldest value once the window is full.
+func (w *window) add(x float64) {
+	w.values[w.next] = x
+	if w.next++; w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// each calls f on the values, oldest first.
+func (w *window) each(f func(i int, x float64)) {
+	n := w.len()
+	start := 0
+	if w.full {
+		start = w.next
+	}
+	for i := 0; i < n; i++ {
+		f(i, w.values[(start+i)%len(w.values)])
+	}
+}
+
+func (w *window) len() int {
+	if w.full {
+		return len(w.values)
+	}
+	return w.next
+}
+
+func (w *window) sum() float64 {
+	s := 0.0
+	w.each(func(_ int, x float64) { s += x })
+	return s
+}
+
+func (w *window) min() float64 {
+	m := math.Inf(1)
+	w.each(func(_ int, x float64) { m = math.Min(m, x) })
+	return m
+}
+
+func (w *window) max() float64 {
+	m := math.Inf(-1)
+	w.each(func(_ int, x float64) { m = math.Max(m, x) })
+	return m
+}
+
+// priceSeries runs add over the closes of candles and returns its values, NaN while not ok.
+func priceSeries(candles []*bittrex.Candle, add func(float64) (float64, bool)) []float64 {
+	values := make([]float64, len(candles))
+	for i, c := range candles {
+		v, ok := add(c.Close)
+		if !ok {
+			v = math.NaN()
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// candleSeries runs add over candles and returns its values, NaN while not ok.
+func candleSeries(candles []*bittrex.Candle, add func(*bittrex.Candle) (float64, bool)) []float64 {
+	values := make([]float64, len(candles))
+	for i, c := range candles {
+		v, ok := add(c)
+		if !ok {
+			v = math.NaN()
+		}
+		values[i] = v
+	}
+	return values
+}
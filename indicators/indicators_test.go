@@ -0,0 +1,66 @@
+package indicators_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// refTolerance is the precision of the published reference values: they are
+// rounded to 2 decimals, at times from unrounded prices.
+const refTolerance = 0.011
+
+var start = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// closes returns hourly candles closing at prices.
+func closes(prices ...float64) []*bittrex.Candle {
+	candles := make([]*bittrex.Candle, len(prices))
+	for i, p := range prices {
+		candles[i] = &bittrex.Candle{TimeStamp: start.Add(time.Duration(i) * time.Hour), Open: p, High: p, Low: p, Close: p}
+	}
+	return candles
+}
+
+// hlc returns hourly candles of the given highs, lows and closes.
+func hlc(highs, lows, closes []float64) []*bittrex.Candle {
+	candles := make([]*bittrex.Candle, len(closes))
+	for i := range closes {
+		candles[i] = &bittrex.Candle{TimeStamp: start.Add(time.Duration(i) * time.Hour), High: highs[i], Low: lows[i], Close: closes[i]}
+	}
+	return candles
+}
+
+// checkSeries checks that got is NaN before warmup, then within tolerance of want.
+func checkSeries(t *testing.T, name string, got []float64, warmup int, want []float64, tolerance float64) {
+	t.Helper()
+	if len(got) < warmup+len(want) {
+		t.Fatalf("%s: %d values, want %d", name, len(got), warmup+len(want))
+	}
+	for i := 0; i < warmup; i++ {
+		if !math.IsNaN(got[i]) {
+			t.Errorf("%s[%d] = %v during the warm-up", name, i, got[i])
+		}
+	}
+	for i, w := range want {
+		if g := got[warmup+i]; math.IsNaN(g) || math.Abs(g-w) > tolerance {
+			t.Errorf("%s[%d] = %v, want %v", name, warmup+i, g, w)
+		}
+	}
+}
+
+// checkStreaming checks that adding the values one at a time gives the batch
+// series: add is called for every index, and reports NaN by returning !ok.
+func checkStreaming(t *testing.T, name string, batch []float64, add func(i int) (float64, bool)) {
+	t.Helper()
+	for i, want := range batch {
+		got, ok := add(i)
+		if !ok {
+			got = math.NaN()
+		}
+		if math.IsNaN(want) != math.IsNaN(got) || (!math.IsNaN(want) && got != want) {
+			t.Errorf("%s: streaming value %d is %v, batch %v", name, i, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package indicators
+
+import "github.com/yangou/go-bittrex"
+
+// SMA is a simple moving average.
+type SMA struct {
+	w *window
+}
+
+// NewSMA returns a simple moving average of period prices.
+func NewSMA(period int) *SMA {
+	return &SMA{w: newWindow(period)}
+}
+
+// Add adds a price and returns the average, ok once period prices were added.
+func (s *SMA) Add(price float64) (value float64, ok bool) {
+	s.w.add(price)
+	return s.Value()
+}
+
+// Value returns the current average.
+func (s *SMA) Value() (value float64, ok bool) {
+	if !s.w.full {
+		return 0, false
+	}
+	return s.w.sum() / float64(len(s.w.values)), true
+}
+
+// SMASeries returns the simple moving average of the closes of candles.
+func SMASeries(candles []*bittrex.Candle, period int) []float64 {
+	return priceSeries(candles, NewSMA(period).Add)
+}
+
+// EMA is an exponential moving average, seeded with the simple average of its
+// first period prices.
+type EMA struct {
+	alpha float64
+	seed  *SMA
+	value float64
+	ok    bool
+}
+
+// NewEMA returns an exponential moving average of period prices, of smoothing factor 2/(period+1).
+func NewEMA(period int) *EMA {
+	return &EMA{alpha: 2 / float64(period+1), seed: NewSMA(period)}
+}
+
+// Add adds a price and returns the average, ok once period prices were added.
+func (e *EMA) Add(price float64) (value float64, ok bool) {
+	if e.ok {
+		e.value += e.alpha * (price - e.value)
+		return e.value, true
+	}
+	e.value, e.ok = e.seed.Add(price)
+	return e.value, e.ok
+}
+
+// Value returns the current average.
+func (e *EMA) Value() (value float64, ok bool) {
+	return e.value, e.ok
+}
+
+// EMASeries returns the exponential moving average of the closes of candles.
+func EMASeries(candles []*bittrex.Candle, period int) []float64 {
+	return priceSeries(candles, NewEMA(period).Add)
+}
+
+// WMA is a linearly weighted moving average: the latest price weighs period,
+// the oldest one 1.
+type WMA struct {
+	w *window
+}
+
+// NewWMA returns a weighted moving average of period prices.
+func NewWMA(period int) *WMA {
+	return &WMA{w: newWindow(period)}
+}
+
+// Add adds a price and returns the average, ok once period prices were added.
+func (m *WMA) Add(price float64) (value float64, ok bool) {
+	m.w.add(price)
+	return m.Value()
+}
+
+// Value returns the current average.
+func (m *WMA) Value() (value float64, ok bool) {
+	if !m.w.full {
+		return 0, false
+	}
+	n := len(m.w.values)
+	sum := 0.0
+	m.w.each(func(i int, x float64) { sum += float64(i+1) * x })
+	return sum / float64(n*(n+1)/2), true
+}
+
+// WMASeries returns the weighted moving average of the closes of candles.
+func WMASeries(candles []*bittrex.Candle, period int) []float64 {
+	return priceSeries(candles, NewWMA(period).Add)
+}
@@ -0,0 +1,49 @@
+package indicators_test
+
+import (
+	"testing"
+
+	"github.com/yangou/go-bittrex/indicators"
+)
+
+// StockCharts, "Moving Averages - Simple and Exponential", 10-day averages.
+var stockChartsPrices = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+func TestSMA(t *testing.T) {
+	candles := closes(stockChartsPrices...)
+	batch := indicators.SMASeries(candles, 10)
+	checkSeries(t, "SMA", batch, 9, []float64{
+		22.22, 22.21, 22.23, 22.26, 22.31, 22.42, 22.61, 22.77, 22.91, 23.08,
+		23.21, 23.38, 23.53, 23.65, 23.71, 23.69, 23.61, 23.51, 23.43, 23.28, 23.13,
+	}, refTolerance)
+	sma := indicators.NewSMA(10)
+	checkStreaming(t, "SMA", batch, func(i int) (float64, bool) { return sma.Add(candles[i].Close) })
+}
+
+func TestEMA(t *testing.T) {
+	candles := closes(stockChartsPrices...)
+	batch := indicators.EMASeries(candles, 10)
+	// Seeded with the SMA of the first 10 prices.
+	checkSeries(t, "EMA", batch, 9, []float64{
+		22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+		23.34, 23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+	}, refTolerance)
+	ema := indicators.NewEMA(10)
+	checkStreaming(t, "EMA", batch, func(i int) (float64, bool) { return ema.Add(candles[i].Close) })
+}
+
+func TestWMA(t *testing.T) {
+	candles := closes(1, 2, 3, 4, 5, 9)
+	batch := indicators.WMASeries(candles, 3)
+	// (1*1 + 2*2 + 3*3) / 6, ..., (1*4 + 2*5 + 3*9) / 6
+	checkSeries(t, "WMA", batch, 2, []float64{14.0 / 6, 20.0 / 6, 26.0 / 6, 41.0 / 6}, 1e-12)
+	wma := indicators.NewWMA(3)
+	checkStreaming(t, "WMA", batch, func(i int) (float64, bool) { return wma.Add(candles[i].Close) })
+	if v, ok := wma.Value(); !ok || v != batch[5] {
+		t.Fatalf("Value() = %v, %v", v, ok)
+	}
+}
@@ -0,0 +1,196 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// RSI is Wilder's relative strength index, from 0 to 100.
+type RSI struct {
+	period   int
+	prev     float64
+	count    int // prices added
+	avgGain  float64
+	avgLoss  float64
+	value    float64
+	hasValue bool
+}
+
+// NewRSI returns a relative strength index of period price changes. Its first
+// value comes with the price period+1.
+func NewRSI(period int) *RSI {
+	if period <= 0 {
+		panic("indicators: period must be positive")
+	}
+	return &RSI{period: period}
+}
+
+// Add adds a price and returns the index, ok once period+1 prices were added.
+func (r *RSI) Add(price float64) (value float64, ok bool) {
+	r.count++
+	if r.count == 1 {
+		r.prev = price
+		return 0, false
+	}
+	gain, loss := 0.0, 0.0
+	if change := price - r.prev; change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+	r.prev = price
+
+	n := float64(r.period)
+	switch {
+	case r.count <= r.period+1:
+		// seed with the simple average of the first period changes
+		r.avgGain += gain / n
+		r.avgLoss += loss / n
+		if r.count < r.period+1 {
+			return 0, false
+		}
+	default:
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+	switch {
+	case r.avgLoss == 0 && r.avgGain == 0:
+		r.value = 50
+	case r.avgLoss == 0:
+		r.value = 100
+	default:
+		r.value = 100 - 100/(1+r.avgGain/r.avgLoss)
+	}
+	r.hasValue = true
+	return r.value, true
+}
+
+// Value returns the current index.
+func (r *RSI) Value() (value float64, ok bool) {
+	return r.value, r.hasValue
+}
+
+// RSISeries returns the relative strength index of the closes of candles.
+func RSISeries(candles []*bittrex.Candle, period int) []float64 {
+	return priceSeries(candles, NewRSI(period).Add)
+}
+
+// MACDValue is a value of a MACD.
+type MACDValue struct {
+	MACD      float64 // fast EMA - slow EMA
+	Signal    float64 // EMA of MACD
+	Histogram float64 // MACD - Signal
+}
+
+// MACD is the moving average convergence divergence.
+type MACD struct {
+	fast, slow, signal *EMA
+	value              MACDValue
+	ok                 bool
+}
+
+// NewMACD returns a MACD of the given EMA periods, usually 12, 26 and 9. Its
+// first value comes with the price slow+signal-1.
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+// Add adds a price and returns the MACD, ok once the signal line is warmed up.
+func (m *MACD) Add(price float64) (value MACDValue, ok bool) {
+	fast, fastOk := m.fast.Add(price)
+	slow, slowOk := m.slow.Add(price)
+	if !fastOk || !slowOk {
+		return value, false
+	}
+	value.MACD = fast - slow
+	signal, ok := m.signal.Add(value.MACD)
+	if !ok {
+		return value, false
+	}
+	value.Signal = signal
+	value.Histogram = value.MACD - signal
+	m.value, m.ok = value, true
+	return value, true
+}
+
+// Value returns the current MACD.
+func (m *MACD) Value() (value MACDValue, ok bool) {
+	return m.value, m.ok
+}
+
+// MACDSeries returns the MACD of the closes of candles. The values are NaN
+// while the signal line warms up.
+func MACDSeries(candles []*bittrex.Candle, fast, slow, signal int) []MACDValue {
+	m := NewMACD(fast, slow, signal)
+	values := make([]MACDValue, len(candles))
+	for i, c := range candles {
+		v, ok := m.Add(c.Close)
+		if !ok {
+			v = MACDValue{MACD: math.NaN(), Signal: math.NaN(), Histogram: math.NaN()}
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// StochasticValue is a value of a stochastic oscillator, from 0 to 100.
+type StochasticValue struct {
+	K float64 // position of the close in the high-low range of the period
+	D float64 // simple average of K
+}
+
+// Stochastic is the fast stochastic oscillator.
+type Stochastic struct {
+	highs, lows *window
+	d           *SMA
+	value       StochasticValue
+	ok          bool
+}
+
+// NewStochastic returns a stochastic oscillator of %K over kPeriod candles and
+// %D over dPeriod values of %K, usually 14 and 3. Its first value comes with
+// the candle kPeriod+dPeriod-1.
+func NewStochastic(kPeriod, dPeriod int) *Stochastic {
+	return &Stochastic{highs: newWindow(kPeriod), lows: newWindow(kPeriod), d: NewSMA(dPeriod)}
+}
+
+// Add adds a candle and returns the oscillator, ok once %D is warmed up. %K
+// is 50 when the range of the period is empty.
+func (s *Stochastic) Add(c *bittrex.Candle) (value StochasticValue, ok bool) {
+	s.highs.add(c.High)
+	s.lows.add(c.Low)
+	if !s.highs.full {
+		return value, false
+	}
+	high, low := s.highs.max(), s.lows.min()
+	value.K = 50
+	if high > low {
+		value.K = 100 * (c.Close - low) / (high - low)
+	}
+	if value.D, ok = s.d.Add(value.K); !ok {
+		return value, false
+	}
+	s.value, s.ok = value, true
+	return value, true
+}
+
+// Value returns the current oscillator.
+func (s *Stochastic) Value() (value StochasticValue, ok bool) {
+	return s.value, s.ok
+}
+
+// StochasticSeries returns the stochastic oscillator of candles. The values
+// are NaN while %D warms up.
+func StochasticSeries(candles []*bittrex.Candle, kPeriod, dPeriod int) []StochasticValue {
+	s := NewStochastic(kPeriod, dPeriod)
+	values := make([]StochasticValue, len(candles))
+	for i, c := range candles {
+		v, ok := s.Add(c)
+		if !ok {
+			v = StochasticValue{K: math.NaN(), D: math.NaN()}
+		}
+		values[i] = v
+	}
+	return values
+}
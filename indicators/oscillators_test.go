@@ -0,0 +1,118 @@
+package indicators_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yangou/go-bittrex/indicators"
+)
+
+func TestRSI(t *testing.T) {
+	// StockCharts, "Relative Strength Index (RSI)", 14 periods.
+	candles := closes(
+		44.3389, 44.0902, 44.1497, 43.6124, 44.3278, 44.8264, 45.0955, 45.4245, 45.8433, 46.0826,
+		45.8931, 46.0328, 45.6140, 46.2820, 46.2820, 46.0028, 46.0328, 46.4116, 46.2222, 45.6439,
+		46.2122, 46.2521, 45.7137, 46.4515, 45.7835, 45.3548, 44.0288, 44.1783, 44.2181, 44.5672,
+		43.4205, 42.6628, 43.1314,
+	)
+	batch := indicators.RSISeries(candles, 14)
+	// The first value needs 14 changes, so 15 prices.
+	checkSeries(t, "RSI", batch, 14, []float64{
+		70.53, 66.32, 66.55, 69.41, 66.36, 57.97, 62.93, 63.26, 56.06, 62.38,
+		54.71, 50.42, 39.99, 41.46, 41.87, 45.46, 37.30, 33.08, 37.77,
+	}, refTolerance)
+	rsi := indicators.NewRSI(14)
+	checkStreaming(t, "RSI", batch, func(i int) (float64, bool) { return rsi.Add(candles[i].Close) })
+
+	if v := indicators.RSISeries(closes(1, 2, 3), 2); v[2] != 100 {
+		t.Errorf("RSI without losses = %v, want 100", v[2])
+	}
+	if v := indicators.RSISeries(closes(1, 1, 1), 2); v[2] != 50 {
+		t.Errorf("RSI without changes = %v, want 50", v[2])
+	}
+}
+
+func macdComponents(values []indicators.MACDValue) (macd, signal, histogram []float64) {
+	for _, v := range values {
+		macd = append(macd, v.MACD)
+		signal = append(signal, v.Signal)
+		histogram = append(histogram, v.Histogram)
+	}
+	return macd, signal, histogram
+}
+
+func TestMACD(t *testing.T) {
+	// On a ramp, an EMA of period n lags by (n-1)/2: MACD(12, 26, 9) is 7,
+	// from the price 26+9-1.
+	ramp := make([]float64, 40)
+	for i := range ramp {
+		ramp[i] = float64(i + 1)
+	}
+	macd, signal, histogram := macdComponents(indicators.MACDSeries(closes(ramp...), 12, 26, 9))
+	checkSeries(t, "MACD", macd, 33, []float64{7, 7, 7, 7, 7, 7, 7}, 1e-9)
+	checkSeries(t, "signal", signal, 33, []float64{7, 7, 7, 7, 7, 7, 7}, 1e-9)
+	checkSeries(t, "histogram", histogram, 33, []float64{0, 0, 0, 0, 0, 0, 0}, 1e-9)
+
+	// Elsewhere it is the difference of the EMAs checked in TestEMA, and its
+	// signal the EMA of that difference.
+	candles := closes(stockChartsPrices...)
+	batch := indicators.MACDSeries(candles, 5, 10, 4)
+	fast, slow := indicators.EMASeries(candles, 5), indicators.EMASeries(candles, 10)
+	ema := indicators.NewEMA(4)
+	for i, v := range batch {
+		if i < 9 {
+			if !math.IsNaN(v.MACD) || !math.IsNaN(v.Signal) || !math.IsNaN(v.Histogram) {
+				t.Fatalf("MACD[%d] = %+v during the warm-up", i, v)
+			}
+			continue
+		}
+		want := fast[i] - slow[i]
+		wantSignal, ok := ema.Add(want)
+		if !ok {
+			if !math.IsNaN(v.MACD) {
+				t.Fatalf("MACD[%d] = %+v while the signal warms up", i, v)
+			}
+			continue
+		}
+		if i < 12 || v.MACD != want || v.Signal != wantSignal || v.Histogram != want-wantSignal {
+			t.Fatalf("MACD[%d] = %+v, want %v and signal %v", i, v, want, wantSignal)
+		}
+	}
+
+	m := indicators.NewMACD(5, 10, 4)
+	macd, signal, _ = macdComponents(batch)
+	checkStreaming(t, "MACD", macd, func(i int) (float64, bool) {
+		v, ok := m.Add(candles[i].Close)
+		if ok && v.Signal != signal[i] {
+			t.Errorf("streaming signal %d is %v, batch %v", i, v.Signal, signal[i])
+		}
+		return v.MACD, ok
+	})
+}
+
+func TestStochastic(t *testing.T) {
+	candles := hlc(
+		[]float64{10, 11, 12, 12, 13, 13},
+		[]float64{8, 9, 9, 10, 11, 13},
+		[]float64{9, 10, 11, 10, 13, 13},
+	)
+	batch := indicators.StochasticSeries(candles, 3, 2)
+	var k, d []float64
+	for _, v := range batch {
+		k, d = append(k, v.K), append(d, v.D)
+	}
+	// %K of the candle 2 is 100 * (11-8) / (12-8) = 75, but %D needs two of them.
+	checkSeries(t, "%K", k, 3, []float64{100.0 / 3, 100, 100}, 1e-9)
+	checkSeries(t, "%D", d, 3, []float64{(75 + 100.0/3) / 2, (100.0/3 + 100) / 2, 100}, 1e-9)
+
+	s := indicators.NewStochastic(3, 2)
+	checkStreaming(t, "%K", k, func(i int) (float64, bool) {
+		v, ok := s.Add(candles[i])
+		return v.K, ok
+	})
+
+	flat := indicators.StochasticSeries(hlc([]float64{5, 5}, []float64{5, 5}, []float64{5, 5}), 1, 1)
+	if flat[1].K != 50 {
+		t.Errorf("%%K of an empty range = %v, want 50", flat[1].K)
+	}
+}
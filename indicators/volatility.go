@@ -0,0 +1,112 @@
+package indicators
+
+import (
+	"math"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// BollingerValue is a value of Bollinger bands.
+type BollingerValue struct {
+	Middle float64 // simple moving average
+	Upper  float64 // Middle + k standard deviations
+	Lower  float64 // Middle - k standard deviations
+}
+
+// Bollinger is a set of Bollinger bands.
+type Bollinger struct {
+	w     *window
+	k     float64
+	value BollingerValue
+	ok    bool
+}
+
+// NewBollinger returns Bollinger bands of period prices, k population standard
+// deviations away from their average, usually 20 and 2.
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{w: newWindow(period), k: k}
+}
+
+// Add adds a price and returns the bands, ok once period prices were added.
+func (b *Bollinger) Add(price float64) (value BollingerValue, ok bool) {
+	b.w.add(price)
+	if !b.w.full {
+		return value, false
+	}
+	n := float64(len(b.w.values))
+	mean := b.w.sum() / n
+	variance := 0.0
+	b.w.each(func(_ int, x float64) { variance += (x - mean) * (x - mean) })
+	sd := math.Sqrt(variance / n)
+	value = BollingerValue{Middle: mean, Upper: mean + b.k*sd, Lower: mean - b.k*sd}
+	b.value, b.ok = value, true
+	return value, true
+}
+
+// Value returns the current bands.
+func (b *Bollinger) Value() (value BollingerValue, ok bool) {
+	return b.value, b.ok
+}
+
+// BollingerSeries returns the Bollinger bands of the closes of candles. The
+// values are NaN while the bands warm up.
+func BollingerSeries(candles []*bittrex.Candle, period int, k float64) []BollingerValue {
+	b := NewBollinger(period, k)
+	values := make([]BollingerValue, len(candles))
+	for i, c := range candles {
+		v, ok := b.Add(c.Close)
+		if !ok {
+			v = BollingerValue{Middle: math.NaN(), Upper: math.NaN(), Lower: math.NaN()}
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// ATR is Wilder's average true range.
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewATR returns an average true range of period candles. The true range of
+// the first candle is its high - low, so the first value comes with the
+// candle period.
+func NewATR(period int) *ATR {
+	if period <= 0 {
+		panic("indicators: period must be positive")
+	}
+	return &ATR{period: period}
+}
+
+// Add adds a candle and returns the average, ok once period candles were added.
+func (a *ATR) Add(c *bittrex.Candle) (value float64, ok bool) {
+	tr := c.High - c.Low
+	if a.count > 0 {
+		tr = math.Max(tr, math.Max(math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose)))
+	}
+	a.prevClose = c.Close
+	a.count++
+	n := float64(a.period)
+	if a.count <= a.period {
+		a.value += tr / n
+	} else {
+		a.value = (a.value*(n-1) + tr) / n
+	}
+	return a.Value()
+}
+
+// Value returns the current average.
+func (a *ATR) Value() (value float64, ok bool) {
+	if a.count < a.period {
+		return 0, false
+	}
+	return a.value, true
+}
+
+// ATRSeries returns the average true range of candles.
+func ATRSeries(candles []*bittrex.Candle, period int) []float64 {
+	return candleSeries(candles, NewATR(period).Add)
+}
@@ -0,0 +1,51 @@
+package indicators_test
+
+import (
+	"testing"
+
+	"github.com/yangou/go-bittrex/indicators"
+)
+
+func TestBollinger(t *testing.T) {
+	// StockCharts, "Bollinger Bands", 20 days and 2 standard deviations.
+	candles := closes(
+		86.16, 89.09, 88.78, 90.32, 89.07, 91.15, 89.44, 89.18, 86.93, 87.68,
+		86.96, 89.43, 89.32, 88.72, 87.45, 87.26, 89.50, 87.90, 89.13, 90.70,
+		92.90, 92.98, 91.80, 92.66, 92.68, 92.30, 92.77, 92.54, 92.95, 93.20,
+		91.07,
+	)
+	batch := indicators.BollingerSeries(candles, 20, 2)
+	var middle, upper, lower []float64
+	for _, v := range batch {
+		middle, upper, lower = append(middle, v.Middle), append(upper, v.Upper), append(lower, v.Lower)
+	}
+	checkSeries(t, "middle", middle, 19, []float64{88.71, 89.05, 89.24, 89.39, 89.51, 89.69, 89.75, 89.91, 90.08, 90.38, 90.66, 90.86}, refTolerance)
+	checkSeries(t, "upper", upper, 19, []float64{91.29, 91.95, 92.61, 92.93, 93.31, 93.73, 93.90, 94.27, 94.57, 94.79, 95.04, 94.91}, refTolerance)
+	checkSeries(t, "lower", lower, 19, []float64{86.12, 86.14, 85.87, 85.85, 85.70, 85.65, 85.59, 85.56, 85.60, 85.98, 86.27, 86.82}, refTolerance)
+
+	b := indicators.NewBollinger(20, 2)
+	checkStreaming(t, "upper", upper, func(i int) (float64, bool) {
+		v, ok := b.Add(candles[i].Close)
+		return v.Upper, ok
+	})
+}
+
+func TestATR(t *testing.T) {
+	// StockCharts, "Average True Range (ATR)", 14 periods.
+	candles := hlc(
+		[]float64{48.70, 48.72, 48.90, 48.87, 48.82, 49.05, 49.20, 49.35, 49.92, 50.19, 50.12, 49.66, 49.88, 50.19, 50.36,
+			50.57, 50.65, 50.43, 49.63, 50.33, 50.29, 50.17, 49.32, 48.50, 48.32, 46.80, 47.80, 48.39, 48.66, 48.79},
+		[]float64{47.79, 48.14, 48.39, 48.37, 48.24, 48.64, 48.94, 48.86, 49.50, 49.87, 49.20, 48.90, 49.43, 49.73, 49.26,
+			50.09, 50.30, 49.21, 48.98, 49.61, 49.20, 49.43, 48.08, 47.64, 41.55, 44.28, 47.31, 47.20, 47.90, 47.73},
+		[]float64{48.16, 48.61, 48.75, 48.63, 48.74, 49.03, 49.07, 49.32, 49.91, 50.13, 49.53, 49.50, 49.75, 50.03, 50.31,
+			50.52, 50.41, 49.34, 49.37, 50.23, 49.24, 49.93, 48.43, 48.18, 46.57, 45.41, 47.77, 47.72, 48.62, 47.85},
+	)
+	batch := indicators.ATRSeries(candles, 14)
+	// The first true range is the high - low of the first candle, so the
+	// first value comes with the candle 14.
+	checkSeries(t, "ATR", batch, 13, []float64{
+		0.56, 0.59, 0.59, 0.57, 0.62, 0.62, 0.64, 0.67, 0.69, 0.78, 0.78, 1.21, 1.30, 1.38, 1.37, 1.34, 1.32,
+	}, refTolerance)
+	atr := indicators.NewATR(14)
+	checkStreaming(t, "ATR", batch, func(i int) (float64, bool) { return atr.Add(candles[i]) })
+}
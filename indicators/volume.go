@@ -0,0 +1,92 @@
+package indicators
+
+import (
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// OBV is the on-balance volume: the running sum of the volume of the candles
+// closing up, minus the volume of the candles closing down.
+type OBV struct {
+	count     int
+	prevClose float64
+	value     float64
+}
+
+// NewOBV returns an on-balance volume starting at 0.
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Add adds a candle and returns the on-balance volume. It is always ok, the
+// first candle giving 0.
+func (o *OBV) Add(c *bittrex.Candle) (value float64, ok bool) {
+	if o.count > 0 {
+		switch {
+		case c.Close > o.prevClose:
+			o.value += c.Volume
+		case c.Close < o.prevClose:
+			o.value -= c.Volume
+		}
+	}
+	o.count++
+	o.prevClose = c.Close
+	return o.value, true
+}
+
+// Value returns the current on-balance volume.
+func (o *OBV) Value() (value float64, ok bool) {
+	return o.value, o.count > 0
+}
+
+// OBVSeries returns the on-balance volume of candles.
+func OBVSeries(candles []*bittrex.Candle) []float64 {
+	return candleSeries(candles, NewOBV().Add)
+}
+
+// VWAP is the volume weighted average price: the average of the typical price
+// (high + low + close) / 3 of the candles, weighted by their volume.
+type VWAP struct {
+	anchor   time.Duration
+	session  time.Time
+	volume   float64
+	weighted float64
+}
+
+// NewVWAP returns a volume weighted average price, restarting on every anchor
+// boundary (UTC), ex: 24*time.Hour for a daily VWAP. 0 never restarts.
+func NewVWAP(anchor time.Duration) *VWAP {
+	return &VWAP{anchor: anchor}
+}
+
+// Add adds a candle and returns the average, ok once some volume was added.
+func (v *VWAP) Add(c *bittrex.Candle) (value float64, ok bool) {
+	if v.anchor > 0 {
+		if session := c.TimeStamp.UTC().Truncate(v.anchor); !session.Equal(v.session) {
+			v.session = session
+			v.Reset()
+		}
+	}
+	v.volume += c.Volume
+	v.weighted += c.Volume * (c.High + c.Low + c.Close) / 3
+	return v.Value()
+}
+
+// Reset restarts the average.
+func (v *VWAP) Reset() {
+	v.volume, v.weighted = 0, 0
+}
+
+// Value returns the current average.
+func (v *VWAP) Value() (value float64, ok bool) {
+	if v.volume == 0 {
+		return 0, false
+	}
+	return v.weighted / v.volume, true
+}
+
+// VWAPSeries returns the volume weighted average price of candles.
+func VWAPSeries(candles []*bittrex.Candle, anchor time.Duration) []float64 {
+	return candleSeries(candles, NewVWAP(anchor).Add)
+}
@@ -0,0 +1,48 @@
+package indicators_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/indicators"
+)
+
+func TestOBV(t *testing.T) {
+	// StockCharts, "On Balance Volume (OBV)".
+	candles := closes(53.26, 53.30, 53.32, 53.72, 54.19, 53.92, 54.65, 54.60, 54.21, 54.53, 53.79)
+	for i, volume := range []float64{8200, 8100, 8300, 8900, 9200, 13300, 10300, 9900, 10100, 11300, 12600} {
+		candles[i].Volume = volume
+	}
+	batch := indicators.OBVSeries(candles)
+	checkSeries(t, "OBV", batch, 0, []float64{0, 8100, 16400, 25300, 34500, 21200, 31500, 21600, 11500, 22800, 10200}, 0)
+	obv := indicators.NewOBV()
+	if _, ok := obv.Value(); ok {
+		t.Fatal("OBV ok before any candle")
+	}
+	checkStreaming(t, "OBV", batch, func(i int) (float64, bool) { return obv.Add(candles[i]) })
+}
+
+func TestVWAP(t *testing.T) {
+	candles := []*bittrex.Candle{
+		{TimeStamp: start, High: 12, Low: 9, Close: 9, Volume: 0},
+		{TimeStamp: start.Add(time.Hour), High: 12, Low: 9, Close: 9, Volume: 10},          // typical price 10
+		{TimeStamp: start.Add(90 * time.Minute), High: 12, Low: 10, Close: 11, Volume: 30}, // 11
+		{TimeStamp: start.Add(2 * time.Hour), High: 20, Low: 14, Close: 17, Volume: 5},     // 17, new session
+		{TimeStamp: start.Add(3 * time.Hour), High: 30, Low: 30, Close: 30, Volume: 0},
+	}
+	// No value before some volume, and a restart on every 2 hour boundary.
+	batch := indicators.VWAPSeries(candles, 2*time.Hour)
+	checkSeries(t, "VWAP", batch, 1, []float64{10, (100 + 330) / 40.0, 17, 17}, 1e-12)
+	vwap := indicators.NewVWAP(2 * time.Hour)
+	checkStreaming(t, "VWAP", batch, func(i int) (float64, bool) { return vwap.Add(candles[i]) })
+
+	// Without anchor, it never restarts.
+	if v := indicators.VWAPSeries(candles, 0); v[3] != (100+330+85)/45.0 {
+		t.Errorf("unanchored VWAP = %v", v[3])
+	}
+	vwap.Reset()
+	if _, ok := vwap.Value(); ok {
+		t.Error("VWAP ok after Reset")
+	}
+}
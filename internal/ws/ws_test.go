@@ -0,0 +1,186 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve starts a server upgrading every request and handing the connection to handle.
+func serve(t *testing.T, handle func(*Conn)) *httptest.Server {
+	t.Helper()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func dial(t *testing.T, s *httptest.Server) *Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := Dial(ctx, strings.Replace(s.URL, "http", "ws", 1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestEcho(t *testing.T) {
+	s := serve(t, func(c *Conn) {
+		for {
+			p, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = c.WriteMessage(p); err != nil {
+				return
+			}
+		}
+	})
+	conn := dial(t, s)
+	// The sizes cover the 7 bit, 16 bit and 64 bit payload lengths.
+	for _, n := range []int{0, 1, 125, 126, 200, 0xffff, 0x10000, 100000} {
+		p := bytes.Repeat([]byte("abcdefg"), n/7+1)[:n]
+		if err := conn.WriteMessage(p); err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, p) {
+			t.Fatalf("%d bytes: echoed %d bytes, not the same", n, len(got))
+		}
+	}
+}
+
+func TestClientFramesAreMasked(t *testing.T) {
+	masked := make(chan bool, 1)
+	s := serve(t, func(c *Conn) {
+		var head [2]byte
+		if _, err := c.br.Read(head[:]); err != nil {
+			return
+		}
+		masked <- head[1]&0x80 != 0
+	})
+	conn := dial(t, s)
+	if err := conn.WriteMessage([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case m := <-masked:
+		if !m {
+			t.Fatal("client frame not masked")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestFragmentedMessage(t *testing.T) {
+	s := serve(t, func(c *Conn) {
+		c.conn.Write([]byte{opText, 3, 'a', 'b', 'c'})
+		c.conn.Write([]byte{0x80 | opContinuation, 3, 'd', 'e', 'f'})
+		c.ReadMessage()
+	})
+	conn := dial(t, s)
+	got, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abcdef" {
+		t.Fatalf("got %q, want abcdef", got)
+	}
+}
+
+func TestPingIsAnswered(t *testing.T) {
+	pong := make(chan string, 1)
+	s := serve(t, func(c *Conn) {
+		c.writeFrame(opPing, []byte("are you there"))
+		c.WriteMessage([]byte("data"))
+		_, op, payload, err := c.readFrame()
+		if err == nil && op == opPong {
+			pong <- string(payload)
+		}
+		close(pong)
+	})
+	conn := dial(t, s)
+	got, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "data" {
+		t.Fatalf("got %q, want data", got)
+	}
+	select {
+	case p := <-pong:
+		if p != "are you there" {
+			t.Fatalf("pong %q, want the ping payload", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pong")
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := serve(t, func(c *Conn) {
+		c.writeFrame(opClose, nil)
+		c.readFrame()
+	})
+	conn := dial(t, s)
+	if _, err := conn.ReadMessage(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("got %v, want ErrClosed", err)
+	}
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	s := serve(t, func(c *Conn) {})
+	resp, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestDialChecksAccept(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Sec-WebSocket-Accept", "wrong")
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}))
+	defer s.Close()
+	_, err := Dial(context.Background(), strings.Replace(s.URL, "http", "ws", 1), nil)
+	if err == nil || !strings.Contains(err.Error(), "Sec-WebSocket-Accept") {
+		t.Fatalf("got %v, want a bad accept error", err)
+	}
+}
+
+func TestDialRejectsNonUpgrade(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	defer s.Close()
+	if _, err := Dial(context.Background(), s.URL, nil); err == nil {
+		t.Fatal("dial succeeded without an upgrade")
+	}
+	if _, err := Dial(context.Background(), "ftp://example.com", nil); err == nil {
+		t.Fatal("dial accepted an ftp url")
+	}
+}
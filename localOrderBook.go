@@ -0,0 +1,354 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrOrderBookGap is returned when a delta does not follow the sequence of the
+// local order book and a fresh snapshot could not fill the gap yet.
+var ErrOrderBookGap = errors.New("bittrex: order book sequence gap")
+
+// maxPendingDeltas bounds the deltas buffered while the book is out of sync.
+const maxPendingDeltas = 10000
+
+// OrderBookSnapshotFunc fetches a full order book along with its sequence number.
+type OrderBookSnapshotFunc func(ctx context.Context) (*V3OrderBook, error)
+
+// OrderBookView is an immutable copy of a LocalOrderBook. It may be shared by
+// any number of goroutines.
+type OrderBookView struct {
+	Symbol   string
+	Sequence int64
+	Bids     []V3OrderBookEntry // best (highest) rate first
+	Asks     []V3OrderBookEntry // best (lowest) rate first
+	Stale    bool               // the book lost the delta sequence and waits for a snapshot
+}
+
+// BestBid returns the highest bid, ok is false if there is none.
+func (v *OrderBookView) BestBid() (entry V3OrderBookEntry, ok bool) {
+	if len(v.Bids) == 0 {
+		return entry, false
+	}
+	return v.Bids[0], true
+}
+
+// BestAsk returns the lowest ask, ok is false if there is none.
+func (v *OrderBookView) BestAsk() (entry V3OrderBookEntry, ok bool) {
+	if len(v.Asks) == 0 {
+		return entry, false
+	}
+	return v.Asks[0], true
+}
+
+// QuantityAt returns the bid and ask quantities at exactly rate.
+func (v *OrderBookView) QuantityAt(rate float64) (bid, ask float64) {
+	for _, e := range v.Bids {
+		if e.Rate == rate {
+			bid = e.Quantity
+			break
+		}
+	}
+	for _, e := range v.Asks {
+		if e.Rate == rate {
+			ask = e.Quantity
+			break
+		}
+	}
+	return
+}
+
+// DepthAt returns the cumulated quantity of the bids at rate or above, and of
+// the asks at rate or below: what a sell, or a buy, limited at rate could fill.
+func (v *OrderBookView) DepthAt(rate float64) (bid, ask float64) {
+	for _, e := range v.Bids {
+		if e.Rate < rate {
+			break
+		}
+		bid += e.Quantity
+	}
+	for _, e := range v.Asks {
+		if e.Rate > rate {
+			break
+		}
+		ask += e.Quantity
+	}
+	return
+}
+
+// OrderBookOption configures a LocalOrderBook.
+type OrderBookOption func(*LocalOrderBook)
+
+// WithOrderBookBackoff sets the delay before fetching a snapshot again after a
+// failed one, doubled after each failure from min up to max. Defaults to 1s
+// and 1m.
+func WithOrderBookBackoff(min, max time.Duration) OrderBookOption {
+	return func(l *LocalOrderBook) {
+		l.minBackoff, l.maxBackoff = min, max
+	}
+}
+
+// LocalOrderBook is an order book kept up to date from a REST snapshot and the
+// streamed deltas. It checks delta sequence numbers and fetches a fresh
+// snapshot when it detects a gap. The snapshot is fetched in the background,
+// once, while the deltas received meanwhile are buffered, and failed
+// snapshots are tried again with a backoff. Until the book is synced again,
+// its views are marked Stale.
+//
+// Writers (Sync, Apply, Run) are serialized, but never wait for a snapshot.
+// Readers get an immutable view through View without ever waiting for writers.
+type LocalOrderBook struct {
+	symbol     string
+	snapshot   OrderBookSnapshotFunc
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu        sync.Mutex
+	synced    bool
+	syncing   bool          // a snapshot is being fetched for Apply
+	backoff   time.Duration // delay after the next failed snapshot
+	nextSync  time.Time     // no snapshot before, after a failed one
+	sequence  int64
+	bids      map[float64]float64 // rate -> quantity
+	asks      map[float64]float64
+	pending   []*StreamOrderBook // deltas received while out of sync
+	lastError error
+
+	view atomic.Value // *OrderBookView
+}
+
+// NewLocalOrderBook returns an empty order book of a v3 market symbol, which
+// gets its snapshots from snapshot.
+func NewLocalOrderBook(symbol string, snapshot OrderBookSnapshotFunc, opts ...OrderBookOption) *LocalOrderBook {
+	l := &LocalOrderBook{
+		symbol:     strings.ToUpper(symbol),
+		snapshot:   snapshot,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.backoff = l.minBackoff
+	l.view.Store(&OrderBookView{Symbol: l.symbol, Stale: true})
+	return l
+}
+
+// NewLocalOrderBook returns an order book of a v3 market symbol taking its
+// snapshots from the v3 REST API. depth should match the streamed channel, see OrderBookChannel.
+func (b *Bittrex) NewLocalOrderBook(symbol string, depth int, opts ...OrderBookOption) *LocalOrderBook {
+	return NewLocalOrderBook(symbol, func(ctx context.Context) (*V3OrderBook, error) {
+		return b.V3().GetOrderBook(ctx, symbol, depth)
+	}, opts...)
+}
+
+// View returns the current state of the book. Check its Stale flag before
+// trading on it.
+func (l *LocalOrderBook) View() *OrderBookView {
+	return l.view.Load().(*OrderBookView)
+}
+
+// BestBid returns the current highest bid, ok is false if there is none or
+// the book is stale.
+func (l *LocalOrderBook) BestBid() (entry V3OrderBookEntry, ok bool) {
+	v := l.View()
+	if v.Stale {
+		return entry, false
+	}
+	return v.BestBid()
+}
+
+// BestAsk returns the current lowest ask, ok is false if there is none or
+// the book is stale.
+func (l *LocalOrderBook) BestAsk() (entry V3OrderBookEntry, ok bool) {
+	v := l.View()
+	if v.Stale {
+		return entry, false
+	}
+	return v.BestAsk()
+}
+
+// Synced reports whether the book currently follows the delta sequence.
+func (l *LocalOrderBook) Synced() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.synced
+}
+
+// Err returns the error of the last failed snapshot, nil once synced.
+func (l *LocalOrderBook) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastError
+}
+
+// Sync loads a fresh snapshot, then applies the buffered deltas following it.
+func (l *LocalOrderBook) Sync(ctx context.Context) error {
+	book, err := l.snapshot(ctx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.failLocked(err)
+		return err
+	}
+	return l.loadLocked(book)
+}
+
+// Apply applies a streamed delta. Deltas of other markets and deltas older
+// than the book are ignored. On a sequence gap, the delta is buffered, the
+// book is marked stale and a snapshot is fetched in the background with ctx,
+// unless one is already in flight or a failed one is backing off: Apply then
+// returns ErrOrderBookGap until the book is synced again.
+func (l *LocalOrderBook) Apply(ctx context.Context, delta *StreamOrderBook) error {
+	if !strings.EqualFold(delta.MarketSymbol, l.symbol) {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.synced {
+		if delta.Sequence <= l.sequence {
+			return nil
+		}
+		if delta.Sequence == l.sequence+1 {
+			l.applyLocked(delta)
+			l.publishLocked()
+			return nil
+		}
+		l.synced = false
+		l.publishLocked()
+	}
+	if len(l.pending) >= maxPendingDeltas {
+		l.pending = l.pending[1:]
+	}
+	l.pending = append(l.pending, delta)
+	if !l.syncing && !time.Now().Before(l.nextSync) {
+		l.syncing = true
+		go l.resync(ctx)
+	}
+	return ErrOrderBookGap
+}
+
+// Run synchronizes the book and applies the deltas received on deltas until
+// ctx is done or deltas is closed. Failed snapshots are tried again on the
+// next deltas, after a backoff.
+func (l *LocalOrderBook) Run(ctx context.Context, deltas <-chan *StreamOrderBook) error {
+	l.Sync(ctx)
+	for {
+		select {
+		case delta, ok := <-deltas:
+			if !ok {
+				return nil
+			}
+			l.Apply(ctx, delta)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resync fetches a snapshot for Apply, without holding l.mu.
+func (l *LocalOrderBook) resync(ctx context.Context) {
+	book, err := l.snapshot(ctx)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.syncing = false
+	if err != nil {
+		l.failLocked(err)
+		return
+	}
+	l.loadLocked(book)
+}
+
+// failLocked marks the book out of sync after a failed snapshot, and delays the next one.
+func (l *LocalOrderBook) failLocked(err error) {
+	l.synced = false
+	l.lastError = err
+	l.nextSync = time.Now().Add(l.backoff)
+	if l.backoff *= 2; l.backoff > l.maxBackoff {
+		l.backoff = l.maxBackoff
+	}
+	l.publishLocked()
+}
+
+// loadLocked replaces the book with a snapshot, then applies the buffered
+// deltas following it.
+func (l *LocalOrderBook) loadLocked(book *V3OrderBook) error {
+	if l.synced && book.Sequence <= l.sequence {
+		return nil // the book already moved past this snapshot
+	}
+	l.sequence = book.Sequence
+	l.bids = make(map[float64]float64, len(book.Bid))
+	for _, e := range book.Bid {
+		l.bids[e.Rate] = e.Quantity
+	}
+	l.asks = make(map[float64]float64, len(book.Ask))
+	for _, e := range book.Ask {
+		l.asks[e.Rate] = e.Quantity
+	}
+
+	pending := l.pending
+	l.pending = nil
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Sequence < pending[j].Sequence })
+	for i, delta := range pending {
+		if delta.Sequence <= l.sequence {
+			continue
+		}
+		if delta.Sequence != l.sequence+1 {
+			// The snapshot is older than the buffered deltas: keep them for the next attempt.
+			l.pending = pending[i:]
+			l.failLocked(ErrOrderBookGap)
+			return ErrOrderBookGap
+		}
+		l.applyLocked(delta)
+	}
+	l.synced = true
+	l.lastError = nil
+	l.backoff = l.minBackoff
+	l.nextSync = time.Time{}
+	l.publishLocked()
+	return nil
+}
+
+func (l *LocalOrderBook) applyLocked(delta *StreamOrderBook) {
+	for _, e := range delta.BidDeltas {
+		if e.Quantity == 0 {
+			delete(l.bids, e.Rate)
+		} else {
+			l.bids[e.Rate] = e.Quantity
+		}
+	}
+	for _, e := range delta.AskDeltas {
+		if e.Quantity == 0 {
+			delete(l.asks, e.Rate)
+		} else {
+			l.asks[e.Rate] = e.Quantity
+		}
+	}
+	l.sequence = delta.Sequence
+}
+
+// publishLocked stores a new immutable view of the book.
+func (l *LocalOrderBook) publishLocked() {
+	view := &OrderBookView{
+		Symbol:   l.symbol,
+		Sequence: l.sequence,
+		Stale:    !l.synced,
+		Bids:     make([]V3OrderBookEntry, 0, len(l.bids)),
+		Asks:     make([]V3OrderBookEntry, 0, len(l.asks)),
+	}
+	for rate, quantity := range l.bids {
+		view.Bids = append(view.Bids, V3OrderBookEntry{Quantity: quantity, Rate: rate})
+	}
+	for rate, quantity := range l.asks {
+		view.Asks = append(view.Asks, V3OrderBookEntry{Quantity: quantity, Rate: rate})
+	}
+	sort.Slice(view.Bids, func(i, j int) bool { return view.Bids[i].Rate > view.Bids[j].Rate })
+	sort.Slice(view.Asks, func(i, j int) bool { return view.Asks[i].Rate < view.Asks[j].Rate })
+	l.view.Store(view)
+}
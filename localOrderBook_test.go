@@ -0,0 +1,223 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSnapshots serves the snapshots of a test, counting the calls. When gate
+// is set, each call waits for a value on it.
+type fakeSnapshots struct {
+	mu    sync.Mutex
+	book  *V3OrderBook
+	err   error
+	calls int
+	gate  chan struct{}
+}
+
+func (f *fakeSnapshots) get(ctx context.Context) (*V3OrderBook, error) {
+	f.mu.Lock()
+	f.calls++
+	gate := f.gate
+	f.mu.Unlock()
+	if gate != nil {
+		<-gate
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.book, f.err
+}
+
+func (f *fakeSnapshots) set(book *V3OrderBook, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.book, f.err = book, err
+}
+
+func (f *fakeSnapshots) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func bookDelta(sequence int64, bids []V3OrderBookEntry, asks ...V3OrderBookEntry) *StreamOrderBook {
+	return &StreamOrderBook{MarketSymbol: "LTC-BTC", Sequence: sequence, BidDeltas: bids, AskDeltas: asks}
+}
+
+func entry(rate, quantity float64) V3OrderBookEntry {
+	return V3OrderBookEntry{Rate: rate, Quantity: quantity}
+}
+
+func waitSynced(t *testing.T, l *LocalOrderBook) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !l.Synced() {
+		if time.Now().After(deadline) {
+			t.Fatal("book not synced")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLocalOrderBookApply(t *testing.T) {
+	f := &fakeSnapshots{book: &V3OrderBook{
+		Sequence: 10,
+		Bid:      []V3OrderBookEntry{entry(0.010, 1), entry(0.011, 2)},
+		Ask:      []V3OrderBookEntry{entry(0.013, 3), entry(0.012, 4)},
+	}}
+	l := NewLocalOrderBook("ltc-btc", f.get)
+	if v := l.View(); !v.Stale {
+		t.Fatal("book not stale before its first snapshot")
+	}
+	if err := l.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	v := l.View()
+	if v.Stale || v.Sequence != 10 {
+		t.Fatalf("view %+v after sync", v)
+	}
+	if want := []V3OrderBookEntry{entry(0.011, 2), entry(0.010, 1)}; !reflect.DeepEqual(v.Bids, want) {
+		t.Fatalf("bids %v, want %v", v.Bids, want)
+	}
+	if want := []V3OrderBookEntry{entry(0.012, 4), entry(0.013, 3)}; !reflect.DeepEqual(v.Asks, want) {
+		t.Fatalf("asks %v, want %v", v.Asks, want)
+	}
+
+	ctx := context.Background()
+	for _, d := range []*StreamOrderBook{
+		bookDelta(11, []V3OrderBookEntry{entry(0.011, 0), entry(0.0115, 5)}), // remove a level, add one
+		bookDelta(9, []V3OrderBookEntry{entry(0.5, 1)}),                      // older, ignored
+		{MarketSymbol: "ETH-BTC", Sequence: 50},                              // other market, ignored
+		bookDelta(12, nil, entry(0.012, 1)),                                  // update a level
+	} {
+		if err := l.Apply(ctx, d); err != nil {
+			t.Fatalf("delta %d: %v", d.Sequence, err)
+		}
+	}
+	v = l.View()
+	if v.Sequence != 12 || v.Stale {
+		t.Fatalf("view %+v", v)
+	}
+	if bid, ok := l.BestBid(); !ok || bid != entry(0.0115, 5) {
+		t.Fatalf("best bid %v %v", bid, ok)
+	}
+	if ask, ok := l.BestAsk(); !ok || ask != entry(0.012, 1) {
+		t.Fatalf("best ask %v %v", ask, ok)
+	}
+	if bid, ask := v.DepthAt(0.0105); bid != 5 || ask != 0 {
+		t.Fatalf("depth at 0.0105: %v %v", bid, ask)
+	}
+	if f.count() != 1 {
+		t.Fatalf("%d snapshots, want 1", f.count())
+	}
+}
+
+func TestLocalOrderBookGapResyncsOnce(t *testing.T) {
+	f := &fakeSnapshots{book: &V3OrderBook{Sequence: 10, Bid: []V3OrderBookEntry{entry(0.010, 1)}}}
+	l := NewLocalOrderBook("LTC-BTC", f.get)
+	ctx := context.Background()
+	if err := l.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	gate := make(chan struct{})
+	f.mu.Lock()
+	f.gate = gate
+	f.mu.Unlock()
+	// 11 is lost: 12 to 15 are buffered while a single snapshot is in flight.
+	for seq := int64(12); seq <= 15; seq++ {
+		if err := l.Apply(ctx, bookDelta(seq, []V3OrderBookEntry{entry(0.010, float64(seq))})); !errors.Is(err, ErrOrderBookGap) {
+			t.Fatalf("delta %d: got %v, want ErrOrderBookGap", seq, err)
+		}
+	}
+	if !l.View().Stale || l.Synced() {
+		t.Fatal("book not stale after a gap")
+	}
+	if _, ok := l.BestBid(); ok {
+		t.Fatal("best bid of a stale book")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for f.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	l.Apply(ctx, bookDelta(16, nil))
+	if n := f.count(); n != 2 {
+		t.Fatalf("%d snapshots, want 2: one sync and one resync", n)
+	}
+
+	f.set(&V3OrderBook{Sequence: 13, Bid: []V3OrderBookEntry{entry(0.010, 13)}}, nil)
+	close(gate)
+	waitSynced(t, l)
+	v := l.View()
+	if v.Stale || v.Sequence != 16 || v.Bids[0].Quantity != 15 {
+		t.Fatalf("view %+v, want the buffered deltas applied up to 16", v)
+	}
+	if err := l.Apply(ctx, bookDelta(17, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.count(); n != 2 {
+		t.Fatalf("%d snapshots, want 2", n)
+	}
+}
+
+func TestLocalOrderBookSnapshotBackoff(t *testing.T) {
+	f := &fakeSnapshots{err: errors.New("unavailable")}
+	l := NewLocalOrderBook("LTC-BTC", f.get, WithOrderBookBackoff(50*time.Millisecond, time.Second))
+	ctx := context.Background()
+	if err := l.Sync(ctx); err == nil {
+		t.Fatal("sync succeeded")
+	}
+	if l.Err() == nil || !l.View().Stale {
+		t.Fatal("failed snapshot not reported")
+	}
+	// Within the backoff, deltas are buffered without fetching snapshots.
+	for seq := int64(1); seq <= 20; seq++ {
+		l.Apply(ctx, bookDelta(seq, nil))
+	}
+	if n := f.count(); n != 1 {
+		t.Fatalf("%d snapshots during the backoff, want 1", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	f.set(&V3OrderBook{Sequence: 20}, nil)
+	l.Apply(ctx, bookDelta(21, []V3OrderBookEntry{entry(0.01, 1)}))
+	waitSynced(t, l)
+	if n := f.count(); n != 2 {
+		t.Fatalf("%d snapshots, want 2", n)
+	}
+	if v := l.View(); v.Sequence != 21 || len(v.Bids) != 1 || l.Err() != nil {
+		t.Fatalf("view %+v, err %v", v, l.Err())
+	}
+}
+
+func TestLocalOrderBookSnapshotBehindDeltas(t *testing.T) {
+	f := &fakeSnapshots{book: &V3OrderBook{Sequence: 5}}
+	l := NewLocalOrderBook("LTC-BTC", f.get, WithOrderBookBackoff(20*time.Millisecond, time.Second))
+	ctx := context.Background()
+	l.Sync(ctx)
+
+	// The snapshot at 5 cannot be followed by 8: the deltas are kept for the next one.
+	l.Apply(ctx, bookDelta(8, nil))
+	deadline := time.Now().Add(5 * time.Second)
+	for f.count() < 2 || !errors.Is(l.Err(), ErrOrderBookGap) {
+		if time.Now().After(deadline) {
+			t.Fatal("no resync")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if l.Synced() {
+		t.Fatal("synced on a snapshot behind the deltas")
+	}
+
+	f.set(&V3OrderBook{Sequence: 8}, nil)
+	time.Sleep(30 * time.Millisecond)
+	l.Apply(ctx, bookDelta(9, nil))
+	waitSynced(t, l)
+	if v := l.View(); v.Sequence != 9 {
+		t.Fatalf("sequence %d, want 9", v.Sequence)
+	}
+}
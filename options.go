@@ -0,0 +1,60 @@
+package bittrex
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures the client built by New.
+type Option func(*client)
+
+// WithBaseURL routes every v1.1 call through root instead of API_BASE+API_VERSION.
+// root must include the version segment, ex: http://127.0.0.1:8080/api/v1.1
+func WithBaseURL(root string) Option {
+	return func(c *client) {
+		c.baseURL = strings.TrimRight(root, "/")
+	}
+}
+
+// WithV2BaseURL routes every v2.0 call (GetTicks, GetDistribution) through root
+// instead of API_V2_BASE, ex: http://127.0.0.1:8080/Api/v2.0
+func WithV2BaseURL(root string) Option {
+	return func(c *client) {
+		c.v2BaseURL = strings.TrimRight(root, "/")
+	}
+}
+
+// WithHTTPClient makes the client use httpClient for every request.
+// A positive httpClient.Timeout is also used as the request timeout.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+		if httpClient.Timeout > 0 {
+			c.httpTimeout = httpClient.Timeout
+		}
+	}
+}
+
+// WithTimeout sets the maximum duration of a single request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.httpTimeout = timeout
+	}
+}
+
+// WithV3BaseURL routes every v3 call through root instead of API_V3_BASE,
+// ex: http://127.0.0.1:8080/v3
+func WithV3BaseURL(root string) Option {
+	return func(c *client) {
+		c.v3BaseURL = strings.TrimRight(root, "/")
+	}
+}
+
+// WithAPIV3 makes the Bittrex methods use the v3 API wherever v3 has an
+// equivalent resource. Methods without equivalent keep calling v1.1 or v2.0.
+func WithAPIV3() Option {
+	return func(c *client) {
+		c.useV3 = true
+	}
+}
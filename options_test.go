@@ -0,0 +1,93 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOptionsRouteRequests(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		io.WriteString(w, `{"success":true,"message":"","result":[]}`)
+	}))
+	defer s.Close()
+	transport := &countingTransport{}
+	b := bittrex.New("key", "secret",
+		bittrex.WithBaseURL(s.URL+"/one/api/v1.1/"),
+		bittrex.WithV2BaseURL(s.URL+"/two/Api/v2.0"),
+		bittrex.WithHTTPClient(&http.Client{Transport: transport}))
+
+	if _, err := b.GetMarkets(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetTicks("BTC-LTC", bittrex.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetOpenOrders("BTC-LTC"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/one/api/v1.1/public/getmarkets", "/two/Api/v2.0/pub/market/GetTicks", "/one/api/v1.1/market/getopenorders"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("paths %v, want %v", paths, want)
+	}
+	if transport.n != 3 {
+		t.Fatalf("%d requests through the client, want 3", transport.n)
+	}
+}
+
+func TestOptionsTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer s.Close()
+
+	for name, opts := range map[string][]bittrex.Option{
+		"WithTimeout":    {bittrex.WithHTTPClient(s.Client()), bittrex.WithTimeout(50 * time.Millisecond)},
+		"WithHTTPClient": {bittrex.WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond})},
+	} {
+		b := bittrex.New("key", "secret", append([]bittrex.Option{bittrex.WithBaseURL(s.URL)}, opts...)...)
+		start := time.Now()
+		if _, err := b.GetMarkets(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("%s: returned after %v, want 50ms", name, d)
+		}
+	}
+	// The timeout is per request: a longer context does not extend it.
+	b := bittrex.New("key", "secret", bittrex.WithBaseURL(s.URL), bittrex.WithTimeout(50*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := b.GetMarketsCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+}
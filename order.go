@@ -2,6 +2,8 @@ package bittrex
 
 import (
 	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -45,6 +47,33 @@ type Order struct {
 	ConditionTarget            string
 }
 
+// UnmarshalJSON decodes an order as returned by getorder. ConditionTarget is
+// sent as a number, or null, and kept as its decimal text, ex: "0.000015".
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type order Order
+	s := struct {
+		*order
+		ConditionTarget json.RawMessage `json:"ConditionTarget"`
+	}{order: (*order)(o)}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	o.ConditionTarget = ""
+	target := strings.TrimSpace(string(s.ConditionTarget))
+	switch {
+	case target == "" || target == "null":
+	case strings.HasPrefix(target, `"`):
+		return json.Unmarshal(s.ConditionTarget, &o.ConditionTarget)
+	default:
+		f, err := strconv.ParseFloat(target, 64)
+		if err != nil {
+			return err
+		}
+		o.ConditionTarget = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return nil
+}
+
 func (o *OrderHistory) UnmarshalJSON(data []byte) (err error) {
 	s := struct {
 		OrderUuid         string  `json:"OrderUuid"`
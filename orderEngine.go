@@ -0,0 +1,654 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of VirtualOrder.
+const (
+	TrailingStop = "TRAILING_STOP"
+	OCO          = "OCO"
+	Bracket      = "BRACKET"
+)
+
+// Statuses of a VirtualOrder.
+const (
+	VirtualPending    = "PENDING"    // a bracket waiting for its entry order to fill
+	VirtualActive     = "ACTIVE"     // watching the price
+	VirtualTriggering = "TRIGGERING" // the real order is being placed
+	VirtualTriggered  = "TRIGGERED"  // the real order was placed
+	VirtualCanceled   = "CANCELED"   // canceled, or a bracket whose entry was canceled unfilled
+	VirtualFailed     = "FAILED"     // the real order could not be placed
+)
+
+// VirtualOrder is an order type Bittrex does not offer, emulated by an
+// OrderEngine watching the price. Side is the side of the exit order fired
+// when the trigger hits, ex: SELL to protect a long position.
+//
+// A TrailingStop follows the best price seen, at TrailPercent (0.05 for 5%)
+// or TrailAmount from it, and fires a market order when the price crosses
+// back the stop.
+//
+// An OCO fires a limit order at TakeProfit when the price reaches it, or a
+// market order when the price crosses StopLoss, whichever comes first.
+//
+// A Bracket places a limit entry order of the opposite side at EntryRate,
+// then acts as an OCO on the quantity filled once the entry is closed.
+type VirtualOrder struct {
+	ID       string
+	Type     string
+	Market   string
+	Side     string // BUY or SELL
+	Quantity float64
+
+	TrailPercent float64 // TrailingStop
+	TrailAmount  float64 // TrailingStop
+	TakeProfit   float64 // OCO and Bracket, 0 for none
+	StopLoss     float64 // OCO and Bracket, 0 for none
+	EntryRate    float64 // Bracket
+
+	Status    string
+	EntryUuid string  // entry order of a Bracket
+	Best      float64 // best price seen by a TrailingStop
+	Stop      float64 // current stop price of a TrailingStop
+	Rate      float64 // rate of the limit order placed when triggered, 0 for a market order
+	OrderUuid string  // order placed when triggered
+	Reason    string  // what triggered the order, or why it failed
+	Created   time.Time
+	Updated   time.Time
+
+	unsettled bool // TRIGGERING, and its order may have been placed: loaded so, or its placement timed out
+}
+
+// selling reports whether o exits through a sell order.
+func (o *VirtualOrder) selling() bool {
+	return o.Side == "SELL"
+}
+
+// follow moves the stop of a trailing stop after a new price.
+func (o *VirtualOrder) follow(price float64) {
+	if o.Best == 0 || (o.selling() && price > o.Best) || (!o.selling() && price < o.Best) {
+		o.Best = price
+	}
+	trail := o.TrailAmount
+	if o.TrailPercent > 0 {
+		trail = o.Best * o.TrailPercent
+	}
+	if o.selling() {
+		o.Stop = round8(o.Best - trail)
+	} else {
+		o.Stop = round8(o.Best + trail)
+	}
+}
+
+// trigger returns what price triggers of an active order, if anything: the
+// rate of the limit order to place, 0 for a market order, and the reason.
+func (o *VirtualOrder) trigger(price float64) (rate float64, reason string, ok bool) {
+	if o.Type == TrailingStop {
+		o.follow(price)
+		if (o.selling() && price <= o.Stop) || (!o.selling() && price >= o.Stop) {
+			return 0, fmt.Sprintf("price %v crossed the trailing stop %v", price, o.Stop), true
+		}
+		return 0, "", false
+	}
+	if o.StopLoss > 0 && ((o.selling() && price <= o.StopLoss) || (!o.selling() && price >= o.StopLoss)) {
+		return 0, fmt.Sprintf("price %v crossed the stop loss %v", price, o.StopLoss), true
+	}
+	if o.TakeProfit > 0 && ((o.selling() && price >= o.TakeProfit) || (!o.selling() && price <= o.TakeProfit)) {
+		return o.TakeProfit, fmt.Sprintf("price %v reached the take profit %v", price, o.TakeProfit), true
+	}
+	return 0, "", false
+}
+
+func (o *VirtualOrder) validate() error {
+	switch {
+	case o.Type != TrailingStop && o.Type != OCO && o.Type != Bracket:
+		return fmt.Errorf("bittrex: unknown virtual order type %q", o.Type)
+	case o.Market == "":
+		return errors.New("bittrex: virtual order without market")
+	case o.Side != "BUY" && o.Side != "SELL":
+		return errors.New("bittrex: virtual order side must be BUY or SELL")
+	case o.Quantity <= 0:
+		return errors.New("bittrex: virtual order without quantity")
+	case o.Type == TrailingStop && o.TrailPercent <= 0 && o.TrailAmount <= 0:
+		return errors.New("bittrex: trailing stop without trail")
+	case o.Type != TrailingStop && o.TakeProfit <= 0 && o.StopLoss <= 0:
+		return errors.New("bittrex: virtual order without take profit nor stop loss")
+	case o.Type == Bracket && o.EntryRate <= 0:
+		return errors.New("bittrex: bracket without entry rate")
+	}
+	return nil
+}
+
+// EngineEvent reports a change of a virtual order: its new status, and the
+// error which made it fail, if any.
+type EngineEvent struct {
+	Order VirtualOrder
+	Err   error
+}
+
+// EngineOption configures an OrderEngine.
+type EngineOption func(*OrderEngine)
+
+// WithEnginePollInterval sets how often prices, and bracket entries, are
+// polled. Defaults to 5s.
+func WithEnginePollInterval(interval time.Duration) EngineOption {
+	return func(e *OrderEngine) {
+		e.interval = interval
+	}
+}
+
+// WithEngineStateFile makes the engine persist its virtual orders to path.
+// They are loaded back by NewOrderEngine, so that a restart does not drop protection.
+func WithEngineStateFile(path string) EngineOption {
+	return func(e *OrderEngine) {
+		e.path = path
+	}
+}
+
+// WithEngineBuffer sets the capacity of the event and error channels. Defaults to 256.
+func WithEngineBuffer(n int) EngineOption {
+	return func(e *OrderEngine) {
+		e.bufSize = n
+	}
+}
+
+// OrderEngine emulates trailing stops, OCO and bracket orders: it watches the
+// prices, from polling or a stream of tickers, and places real orders on the
+// exchange when their triggers hit. The order placed depends only on the last
+// trade price, so a stop fires a market order which may fill further away.
+//
+// A triggered order is saved TRIGGERING before its real order is placed. If
+// the engine stops meanwhile, the next one looks for that order among the
+// orders of the market before firing again, see Run.
+//
+// Events must be received, or the engine blocks.
+type OrderEngine struct {
+	ex       Exchange
+	interval time.Duration
+	path     string
+	bufSize  int
+
+	events chan *EngineEvent
+	errs   chan error
+
+	mu     sync.Mutex
+	orders map[string]*VirtualOrder // pending, active and triggering orders
+}
+
+// NewOrderEngine returns an engine placing its orders on ex, a *Bittrex or a
+// *PaperBittrex. With WithEngineStateFile, it resumes the virtual orders
+// saved by a previous engine, if any.
+func NewOrderEngine(ex Exchange, opts ...EngineOption) (*OrderEngine, error) {
+	e := &OrderEngine{
+		ex:       ex,
+		interval: 5 * time.Second,
+		bufSize:  256,
+		orders:   map[string]*VirtualOrder{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.events = make(chan *EngineEvent, e.bufSize)
+	e.errs = make(chan error, e.bufSize)
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Events returns the channel of the status changes of the virtual orders. It
+// is closed when Run returns.
+func (e *OrderEngine) Events() <-chan *EngineEvent { return e.events }
+
+// Errors returns the channel of non fatal errors: failed polls and saves, and
+// transient failures of the orders to place, which are tried again on the
+// next price. Errors are dropped if it is full.
+func (e *OrderEngine) Errors() <-chan error { return e.errs }
+
+// Add starts to manage order, and returns it with its ID and status set. The
+// entry order of a bracket is placed right away.
+func (e *OrderEngine) Add(ctx context.Context, order VirtualOrder) (VirtualOrder, error) {
+	o := order
+	o.Type, o.Side, o.Market = strings.ToUpper(o.Type), strings.ToUpper(o.Side), strings.ToUpper(o.Market)
+	if err := o.validate(); err != nil {
+		return o, err
+	}
+	o.ID = newUuid()
+	o.Status = VirtualActive
+	o.Created = time.Now().UTC()
+	o.Updated = o.Created
+	o.Best, o.Stop, o.Rate, o.OrderUuid, o.EntryUuid, o.Reason = 0, 0, 0, "", "", ""
+	if o.Type == Bracket {
+		var err error
+		if o.selling() {
+			o.EntryUuid, err = e.ex.BuyLimitCtx(ctx, o.Market, o.Quantity, o.EntryRate)
+		} else {
+			o.EntryUuid, err = e.ex.SellLimitCtx(ctx, o.Market, o.Quantity, o.EntryRate)
+		}
+		if err != nil {
+			return o, err
+		}
+		o.Status = VirtualPending
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stored := o
+	e.orders[o.ID] = &stored
+	return o, e.saveLocked()
+}
+
+// Cancel stops managing a virtual order. The entry order of a pending bracket is canceled.
+func (e *OrderEngine) Cancel(ctx context.Context, id string) error {
+	e.mu.Lock()
+	o, ok := e.orders[id]
+	if !ok {
+		e.mu.Unlock()
+		return fmt.Errorf("bittrex: no virtual order %s", id)
+	}
+	entry := ""
+	if o.Status == VirtualPending {
+		entry = o.EntryUuid
+	}
+	e.mu.Unlock()
+
+	if entry != "" {
+		if err := e.ex.CancelOrderCtx(ctx, entry); err != nil && !errors.Is(err, ErrOrderNotOpen) {
+			return err
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.orders, id)
+	return e.saveLocked()
+}
+
+// Order returns the virtual order id, if it is still pending, active or triggering.
+func (e *OrderEngine) Order(id string) (VirtualOrder, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if o, ok := e.orders[id]; ok {
+		return *o, true
+	}
+	return VirtualOrder{}, false
+}
+
+// Orders returns the pending, active and triggering virtual orders, oldest first.
+func (e *OrderEngine) Orders() []VirtualOrder {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	orders := make([]VirtualOrder, 0, len(e.orders))
+	for _, o := range e.orders {
+		orders = append(orders, *o)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].Created.Equal(orders[j].Created) {
+			return orders[i].Created.Before(orders[j].Created)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+	return orders
+}
+
+// Run polls the prices of the markets with active virtual orders, and the
+// entries of the pending brackets, every poll interval until ctx is done.
+// The orders left TRIGGERING, by a previous engine or by a placement whose
+// answer was lost, are settled first on every poll: one whose real order is
+// found, placed since the trigger with the same side, quantity and rate, is
+// TRIGGERED, others are active again.
+// If tickers is not nil, see Stream.Tickers, prices are taken from it instead
+// of polling. The event channel is closed when Run returns, so Run may be
+// called only once.
+func (e *OrderEngine) Run(ctx context.Context, tickers <-chan *V3Ticker) error {
+	defer close(e.events)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	if err := e.poll(ctx, tickers == nil); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.poll(ctx, tickers == nil); err != nil {
+				return err
+			}
+		case t, ok := <-tickers:
+			if !ok {
+				tickers = nil
+				continue
+			}
+			if err := e.Update(ctx, V3Symbol(t.Symbol), t.LastTradeRate); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// poll settles the orders left triggering, checks the pending brackets, then
+// the prices if prices is set.
+func (e *OrderEngine) poll(ctx context.Context, prices bool) error {
+	if err := e.settle(ctx); err != nil {
+		return err
+	}
+	pending, markets := e.watched()
+	for _, o := range pending {
+		entry, err := e.ex.GetOrderCtx(ctx, o.EntryUuid)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.report(fmt.Errorf("bittrex: engine: bracket %s: %w", o.ID, err))
+			continue
+		}
+		if err = e.entryUpdate(ctx, o.ID, entry); err != nil {
+			return err
+		}
+	}
+	if !prices {
+		return nil
+	}
+	for _, market := range markets {
+		t, err := e.ex.GetTickerCtx(ctx, market)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.report(fmt.Errorf("bittrex: engine: %s: %w", market, err))
+			continue
+		}
+		if err = e.Update(ctx, market, t.Last); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watched returns the pending brackets and the markets of the active orders.
+func (e *OrderEngine) watched() (pending []VirtualOrder, markets []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	seen := map[string]bool{}
+	for _, o := range e.orders {
+		if o.Status == VirtualPending {
+			pending = append(pending, *o)
+		} else if o.Status == VirtualActive && !seen[o.Market] {
+			seen[o.Market] = true
+			markets = append(markets, o.Market)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+	sort.Strings(markets)
+	return pending, markets
+}
+
+// entryUpdate activates, or cancels, a pending bracket after the state of its entry order.
+func (e *OrderEngine) entryUpdate(ctx context.Context, id string, entry *Order) error {
+	if entry.IsOpen {
+		return nil
+	}
+	e.mu.Lock()
+	o, ok := e.orders[id]
+	if !ok || o.Status != VirtualPending {
+		e.mu.Unlock()
+		return nil
+	}
+	o.Updated = time.Now().UTC()
+	if filled := round8(entry.Quantity - entry.QuantityRemaining); filled > 0 {
+		o.Quantity = filled
+		o.Status = VirtualActive
+	} else {
+		o.Status = VirtualCanceled
+		o.Reason = "entry order closed unfilled"
+		delete(e.orders, id)
+	}
+	event := &EngineEvent{Order: *o}
+	if err := e.saveLocked(); err != nil {
+		e.report(err)
+	}
+	e.mu.Unlock()
+	return e.emit(ctx, event)
+}
+
+// Update feeds the last price of market to the engine, firing the orders it
+// triggers. Run calls it; it is exported for other price sources.
+// An order whose placement fails with a timeout, a network error or a 5xx
+// answer may still have been placed: it stays TRIGGERING until Run settles it.
+func (e *OrderEngine) Update(ctx context.Context, market string, price float64) error {
+	if price <= 0 {
+		return nil
+	}
+	market = strings.ToUpper(market)
+	var fires []*VirtualOrder
+	e.mu.Lock()
+	changed := false
+	for _, o := range e.orders {
+		if o.Market != market || o.Status != VirtualActive {
+			continue
+		}
+		best, stop := o.Best, o.Stop
+		if rate, reason, ok := o.trigger(price); ok {
+			o.Status, o.Rate, o.Reason = VirtualTriggering, rate, reason
+			fires = append(fires, o)
+		}
+		if o.Best != best || o.Stop != stop || o.Status != VirtualActive {
+			o.Updated = time.Now().UTC()
+			changed = true
+		}
+	}
+	// The triggering orders are saved before being placed. If that fails
+	// they are placed anyway: the protection matters more.
+	if changed {
+		if err := e.saveLocked(); err != nil {
+			e.report(err)
+		}
+	}
+	e.mu.Unlock()
+
+	sort.Slice(fires, func(i, j int) bool { return fires[i].ID < fires[j].ID })
+	for _, o := range fires {
+		uuid, err := e.place(ctx, o)
+
+		e.mu.Lock()
+		if err != nil && (ctx.Err() != nil || (IsTransient(err) && !errors.Is(err, ErrRateLimited))) {
+			// The order may have gone through without its answer coming
+			// back: settle looks for it before it can fire again.
+			o.unsettled = true
+			e.mu.Unlock()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.report(fmt.Errorf("bittrex: engine: %s: %w", o.ID, err))
+			continue
+		}
+		o.Updated = time.Now().UTC()
+		if errors.Is(err, ErrRateLimited) {
+			// Throttled requests are turned down before reaching the book.
+			o.Status, o.Rate, o.Reason = VirtualActive, 0, ""
+			if err := e.saveLocked(); err != nil {
+				e.report(err)
+			}
+			e.mu.Unlock()
+			e.report(fmt.Errorf("bittrex: engine: %s: %w", o.ID, err))
+			continue
+		}
+		if err != nil {
+			o.Status, o.Reason = VirtualFailed, err.Error()
+		} else {
+			o.Status, o.OrderUuid = VirtualTriggered, uuid
+		}
+		delete(e.orders, o.ID)
+		event := &EngineEvent{Order: *o, Err: err}
+		if err := e.saveLocked(); err != nil {
+			e.report(err)
+		}
+		e.mu.Unlock()
+		if err := e.emit(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// place sends the real order of o: a limit order at o.Rate, or a market order if it is 0.
+func (e *OrderEngine) place(ctx context.Context, o *VirtualOrder) (string, error) {
+	switch {
+	case o.selling() && o.Rate > 0:
+		return e.ex.SellLimitCtx(ctx, o.Market, o.Quantity, o.Rate)
+	case o.selling():
+		return e.ex.SellMarketCtx(ctx, o.Market, o.Quantity)
+	case o.Rate > 0:
+		return e.ex.BuyLimitCtx(ctx, o.Market, o.Quantity, o.Rate)
+	}
+	return e.ex.BuyMarketCtx(ctx, o.Market, o.Quantity)
+}
+
+// settle looks for the real orders of the triggering orders whose placement
+// is unknown: loaded TRIGGERING, or left so by a timeout or a 5xx answer. A
+// failed lookup is reported, and tried again on the next poll.
+func (e *OrderEngine) settle(ctx context.Context) error {
+	e.mu.Lock()
+	var unsettled []VirtualOrder
+	for _, o := range e.orders {
+		if o.unsettled {
+			unsettled = append(unsettled, *o)
+		}
+	}
+	e.mu.Unlock()
+	sort.Slice(unsettled, func(i, j int) bool { return unsettled[i].ID < unsettled[j].ID })
+
+	for _, u := range unsettled {
+		uuid, err := e.placed(ctx, &u)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			e.report(fmt.Errorf("bittrex: engine: %s: %w", u.ID, err))
+			continue
+		}
+
+		e.mu.Lock()
+		o, ok := e.orders[u.ID]
+		if !ok || !o.unsettled {
+			e.mu.Unlock()
+			continue
+		}
+		o.unsettled = false
+		o.Updated = time.Now().UTC()
+		if uuid == "" {
+			// Never placed: the next price triggers it again, if it still does.
+			o.Status, o.Rate, o.Reason = VirtualActive, 0, ""
+			if err := e.saveLocked(); err != nil {
+				e.report(err)
+			}
+			e.mu.Unlock()
+			continue
+		}
+		o.Status, o.OrderUuid = VirtualTriggered, uuid
+		delete(e.orders, o.ID)
+		event := &EngineEvent{Order: *o}
+		if err := e.saveLocked(); err != nil {
+			e.report(err)
+		}
+		e.mu.Unlock()
+		if err := e.emit(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placed returns the uuid of the real order of o, a triggering order, or ""
+// if there is none: an open or closed order of its market and side, with its
+// quantity and rate, placed since o was triggered. A minute is allowed for the
+// difference between the clocks of the exchange and the engine.
+func (e *OrderEngine) placed(ctx context.Context, o *VirtualOrder) (string, error) {
+	open, err := e.ex.GetOpenOrdersCtx(ctx, o.Market)
+	if err != nil {
+		return "", err
+	}
+	closed, err := e.ex.GetOrderHistoryCtx(ctx, o.Market)
+	if err != nil {
+		return "", err
+	}
+	since := o.Updated.Add(-time.Minute)
+	for _, h := range append(open, closed...) {
+		if strings.HasSuffix(h.OrderType, "_"+o.Side) && round8(h.Quantity) == round8(o.Quantity) &&
+			(o.Rate == 0 || round8(h.Limit) == round8(o.Rate)) && !h.TimeStamp.Before(since) {
+			return h.OrderUuid, nil
+		}
+	}
+	return "", nil
+}
+
+func (e *OrderEngine) emit(ctx context.Context, event *EngineEvent) error {
+	select {
+	case e.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *OrderEngine) report(err error) {
+	select {
+	case e.errs <- err:
+	default:
+	}
+}
+
+// load reads the virtual orders saved at e.path, if any.
+func (e *OrderEngine) load() error {
+	if e.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var orders []*VirtualOrder
+	if err = json.Unmarshal(data, &orders); err != nil {
+		return fmt.Errorf("bittrex: engine: %s: %w", e.path, err)
+	}
+	for _, o := range orders {
+		o.unsettled = o.Status == VirtualTriggering
+		e.orders[o.ID] = o
+	}
+	return nil
+}
+
+// saveLocked writes the virtual orders to e.path, through a temporary file so
+// that a crash never leaves a truncated file.
+func (e *OrderEngine) saveLocked() error {
+	if e.path == "" {
+		return nil
+	}
+	orders := make([]*VirtualOrder, 0, len(e.orders))
+	for _, o := range e.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	data, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.path)
+}
@@ -0,0 +1,279 @@
+package bittrex_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+func engine(t *testing.T, ex bittrex.Exchange, opts ...bittrex.EngineOption) *bittrex.OrderEngine {
+	t.Helper()
+	e, err := bittrex.NewOrderEngine(ex, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func nextEvent(t *testing.T, e *bittrex.OrderEngine) *bittrex.EngineEvent {
+	t.Helper()
+	select {
+	case event := <-e.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no engine event")
+		return nil
+	}
+}
+
+func update(t *testing.T, e *bittrex.OrderEngine, price float64) {
+	t.Helper()
+	if err := e.Update(context.Background(), "btc-ltc", price); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngineTrailingStop(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10), bittrex.WithPaperBalance("BTC", 1), bittrex.WithPaperFee(0))
+	e := engine(t, p)
+	sell, err := e.Add(context.Background(), bittrex.VirtualOrder{Type: "trailing_stop", Market: "btc-ltc", Side: "sell", Quantity: 5, TrailPercent: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sell.Status != bittrex.VirtualActive || sell.Market != "BTC-LTC" {
+		t.Fatalf("added %+v", sell)
+	}
+
+	// The stop follows the highest price, and stays when the price falls back.
+	for _, price := range []float64{0.01, 0.012, 0.011} {
+		update(t, e, price)
+	}
+	if o, _ := e.Order(sell.ID); o.Best != 0.012 || o.Stop != 0.0108 || o.Status != bittrex.VirtualActive {
+		t.Fatalf("trailing stop %+v", o)
+	}
+	update(t, e, 0.0108)
+	event := nextEvent(t, e)
+	if event.Err != nil || event.Order.ID != sell.ID || event.Order.Status != bittrex.VirtualTriggered || event.Order.Rate != 0 {
+		t.Fatalf("event %+v", event)
+	}
+	if o := order(t, p, event.Order.OrderUuid); o.Type != "MARKET_SELL" || o.Quantity != 5 || o.IsOpen {
+		t.Fatalf("order %+v", o)
+	}
+
+	// A buy stop follows the lowest price, here by an amount.
+	buy, err := e.Add(context.Background(), bittrex.VirtualOrder{Type: bittrex.TrailingStop, Market: "BTC-LTC", Side: "BUY", Quantity: 1, TrailAmount: 0.001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, price := range []float64{0.01, 0.009, 0.0099} {
+		update(t, e, price)
+	}
+	if o, _ := e.Order(buy.ID); o.Best != 0.009 || o.Stop != 0.01 {
+		t.Fatalf("trailing stop %+v", o)
+	}
+	update(t, e, 0.01)
+	if event = nextEvent(t, e); event.Order.ID != buy.ID || event.Order.Status != bittrex.VirtualTriggered {
+		t.Fatalf("event %+v", event)
+	}
+	if o := order(t, p, event.Order.OrderUuid); o.Type != "MARKET_BUY" || o.Quantity != 1 {
+		t.Fatalf("order %+v", o)
+	}
+	if orders := e.Orders(); len(orders) != 0 {
+		t.Fatalf("orders %+v still managed", orders)
+	}
+}
+
+func TestEngineOCO(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10), bittrex.WithPaperFee(0))
+	e := engine(t, p)
+	oco := bittrex.VirtualOrder{Type: bittrex.OCO, Market: "BTC-LTC", Side: "SELL", Quantity: 2, TakeProfit: 0.012, StopLoss: 0.008}
+	profit, err := e.Add(context.Background(), oco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(t, e, 0.0115)
+	update(t, e, 0.0125)
+	event := nextEvent(t, e)
+	if event.Order.ID != profit.ID || event.Order.Rate != 0.012 || !strings.Contains(event.Order.Reason, "take profit") {
+		t.Fatalf("event %+v", event)
+	}
+	// The take profit is a limit order, resting above the bids.
+	if o := order(t, p, event.Order.OrderUuid); o.Type != "LIMIT_SELL" || o.Limit != 0.012 || !o.IsOpen {
+		t.Fatalf("order %+v", o)
+	}
+
+	loss, err := e.Add(context.Background(), oco)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(t, e, 0.0079)
+	if event = nextEvent(t, e); event.Order.ID != loss.ID || event.Order.Rate != 0 || !strings.Contains(event.Order.Reason, "stop loss") {
+		t.Fatalf("event %+v", event)
+	}
+	if o := order(t, p, event.Order.OrderUuid); o.Type != "MARKET_SELL" || o.QuantityRemaining != 0 {
+		t.Fatalf("order %+v", o)
+	}
+}
+
+func TestEngineBracket(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("BTC", 1), bittrex.WithPaperFee(0))
+	e := engine(t, p, bittrex.WithEnginePollInterval(time.Hour))
+	// The entry buys at up to 0.0105: it fills at once on the 0.01 asks.
+	bracket, err := e.Add(context.Background(), bittrex.VirtualOrder{Type: bittrex.Bracket, Market: "BTC-LTC", Side: "SELL", Quantity: 5,
+		EntryRate: 0.0105, TakeProfit: 0.012, StopLoss: 0.009})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bracket.Status != bittrex.VirtualPending || bracket.EntryUuid == "" {
+		t.Fatalf("added %+v", bracket)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tickers := make(chan *bittrex.V3Ticker)
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx, tickers) }()
+
+	event := nextEvent(t, e)
+	if event.Order.ID != bracket.ID || event.Order.Status != bittrex.VirtualActive || event.Order.Quantity != 5 {
+		t.Fatalf("event %+v", event)
+	}
+	// Prices come from the stream, named the v3 way.
+	tickers <- &bittrex.V3Ticker{Symbol: "LTC-BTC", LastTradeRate: 0.0125}
+	if event = nextEvent(t, e); event.Order.Status != bittrex.VirtualTriggered || event.Order.Rate != 0.012 {
+		t.Fatalf("event %+v", event)
+	}
+	if o := order(t, p, event.Order.OrderUuid); o.Type != "LIMIT_SELL" || o.Quantity != 5 {
+		t.Fatalf("order %+v", o)
+	}
+
+	cancel()
+	if err = <-done; err != context.Canceled {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, ok := <-e.Events(); ok {
+		t.Fatal("events not closed")
+	}
+}
+
+func TestEngineReloadsState(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10))
+	path := filepath.Join(t.TempDir(), "engine.json")
+	e := engine(t, p, bittrex.WithEngineStateFile(path))
+	stop, err := e.Add(context.Background(), bittrex.VirtualOrder{Type: bittrex.TrailingStop, Market: "BTC-LTC", Side: "SELL", Quantity: 5, TrailPercent: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(t, e, 0.012)
+
+	e = engine(t, p, bittrex.WithEngineStateFile(path))
+	orders := e.Orders()
+	if len(orders) != 1 || orders[0].ID != stop.ID || orders[0].Best != 0.012 || orders[0].Stop != 0.0108 || orders[0].Status != bittrex.VirtualActive {
+		t.Fatalf("reloaded %+v", orders)
+	}
+	update(t, e, 0.0107)
+	if event := nextEvent(t, e); event.Order.ID != stop.ID || event.Order.Status != bittrex.VirtualTriggered {
+		t.Fatalf("event %+v", event)
+	}
+	if e = engine(t, p, bittrex.WithEngineStateFile(path)); len(e.Orders()) != 0 {
+		t.Fatalf("reloaded %+v after the trigger", e.Orders())
+	}
+}
+
+func TestEngineSettlesTriggeringOrders(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10))
+	// An engine stopped while placing two stops: the order of the first went through.
+	placed, err := p.SellMarket("BTC-LTC", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	state, _ := json.Marshal([]bittrex.VirtualOrder{
+		{ID: "v1", Type: bittrex.TrailingStop, Market: "BTC-LTC", Side: "SELL", Quantity: 5, TrailPercent: 0.1,
+			Status: bittrex.VirtualTriggering, Best: 0.012, Stop: 0.0108, Reason: "crossed", Created: now, Updated: now},
+		{ID: "v2", Type: bittrex.TrailingStop, Market: "BTC-LTC", Side: "SELL", Quantity: 3, TrailPercent: 0.1,
+			Status: bittrex.VirtualTriggering, Best: 0.012, Stop: 0.0108, Reason: "crossed", Created: now, Updated: now},
+	})
+	path := filepath.Join(t.TempDir(), "engine.json")
+	if err = os.WriteFile(path, state, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := engine(t, p, bittrex.WithEngineStateFile(path), bittrex.WithEnginePollInterval(time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tickers := make(chan *bittrex.V3Ticker)
+	go e.Run(ctx, tickers)
+
+	event := nextEvent(t, e)
+	if event.Order.ID != "v1" || event.Order.Status != bittrex.VirtualTriggered || event.Order.OrderUuid != placed {
+		t.Fatalf("event %+v", event)
+	}
+	// v2 was never placed: active again, it fires on the next price crossing its stop.
+	tickers <- &bittrex.V3Ticker{Symbol: "LTC-BTC", LastTradeRate: 0.0105}
+	if event = nextEvent(t, e); event.Order.ID != "v2" || event.Order.Status != bittrex.VirtualTriggered {
+		t.Fatalf("event %+v", event)
+	}
+	history, err := p.GetOrderHistory("BTC-LTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Quantity+history[1].Quantity != 8 {
+		t.Fatalf("history %+v, want the orders of v1 and v2 once each", history)
+	}
+}
+
+// lostAnswer is an exchange whose first market sell goes through, but times out.
+type lostAnswer struct {
+	bittrex.Exchange
+	lost bool
+}
+
+func (l *lostAnswer) SellMarketCtx(ctx context.Context, market string, quantity float64) (string, error) {
+	uuid, err := l.Exchange.SellMarketCtx(ctx, market, quantity)
+	if err == nil && !l.lost {
+		l.lost = true
+		return "", context.DeadlineExceeded
+	}
+	return uuid, err
+}
+
+func TestEngineSettlesLostPlacements(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10))
+	e := engine(t, &lostAnswer{Exchange: p}, bittrex.WithEnginePollInterval(time.Hour))
+	stop, err := e.Add(context.Background(), bittrex.VirtualOrder{Type: bittrex.TrailingStop, Market: "BTC-LTC", Side: "SELL", Quantity: 5, TrailPercent: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(t, e, 0.012)
+	update(t, e, 0.0107)
+	if err := <-e.Errors(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("reported %v", err)
+	}
+	// The order may have been placed: it does not fire again.
+	update(t, e, 0.0105)
+	if o, _ := e.Order(stop.ID); o.Status != bittrex.VirtualTriggering {
+		t.Fatalf("order %+v after the timeout", o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx, make(chan *bittrex.V3Ticker))
+	event := nextEvent(t, e)
+	if event.Order.ID != stop.ID || event.Order.Status != bittrex.VirtualTriggered || event.Order.OrderUuid == "" {
+		t.Fatalf("event %+v", event)
+	}
+	history, err := p.GetOrderHistory("BTC-LTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OrderUuid != event.Order.OrderUuid {
+		t.Fatalf("history %+v, want a single order", history)
+	}
+}
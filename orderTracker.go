@@ -0,0 +1,389 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OrderEventType is the kind of an OrderEvent.
+type OrderEventType int
+
+const (
+	OrderOpened          OrderEventType = iota + 1 // first sight of the order
+	OrderPartiallyFilled                           // some quantity was filled, the order is still open
+	OrderFilled                                    // the order is closed and fully filled
+	OrderCanceled                                  // the order is closed before being fully filled
+)
+
+func (t OrderEventType) String() string {
+	switch t {
+	case OrderOpened:
+		return "Opened"
+	case OrderPartiallyFilled:
+		return "PartiallyFilled"
+	case OrderFilled:
+		return "Filled"
+	case OrderCanceled:
+		return "Canceled"
+	}
+	return fmt.Sprintf("OrderEventType(%d)", int(t))
+}
+
+// OrderEvent is a change in the lifecycle of a tracked order.
+type OrderEvent struct {
+	Type      OrderEventType
+	OrderUuid string
+	Delta     float64 // quantity filled since the previous event of the order
+	Order     *Order  // state of the order when the change was seen
+	Time      time.Time
+}
+
+// OrderGetter is the part of Trading the OrderTracker polls.
+type OrderGetter interface {
+	GetOrderCtx(ctx context.Context, order_uuid string) (*Order, error)
+}
+
+// TrackerOption configures an OrderTracker.
+type TrackerOption func(*OrderTracker)
+
+// WithTrackerPollInterval sets the bounds of the polling interval of an order.
+// An order is polled every min after a change, then less and less often, up to
+// every max, while it does not change. Defaults to 1s and 1m.
+func WithTrackerPollInterval(min, max time.Duration) TrackerOption {
+	return func(t *OrderTracker) {
+		t.minInterval = min
+		t.maxInterval = max
+	}
+}
+
+// WithTrackerStateFile makes the tracker persist its watch list, and the last
+// known state of each order, to path. The list is loaded back by
+// NewOrderTracker, so that tracking survives a restart without repeating events.
+func WithTrackerStateFile(path string) TrackerOption {
+	return func(t *OrderTracker) {
+		t.path = path
+	}
+}
+
+// WithTrackerBuffer sets the capacity of the event and error channels. Defaults to 256.
+func WithTrackerBuffer(n int) TrackerOption {
+	return func(t *OrderTracker) {
+		t.bufSize = n
+	}
+}
+
+// OrderTracker watches a set of orders and emits an OrderEvent on each change
+// of their lifecycle. An order is tracked until it is filled or canceled.
+//
+// Orders are polled with GetOrder, adaptively: often while they change, rarely
+// while they do not. When a stream of order events is given to Run, the
+// streamed changes are applied as they come and polling is only a fallback.
+//
+// Events must be received, or the tracker blocks.
+type OrderTracker struct {
+	getter      OrderGetter
+	minInterval time.Duration
+	maxInterval time.Duration
+	path        string
+	bufSize     int
+
+	events chan *OrderEvent
+	errs   chan error
+	wakeup chan struct{}
+
+	mu     sync.Mutex
+	orders map[string]*trackedOrder
+}
+
+// trackedOrder is the last known state of a watched order, as persisted.
+type trackedOrder struct {
+	OrderUuid         string        `json:"orderUuid"`
+	Seen              bool          `json:"seen"`
+	Quantity          float64       `json:"quantity"`
+	QuantityRemaining float64       `json:"quantityRemaining"`
+	Interval          time.Duration `json:"-"`
+	Next              time.Time     `json:"-"`
+}
+
+// NewOrderTracker returns a tracker polling orders through getter, typically a
+// *Bittrex or a *PaperBittrex. With WithTrackerStateFile, it resumes the watch
+// list saved by a previous tracker, if any.
+func NewOrderTracker(getter OrderGetter, opts ...TrackerOption) (*OrderTracker, error) {
+	t := &OrderTracker{
+		getter:      getter,
+		minInterval: time.Second,
+		maxInterval: time.Minute,
+		bufSize:     256,
+		wakeup:      make(chan struct{}, 1),
+		orders:      map[string]*trackedOrder{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.events = make(chan *OrderEvent, t.bufSize)
+	t.errs = make(chan error, t.bufSize)
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Events returns the channel of order events. It is closed when Run returns.
+func (t *OrderTracker) Events() <-chan *OrderEvent { return t.events }
+
+// Errors returns the channel of non fatal errors: failed polls and saves.
+// Errors are dropped if it is full.
+func (t *OrderTracker) Errors() <-chan error { return t.errs }
+
+// Watch adds orders to the watch list. They are polled right away.
+func (t *OrderTracker) Watch(orderUuids ...string) error {
+	t.mu.Lock()
+	for _, uuid := range orderUuids {
+		if _, ok := t.orders[uuid]; !ok {
+			t.orders[uuid] = &trackedOrder{OrderUuid: uuid, Interval: t.minInterval}
+		}
+	}
+	err := t.saveLocked()
+	t.mu.Unlock()
+	t.wake()
+	return err
+}
+
+// Unwatch removes orders from the watch list, without any event.
+func (t *OrderTracker) Unwatch(orderUuids ...string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, uuid := range orderUuids {
+		delete(t.orders, uuid)
+	}
+	return t.saveLocked()
+}
+
+// Watched returns the uuids of the orders being tracked.
+func (t *OrderTracker) Watched() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	uuids := make([]string, 0, len(t.orders))
+	for uuid := range t.orders {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
+// Run polls the watched orders, and applies the events received on stream,
+// until ctx is done. stream may be nil, see Stream.Orders. The event channel is
+// closed when Run returns, so Run may be called only once.
+func (t *OrderTracker) Run(ctx context.Context, stream <-chan *StreamOrder) error {
+	defer close(t.events)
+	for {
+		due, wait := t.due()
+		for _, uuid := range due {
+			order, err := t.getter.GetOrderCtx(ctx, uuid)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				t.report(fmt.Errorf("bittrex: tracker: %s: %w", uuid, err))
+				t.backoff(uuid)
+				continue
+			}
+			if err = t.update(ctx, order, false); err != nil {
+				return err
+			}
+		}
+		if len(due) > 0 {
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-t.wakeup:
+		case event, ok := <-stream:
+			if !ok {
+				stream = nil
+				break
+			}
+			if err := t.update(ctx, v3ToOrder(&event.Delta), true); err != nil {
+				timer.Stop()
+				return err
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// due returns the orders to poll now, or how long to wait for the next one.
+func (t *OrderTracker) due() (uuids []string, wait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	wait = t.maxInterval
+	for uuid, o := range t.orders {
+		if d := o.Next.Sub(now); d <= 0 {
+			uuids = append(uuids, uuid)
+		} else if d < wait {
+			wait = d
+		}
+	}
+	sort.Strings(uuids)
+	return uuids, wait
+}
+
+// backoff delays the next poll of an order which did not change.
+func (t *OrderTracker) backoff(uuid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if o, ok := t.orders[uuid]; ok {
+		t.backoffLocked(o)
+	}
+}
+
+func (t *OrderTracker) backoffLocked(o *trackedOrder) {
+	if o.Interval *= 2; o.Interval > t.maxInterval {
+		o.Interval = t.maxInterval
+	}
+	if o.Interval < t.minInterval {
+		o.Interval = t.minInterval
+	}
+	o.Next = time.Now().Add(o.Interval)
+}
+
+// update compares order to its last known state and emits the resulting
+// events. Streamed updates leave the order polled at the longest interval.
+func (t *OrderTracker) update(ctx context.Context, order *Order, streamed bool) error {
+	t.mu.Lock()
+	o, ok := t.orders[order.OrderUuid]
+	if !ok {
+		t.mu.Unlock()
+		return nil
+	}
+	events := orderEvents(o, order, time.Now())
+	if len(events) > 0 {
+		o.Seen = true
+		o.Quantity = order.Quantity
+		o.QuantityRemaining = order.QuantityRemaining
+		o.Interval = t.minInterval
+		if streamed {
+			o.Interval = t.maxInterval
+		}
+		o.Next = time.Now().Add(o.Interval)
+		if last := events[len(events)-1].Type; last == OrderFilled || last == OrderCanceled {
+			delete(t.orders, o.OrderUuid)
+		}
+		if err := t.saveLocked(); err != nil {
+			t.report(err)
+		}
+	} else if !streamed {
+		t.backoffLocked(o)
+	}
+	t.mu.Unlock()
+
+	for _, event := range events {
+		select {
+		case t.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// orderEvents returns the events leading from the known state o to order.
+func orderEvents(o *trackedOrder, order *Order, now time.Time) []*OrderEvent {
+	var events []*OrderEvent
+	event := func(typ OrderEventType, delta float64) {
+		events = append(events, &OrderEvent{Type: typ, OrderUuid: order.OrderUuid, Delta: delta, Order: order, Time: now})
+	}
+	if !o.Seen {
+		event(OrderOpened, 0)
+	}
+	filled := order.Quantity - order.QuantityRemaining
+	delta := round8(filled - (o.Quantity - o.QuantityRemaining))
+	if !o.Seen {
+		delta = round8(filled)
+	}
+	if delta < 0 {
+		delta = 0 // stale update
+	}
+	// Only a closed order is final: one being canceled may still fill.
+	switch {
+	case !order.IsOpen && order.QuantityRemaining <= 0:
+		event(OrderFilled, delta)
+	case !order.IsOpen:
+		event(OrderCanceled, delta)
+	case delta > 0:
+		event(OrderPartiallyFilled, delta)
+	}
+	return events
+}
+
+func (t *OrderTracker) wake() {
+	select {
+	case t.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (t *OrderTracker) report(err error) {
+	select {
+	case t.errs <- err:
+	default:
+	}
+}
+
+// load reads the watch list saved at t.path, if any.
+func (t *OrderTracker) load() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var orders []*trackedOrder
+	if err = json.Unmarshal(data, &orders); err != nil {
+		return fmt.Errorf("bittrex: tracker: %s: %w", t.path, err)
+	}
+	for _, o := range orders {
+		o.Interval = t.minInterval
+		t.orders[o.OrderUuid] = o
+	}
+	return nil
+}
+
+// saveLocked writes the watch list to t.path, through a temporary file so that
+// a crash never leaves a truncated list.
+func (t *OrderTracker) saveLocked() error {
+	if t.path == "" {
+		return nil
+	}
+	orders := make([]*trackedOrder, 0, len(t.orders))
+	for _, o := range t.orders {
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].OrderUuid < orders[j].OrderUuid })
+	data, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
@@ -0,0 +1,48 @@
+package bittrex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOrderEvents(t *testing.T) {
+	seen := func(quantity, remaining float64) *trackedOrder {
+		return &trackedOrder{OrderUuid: "o1", Seen: true, Quantity: quantity, QuantityRemaining: remaining}
+	}
+	for _, tt := range []struct {
+		name  string
+		known *trackedOrder
+		order Order
+		want  []OrderEventType
+		delta float64 // of the last event
+	}{
+		{"opened", &trackedOrder{OrderUuid: "o1"}, Order{Quantity: 10, QuantityRemaining: 10, IsOpen: true}, []OrderEventType{OrderOpened}, 0},
+		{"opened partially filled", &trackedOrder{OrderUuid: "o1"}, Order{Quantity: 10, QuantityRemaining: 6, IsOpen: true}, []OrderEventType{OrderOpened, OrderPartiallyFilled}, 4},
+		{"opened filled", &trackedOrder{OrderUuid: "o1"}, Order{Quantity: 10, IsOpen: false}, []OrderEventType{OrderOpened, OrderFilled}, 10},
+		{"unchanged", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 6, IsOpen: true}, nil, 0},
+		{"partially filled", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 1, IsOpen: true}, []OrderEventType{OrderPartiallyFilled}, 5},
+		{"filled", seen(10, 6), Order{Quantity: 10, IsOpen: false}, []OrderEventType{OrderFilled}, 6},
+		{"canceled", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 6, IsOpen: false, CancelInitiated: true}, []OrderEventType{OrderCanceled}, 0},
+		{"canceled after a fill", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 2, IsOpen: false}, []OrderEventType{OrderCanceled}, 4},
+		// A cancel in flight is not final: the order may still fill.
+		{"cancel initiated", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 6, IsOpen: true, CancelInitiated: true}, nil, 0},
+		{"cancel initiated, filling", seen(10, 6), Order{Quantity: 10, QuantityRemaining: 3, IsOpen: true, CancelInitiated: true}, []OrderEventType{OrderPartiallyFilled}, 3},
+		{"filled while canceling", seen(10, 6), Order{Quantity: 10, IsOpen: false, CancelInitiated: true}, []OrderEventType{OrderFilled}, 6},
+		{"stale", seen(10, 2), Order{Quantity: 10, QuantityRemaining: 6, IsOpen: true}, nil, 0},
+	} {
+		tt.order.OrderUuid = "o1"
+		events := orderEvents(tt.known, &tt.order, time.Now())
+		var got []OrderEventType
+		for _, e := range events {
+			got = append(got, e.Type)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		if len(events) > 0 && events[len(events)-1].Delta != tt.delta {
+			t.Errorf("%s: delta %v, want %v", tt.name, events[len(events)-1].Delta, tt.delta)
+		}
+	}
+}
@@ -0,0 +1,622 @@
+package bittrex
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPaperFee is the commission rate charged by PaperBittrex, the Bittrex one.
+const DefaultPaperFee = 0.0025
+
+// PaperBittrex is a paper trading exchange: market data calls go to a real
+// or fake source while orders and balances are simulated.
+//
+// Orders fill against the order book of the source. Marketable orders fill on
+// placement, walking the book, and the remaining quantity of limit orders rests
+// until a later Match crosses it. Market orders fill what the book offers and
+// close. Each fill is charged the fee rate on its total, in the base currency.
+//
+// A Match sees the book as the source returns it: liquidity taken by a
+// previous Match is not remembered, and resting orders do not queue behind
+// the orders already at their rate.
+type PaperBittrex struct {
+	MarketData
+
+	fee       float64
+	depth     int
+	autoMatch bool
+	now       func() time.Time
+
+	mu       sync.Mutex
+	balances map[string]float64 // currency -> total
+	reserved map[string]float64 // currency -> held by open limit orders
+	orders   map[string]*paperOrder
+	sequence []*paperOrder // in placement order
+
+	deposits    []*Deposit
+	withdrawals []*Withdrawal
+}
+
+// PaperOption configures a PaperBittrex.
+type PaperOption func(*PaperBittrex)
+
+// WithPaperBalance credits amount of currency to the simulated account.
+func WithPaperBalance(currency string, amount float64) PaperOption {
+	return func(p *PaperBittrex) {
+		p.balances[strings.ToUpper(currency)] += amount
+	}
+}
+
+// WithPaperFee sets the commission rate, ex: 0.0025 for 0.25%.
+func WithPaperFee(fee float64) PaperOption {
+	return func(p *PaperBittrex) {
+		p.fee = fee
+	}
+}
+
+// WithPaperDepth sets the depth of the order books fetched to match orders, 50 by default.
+func WithPaperDepth(depth int) PaperOption {
+	return func(p *PaperBittrex) {
+		p.depth = depth
+	}
+}
+
+// WithPaperAutoMatch sets whether GetOpenOrders, GetOrderHistory, GetOrder,
+// GetBalances and GetBalance match the open orders before answering, true by default.
+// Without it, call Match to make the resting orders progress.
+func WithPaperAutoMatch(autoMatch bool) PaperOption {
+	return func(p *PaperBittrex) {
+		p.autoMatch = autoMatch
+	}
+}
+
+// WithPaperClock sets the clock used to timestamp the orders, time.Now by default.
+func WithPaperClock(now func() time.Time) PaperOption {
+	return func(p *PaperBittrex) {
+		p.now = now
+	}
+}
+
+// NewPaperBittrex returns a paper exchange taking its market data from source,
+// ex: NewPaperBittrex(bittrex.New("", ""), WithPaperBalance("BTC", 1)).
+func NewPaperBittrex(source MarketData, opts ...PaperOption) *PaperBittrex {
+	p := &PaperBittrex{
+		MarketData: source,
+		fee:        DefaultPaperFee,
+		depth:      50,
+		autoMatch:  true,
+		now:        time.Now,
+		balances:   make(map[string]float64),
+		reserved:   make(map[string]float64),
+		orders:     make(map[string]*paperOrder),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// paperOrder is a simulated order.
+type paperOrder struct {
+	Order
+	market       string
+	base         string // currency paid, or received, ex: BTC for BTC-LTC
+	currency     string // currency bought, or sold, ex: LTC for BTC-LTC
+	buy          bool
+	isMarket     bool
+	opened       time.Time
+	reserveAvail float64 // reservation not consumed by the fills yet
+}
+
+// Deposit credits amount of currency to the simulated account, as a
+// deposit listed by GetDepositHistory.
+func (p *PaperBittrex) Deposit(currency string, amount float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	currency = strings.ToUpper(currency)
+	p.balances[currency] += amount
+	p.deposits = append(p.deposits, &Deposit{
+		Id:            int64(len(p.deposits) + 1),
+		Amount:        amount,
+		Currency:      currency,
+		LastUpdated:   p.now().UTC(),
+		TxId:          newUuid(),
+		CryptoAddress: paperAddress(currency),
+	})
+}
+
+// Market
+
+// BuyLimit places a simulated limited buy order.
+func (p *PaperBittrex) BuyLimit(market string, quantity, rate float64) (uuid string, err error) {
+	return p.BuyLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// BuyLimitCtx is like BuyLimit but carries ctx to the market data source.
+func (p *PaperBittrex) BuyLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	return p.place(ctx, "market/buylimit", market, true, false, quantity, rate)
+}
+
+// BuyMarket places a simulated market buy order.
+func (p *PaperBittrex) BuyMarket(market string, quantity float64) (uuid string, err error) {
+	return p.BuyMarketCtx(context.Background(), market, quantity)
+}
+
+// BuyMarketCtx is like BuyMarket but carries ctx to the market data source.
+func (p *PaperBittrex) BuyMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	return p.place(ctx, "market/buymarket", market, true, true, quantity, 0)
+}
+
+// SellLimit places a simulated limited sell order.
+func (p *PaperBittrex) SellLimit(market string, quantity, rate float64) (uuid string, err error) {
+	return p.SellLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// SellLimitCtx is like SellLimit but carries ctx to the market data source.
+func (p *PaperBittrex) SellLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	return p.place(ctx, "market/selllimit", market, false, false, quantity, rate)
+}
+
+// SellMarket places a simulated market sell order.
+func (p *PaperBittrex) SellMarket(market string, quantity float64) (uuid string, err error) {
+	return p.SellMarketCtx(context.Background(), market, quantity)
+}
+
+// SellMarketCtx is like SellMarket but carries ctx to the market data source.
+func (p *PaperBittrex) SellMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	return p.place(ctx, "market/sellmarket", market, false, true, quantity, 0)
+}
+
+// CancelOrder cancels a simulated order and releases its reservation.
+func (p *PaperBittrex) CancelOrder(orderID string) (err error) {
+	return p.CancelOrderCtx(context.Background(), orderID)
+}
+
+// CancelOrderCtx is like CancelOrder. ctx is unused, it is there to match Bittrex.
+func (p *PaperBittrex) CancelOrderCtx(ctx context.Context, orderID string) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.orders[orderID]
+	if !ok {
+		return &APIError{Endpoint: "market/cancel", Message: "UUID_INVALID"}
+	}
+	if !o.IsOpen {
+		return &APIError{Endpoint: "market/cancel", Message: ErrOrderNotOpen.Message}
+	}
+	o.CancelInitiated = true
+	p.closeLocked(o)
+	return nil
+}
+
+// GetOpenOrders returns the simulated orders still open, of market or of "all" markets.
+func (p *PaperBittrex) GetOpenOrders(market string) ([]*OrderHistory, error) {
+	return p.GetOpenOrdersCtx(context.Background(), market)
+}
+
+// GetOpenOrdersCtx is like GetOpenOrders but carries ctx to the market data source.
+func (p *PaperBittrex) GetOpenOrdersCtx(ctx context.Context, market string) ([]*OrderHistory, error) {
+	if err := p.maybeMatch(ctx); err != nil {
+		return nil, err
+	}
+	return p.history(market, true), nil
+}
+
+// Account
+
+// GetBalances returns the simulated balances.
+func (p *PaperBittrex) GetBalances() ([]*Balance, error) {
+	return p.GetBalancesCtx(context.Background())
+}
+
+// GetBalancesCtx is like GetBalances but carries ctx to the market data source.
+func (p *PaperBittrex) GetBalancesCtx(ctx context.Context) ([]*Balance, error) {
+	if err := p.maybeMatch(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	currencies := make([]string, 0, len(p.balances))
+	for currency := range p.balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	balances := make([]*Balance, len(currencies))
+	for i, currency := range currencies {
+		balances[i] = p.balanceLocked(currency)
+	}
+	return balances, nil
+}
+
+// GetBalance returns the simulated balance of currency.
+func (p *PaperBittrex) GetBalance(currency string) (*Balance, error) {
+	return p.GetBalanceCtx(context.Background(), currency)
+}
+
+// GetBalanceCtx is like GetBalance but carries ctx to the market data source.
+func (p *PaperBittrex) GetBalanceCtx(ctx context.Context, currency string) (*Balance, error) {
+	if err := p.maybeMatch(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.balanceLocked(strings.ToUpper(currency)), nil
+}
+
+// GetDepositAddress returns the simulated deposit address of currency.
+func (p *PaperBittrex) GetDepositAddress(currency string) (*Address, error) {
+	return p.GetDepositAddressCtx(context.Background(), currency)
+}
+
+// GetDepositAddressCtx is like GetDepositAddress. ctx is unused, it is there to match Bittrex.
+func (p *PaperBittrex) GetDepositAddressCtx(ctx context.Context, currency string) (*Address, error) {
+	currency = strings.ToUpper(currency)
+	return &Address{Currency: currency, Address: paperAddress(currency)}, nil
+}
+
+// Withdraw debits quantity of currency from the simulated account.
+func (p *PaperBittrex) Withdraw(address, currency string, quantity float64) (withdrawUuid string, err error) {
+	return p.WithdrawCtx(context.Background(), address, currency, quantity)
+}
+
+// WithdrawCtx is like Withdraw. ctx is unused, it is there to match Bittrex.
+func (p *PaperBittrex) WithdrawCtx(ctx context.Context, address, currency string, quantity float64) (withdrawUuid string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	currency = strings.ToUpper(currency)
+	if quantity <= 0 {
+		return "", &APIError{Endpoint: "account/withdraw", Message: "QUANTITY_NOT_PROVIDED"}
+	}
+	if p.availableLocked(currency) < quantity {
+		return "", &APIError{Endpoint: "account/withdraw", Message: ErrInsufficientFunds.Message}
+	}
+	p.balances[currency] -= quantity
+	w := &Withdrawal{
+		PaymentUuid: newUuid(),
+		Currency:    currency,
+		Amount:      quantity,
+		Address:     address,
+		Opened:      p.now().UTC(),
+		Authorized:  true,
+		TxId:        newUuid(),
+	}
+	p.withdrawals = append(p.withdrawals, w)
+	return w.PaymentUuid, nil
+}
+
+// GetWithdrawalHistory returns the simulated withdrawals of currency, or of "all" currencies.
+func (p *PaperBittrex) GetWithdrawalHistory(currency string) ([]*Withdrawal, error) {
+	return p.GetWithdrawalHistoryCtx(context.Background(), currency)
+}
+
+// GetWithdrawalHistoryCtx is like GetWithdrawalHistory. ctx is unused, it is there to match Bittrex.
+func (p *PaperBittrex) GetWithdrawalHistoryCtx(ctx context.Context, currency string) ([]*Withdrawal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	withdrawals := []*Withdrawal{}
+	for _, w := range p.withdrawals {
+		if strings.EqualFold(currency, "all") || strings.EqualFold(currency, w.Currency) {
+			withdrawal := *w
+			withdrawals = append(withdrawals, &withdrawal)
+		}
+	}
+	return withdrawals, nil
+}
+
+// GetDepositHistory returns the deposits made with Deposit, of currency or of "all" currencies.
+func (p *PaperBittrex) GetDepositHistory(currency string) ([]*Deposit, error) {
+	return p.GetDepositHistoryCtx(context.Background(), currency)
+}
+
+// GetDepositHistoryCtx is like GetDepositHistory. ctx is unused, it is there to match Bittrex.
+func (p *PaperBittrex) GetDepositHistoryCtx(ctx context.Context, currency string) ([]*Deposit, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	deposits := []*Deposit{}
+	for _, d := range p.deposits {
+		if strings.EqualFold(currency, "all") || strings.EqualFold(currency, d.Currency) {
+			deposit := *d
+			deposits = append(deposits, &deposit)
+		}
+	}
+	return deposits, nil
+}
+
+// GetOrderHistory returns the closed simulated orders, of market or of "all" markets.
+func (p *PaperBittrex) GetOrderHistory(market string) ([]*OrderHistory, error) {
+	return p.GetOrderHistoryCtx(context.Background(), market)
+}
+
+// GetOrderHistoryCtx is like GetOrderHistory but carries ctx to the market data source.
+func (p *PaperBittrex) GetOrderHistoryCtx(ctx context.Context, market string) ([]*OrderHistory, error) {
+	if err := p.maybeMatch(ctx); err != nil {
+		return nil, err
+	}
+	return p.history(market, false), nil
+}
+
+// GetOrder returns a simulated order by uuid.
+func (p *PaperBittrex) GetOrder(order_uuid string) (*Order, error) {
+	return p.GetOrderCtx(context.Background(), order_uuid)
+}
+
+// GetOrderCtx is like GetOrder but carries ctx to the market data source.
+func (p *PaperBittrex) GetOrderCtx(ctx context.Context, order_uuid string) (*Order, error) {
+	if err := p.maybeMatch(ctx); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	o, ok := p.orders[order_uuid]
+	if !ok {
+		return nil, &APIError{Endpoint: "account/getorder", Message: "UUID_INVALID"}
+	}
+	order := o.Order
+	return &order, nil
+}
+
+// Match fills the open limit orders crossed by the current order books.
+func (p *PaperBittrex) Match(ctx context.Context) error {
+	p.mu.Lock()
+	markets := make(map[string]bool)
+	for _, o := range p.sequence {
+		if o.IsOpen {
+			markets[o.market] = true
+		}
+	}
+	p.mu.Unlock()
+
+	for market := range markets {
+		book, err := p.MarketData.GetOrderBookCtx(ctx, market, "both", p.depth)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		asks, bids := copyOrderbs(book.Sell), copyOrderbs(book.Buy)
+		for _, o := range p.sequence {
+			if !o.IsOpen || o.market != market {
+				continue
+			}
+			if o.buy {
+				p.fillLocked(o, asks)
+			} else {
+				p.fillLocked(o, bids)
+			}
+		}
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+func (p *PaperBittrex) maybeMatch(ctx context.Context) error {
+	if !p.autoMatch {
+		return nil
+	}
+	return p.Match(ctx)
+}
+
+// place checks and reserves the funds of a new order, then fills it against the book.
+func (p *PaperBittrex) place(ctx context.Context, endpoint, market string, buy, isMarket bool, quantity, rate float64) (string, error) {
+	market = strings.ToUpper(market)
+	parts := strings.Split(market, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", &APIError{Endpoint: endpoint, Message: ErrInvalidMarket.Message}
+	}
+	if quantity <= 0 {
+		return "", &APIError{Endpoint: endpoint, Message: "QUANTITY_NOT_PROVIDED"}
+	}
+	if !isMarket && rate <= 0 {
+		return "", &APIError{Endpoint: endpoint, Message: "RATE_NOT_PROVIDED"}
+	}
+
+	cat := "sell"
+	if !buy {
+		cat = "buy"
+	}
+	book, err := p.MarketData.GetOrderBookCtx(ctx, market, cat, p.depth)
+	if err != nil {
+		return "", err
+	}
+	levels := book.Sell
+	if !buy {
+		levels = book.Buy
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	o := &paperOrder{
+		Order: Order{
+			OrderUuid:         newUuid(),
+			Exchange:          market,
+			Type:              orderType(buy, isMarket),
+			Quantity:          quantity,
+			QuantityRemaining: quantity,
+			Limit:             rate,
+			IsOpen:            true,
+		},
+		market:   market,
+		base:     parts[0],
+		currency: parts[1],
+		buy:      buy,
+		isMarket: isMarket,
+		opened:   p.now().UTC(),
+	}
+	o.Opened = o.opened.Format(TIME_FORMAT)
+
+	// Funds needed: the whole order at its limit, or what the book can fill of a market order.
+	held, need := o.currency, quantity
+	if buy {
+		held = o.base
+		if isMarket {
+			need = 0
+			remaining := quantity
+			for _, level := range levels {
+				dq := math.Min(remaining, level.Quantity)
+				need += dq * level.Rate * (1 + p.fee)
+				if remaining -= dq; remaining <= 0 {
+					break
+				}
+			}
+		} else {
+			need = quantity * rate * (1 + p.fee)
+			o.Reserved = round8(quantity * rate)
+			o.CommissionReserved = round8(quantity * rate * p.fee)
+		}
+	} else if !isMarket {
+		o.Reserved = quantity
+	}
+	if p.availableLocked(held) < need-1e-12 {
+		return "", &APIError{Endpoint: endpoint, Message: ErrInsufficientFunds.Message}
+	}
+	if !isMarket {
+		p.reserved[held] += need
+		o.reserveAvail = need
+	}
+
+	p.orders[o.OrderUuid] = o
+	p.sequence = append(p.sequence, o)
+	p.fillLocked(o, copyOrderbs(levels))
+	if isMarket && o.IsOpen {
+		// What the book could not fill is canceled, like an immediate or cancel order.
+		p.closeLocked(o)
+	}
+	return o.OrderUuid, nil
+}
+
+// fillLocked fills o against levels, best rate first, and removes the
+// quantity taken from levels.
+func (p *PaperBittrex) fillLocked(o *paperOrder, levels []Orderb) {
+	for i := range levels {
+		if !o.IsOpen {
+			return
+		}
+		level := &levels[i]
+		if level.Quantity <= 0 {
+			continue
+		}
+		if !o.isMarket && (o.buy && level.Rate > o.Limit || !o.buy && level.Rate < o.Limit) {
+			return
+		}
+		dq := math.Min(o.QuantityRemaining, level.Quantity)
+		level.Quantity -= dq
+		p.tradeLocked(o, dq, level.Rate)
+	}
+}
+
+// tradeLocked books the fill of quantity of o at rate.
+func (p *PaperBittrex) tradeLocked(o *paperOrder, quantity, rate float64) {
+	total := quantity * rate
+	commission := total * p.fee
+	if o.buy {
+		p.balances[o.base] -= total + commission
+		p.balances[o.currency] += quantity
+		// The reservation was made at the limit: a fill at a better rate frees the difference too.
+		p.release(o, quantity*o.Limit*(1+p.fee))
+	} else {
+		p.balances[o.currency] -= quantity
+		p.balances[o.base] += total - commission
+		p.release(o, quantity)
+	}
+	o.QuantityRemaining = round8(o.QuantityRemaining - quantity)
+	o.Price = round8(o.Price + total)
+	o.CommissionPaid = round8(o.CommissionPaid + commission)
+	if filled := o.Quantity - o.QuantityRemaining; filled > 0 {
+		o.PricePerUnit = round8(o.Price / filled)
+	}
+	if o.QuantityRemaining <= 0 {
+		o.QuantityRemaining = 0
+		p.closeLocked(o)
+	}
+}
+
+// release gives back amount of the reservation of o, if it has one.
+func (p *PaperBittrex) release(o *paperOrder, amount float64) {
+	if o.isMarket {
+		return
+	}
+	amount = math.Min(amount, o.reserveAvail)
+	o.reserveAvail -= amount
+	held := o.currency
+	if o.buy {
+		held = o.base
+	}
+	p.reserved[held] -= amount
+	o.ReserveRemaining = round8(o.reserveAvail)
+	if o.buy {
+		o.ReserveRemaining = round8(o.reserveAvail / (1 + p.fee))
+		o.CommissionReserveRemaining = round8(o.reserveAvail - o.ReserveRemaining)
+	}
+}
+
+// closeLocked closes o and releases what is left of its reservation.
+func (p *PaperBittrex) closeLocked(o *paperOrder) {
+	p.release(o, o.reserveAvail)
+	o.IsOpen = false
+	o.Closed = p.now().UTC().Format(TIME_FORMAT)
+}
+
+func (p *PaperBittrex) availableLocked(currency string) float64 {
+	return p.balances[currency] - p.reserved[currency]
+}
+
+func (p *PaperBittrex) balanceLocked(currency string) *Balance {
+	return &Balance{
+		Currency:  currency,
+		Balance:   round8(p.balances[currency]),
+		Available: round8(p.availableLocked(currency)),
+	}
+}
+
+// history returns the open, or closed, orders of market, or of "all" markets.
+func (p *PaperBittrex) history(market string, open bool) []*OrderHistory {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	market = strings.ToUpper(market)
+	orders := []*OrderHistory{}
+	for _, o := range p.sequence {
+		if o.IsOpen != open || (market != "ALL" && o.market != market) {
+			continue
+		}
+		orders = append(orders, &OrderHistory{
+			OrderUuid:         o.OrderUuid,
+			Exchange:          o.Exchange,
+			TimeStamp:         o.opened,
+			OrderType:         o.Type,
+			Limit:             o.Limit,
+			Quantity:          o.Quantity,
+			QuantityRemaining: o.QuantityRemaining,
+			Commission:        o.CommissionPaid,
+			Price:             o.Price,
+			PricePerUnit:      o.PricePerUnit,
+		})
+	}
+	return orders
+}
+
+func orderType(buy, isMarket bool) string {
+	kind := "LIMIT"
+	if isMarket {
+		kind = "MARKET"
+	}
+	if buy {
+		return kind + "_BUY"
+	}
+	return kind + "_SELL"
+}
+
+func copyOrderbs(orderbs []Orderb) []Orderb {
+	return append([]Orderb(nil), orderbs...)
+}
+
+func round8(f float64) float64 {
+	return math.Round(f*1e8) / 1e8
+}
+
+// paperAddress returns the simulated deposit address of currency.
+func paperAddress(currency string) string {
+	return "paper-" + strings.ToLower(currency)
+}
@@ -0,0 +1,159 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+// paper returns a paper exchange backed by a fake server quoting book on BTC-LTC.
+func paper(t *testing.T, book *bittrex.OrderBook, opts ...bittrex.PaperOption) (*bittrex.PaperBittrex, *bittrextest.Server) {
+	t.Helper()
+	s := bittrextest.NewServer()
+	t.Cleanup(s.Close)
+	s.SetOrderBook("BTC-LTC", book)
+	return bittrex.NewPaperBittrex(s.Bittrex(), opts...), s
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-8
+}
+
+func balance(t *testing.T, p *bittrex.PaperBittrex, currency string) *bittrex.Balance {
+	t.Helper()
+	b, err := p.GetBalance(currency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func order(t *testing.T, p *bittrex.PaperBittrex, uuid string) *bittrex.Order {
+	t.Helper()
+	o, err := p.GetOrder(uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+var paperBook = &bittrex.OrderBook{
+	Buy:  []bittrex.Orderb{{Quantity: 4, Rate: 0.009}, {Quantity: 10, Rate: 0.008}},
+	Sell: []bittrex.Orderb{{Quantity: 5, Rate: 0.01}, {Quantity: 5, Rate: 0.011}},
+}
+
+func TestPaperLimitBuyWalksTheBookThenRests(t *testing.T) {
+	// Without auto match, as a Match would see the 0.01 asks again.
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("BTC", 1), bittrex.WithPaperAutoMatch(false))
+	uuid, err := p.BuyLimit("btc-ltc", 8, 0.0105)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := order(t, p, uuid)
+	// 5 fill at 0.01, 0.011 is above the limit.
+	if !o.IsOpen || o.QuantityRemaining != 3 || o.Price != 0.05 || o.PricePerUnit != 0.01 || !near(o.CommissionPaid, 0.05*bittrex.DefaultPaperFee) {
+		t.Fatalf("order %+v", o)
+	}
+	btc := balance(t, p, "BTC")
+	spent := 0.05 * (1 + bittrex.DefaultPaperFee)
+	held := 3 * 0.0105 * (1 + bittrex.DefaultPaperFee)
+	if !near(btc.Balance, 1-spent) || !near(btc.Available, 1-spent-held) {
+		t.Fatalf("BTC %+v", btc)
+	}
+	if ltc := balance(t, p, "LTC"); ltc.Balance != 5 {
+		t.Fatalf("LTC %+v", ltc)
+	}
+}
+
+func TestPaperRestingOrderFillsWhenTheBookCrosses(t *testing.T) {
+	p, s := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10), bittrex.WithPaperFee(0))
+	uuid, err := p.SellLimit("BTC-LTC", 6, 0.0095)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history, _ := p.GetOrderHistory("all"); len(history) != 0 {
+		t.Fatalf("history %+v before any fill", history)
+	}
+	s.SetOrderBook("BTC-LTC", &bittrex.OrderBook{Buy: []bittrex.Orderb{{Quantity: 2, Rate: 0.0097}, {Quantity: 10, Rate: 0.0096}}})
+
+	// GetOrderHistory matches before answering, like GetOrder does.
+	history, err := p.GetOrderHistory("BTC-LTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OrderUuid != uuid || history[0].QuantityRemaining != 0 || !near(history[0].Price, 2*0.0097+4*0.0096) {
+		t.Fatalf("history %+v", history)
+	}
+	if open, _ := p.GetOpenOrders("all"); len(open) != 0 {
+		t.Fatalf("open orders %+v", open)
+	}
+	if btc := balance(t, p, "BTC"); !near(btc.Balance, 2*0.0097+4*0.0096) {
+		t.Fatalf("BTC %+v", btc)
+	}
+	if ltc := balance(t, p, "LTC"); ltc.Balance != 4 || ltc.Available != 4 {
+		t.Fatalf("LTC %+v", ltc)
+	}
+}
+
+func TestPaperAutoMatchOff(t *testing.T) {
+	p, s := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 10), bittrex.WithPaperAutoMatch(false))
+	uuid, _ := p.SellLimit("BTC-LTC", 1, 0.0095)
+	s.SetOrderBook("BTC-LTC", &bittrex.OrderBook{Buy: []bittrex.Orderb{{Quantity: 2, Rate: 0.0097}}})
+	if history, _ := p.GetOrderHistory("all"); len(history) != 0 {
+		t.Fatalf("matched without auto match: %+v", history)
+	}
+	if err := p.Match(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if o := order(t, p, uuid); o.IsOpen {
+		t.Fatalf("order %+v still open after Match", o)
+	}
+}
+
+func TestPaperMarketOrderCancelsTheRest(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("LTC", 20), bittrex.WithPaperFee(0))
+	uuid, err := p.SellMarket("BTC-LTC", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := order(t, p, uuid)
+	if o.IsOpen || o.QuantityRemaining != 6 || !near(o.Price, 4*0.009+10*0.008) {
+		t.Fatalf("order %+v", o)
+	}
+	if ltc := balance(t, p, "LTC"); ltc.Balance != 6 || ltc.Available != 6 {
+		t.Fatalf("LTC %+v", ltc)
+	}
+}
+
+func TestPaperFundsAndCancel(t *testing.T) {
+	p, _ := paper(t, paperBook, bittrex.WithPaperBalance("BTC", 0.01))
+	if _, err := p.BuyLimit("BTC-LTC", 2, 0.005); !errors.Is(err, bittrex.ErrInsufficientFunds) {
+		t.Fatalf("got %v, want ErrInsufficientFunds", err)
+	}
+	uuid, err := p.BuyLimit("BTC-LTC", 1, 0.005)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if btc := balance(t, p, "BTC"); !near(btc.Available, 0.01-0.005*(1+bittrex.DefaultPaperFee)) {
+		t.Fatalf("BTC %+v while the order is open", btc)
+	}
+	if err = p.CancelOrder(uuid); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.CancelOrder(uuid); !errors.Is(err, bittrex.ErrOrderNotOpen) {
+		t.Fatalf("second cancel: got %v, want ErrOrderNotOpen", err)
+	}
+	if btc := balance(t, p, "BTC"); btc.Available != 0.01 || btc.Balance != 0.01 {
+		t.Fatalf("BTC %+v after cancel", btc)
+	}
+	if history, _ := p.GetOrderHistory("BTC-LTC"); len(history) != 1 || history[0].QuantityRemaining != 1 {
+		t.Fatalf("history %+v", history)
+	}
+	if _, err = p.SellLimit("LTC", 1, 1); !errors.Is(err, bittrex.ErrInvalidMarket) {
+		t.Fatalf("got %v, want ErrInvalidMarket", err)
+	}
+}
@@ -0,0 +1,175 @@
+package bittrex
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the calls sent by the client.
+type RateLimiter interface {
+	// Wait blocks until a call to endpoint may be sent, or until ctx is done.
+	Wait(ctx context.Context, endpoint string, authNeeded bool) error
+	// Throttled is called when Bittrex answered a call to endpoint with a rate
+	// limit error. retryAfter is the delay asked by the server, or zero.
+	Throttled(endpoint string, authNeeded bool, retryAfter time.Duration)
+}
+
+// WithRateLimiter makes the client wait on l before sending each request.
+func WithRateLimiter(l RateLimiter) Option {
+	return func(c *client) {
+		c.limiter = l
+	}
+}
+
+// LimiterConfig configures a TokenBucketLimiter.
+type LimiterConfig struct {
+	PublicRate   float64            // tokens per second for unauthenticated calls
+	PublicBurst  int                // bucket size for unauthenticated calls
+	PrivateRate  float64            // tokens per second for authenticated calls
+	PrivateBurst int                // bucket size for authenticated calls
+	Weights      map[string]float64 // tokens taken by an endpoint (ex: public/getmarketsummaries), 1 if not set
+	Backoff      time.Duration      // pause after a throttling signal without Retry-After, doubled on repeated signals
+	MaxBackoff   time.Duration      // cap of the pause
+}
+
+// DefaultLimiterConfig returns a conservative configuration: one public call
+// and one authenticated call per second, bursts of 5, and a 1s to 1m backoff.
+func DefaultLimiterConfig() LimiterConfig {
+	return LimiterConfig{
+		PublicRate:   1,
+		PublicBurst:  5,
+		PrivateRate:  1,
+		PrivateBurst: 5,
+		Backoff:      time.Second,
+		MaxBackoff:   time.Minute,
+	}
+}
+
+// TokenBucketLimiter is a RateLimiter using one token bucket for public calls
+// and another one for authenticated calls. It is safe for concurrent use.
+type TokenBucketLimiter struct {
+	cfg     LimiterConfig
+	public  *tokenBucket
+	private *tokenBucket
+}
+
+// NewTokenBucketLimiter returns a limiter configured by cfg.
+func NewTokenBucketLimiter(cfg LimiterConfig) *TokenBucketLimiter {
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = cfg.Backoff
+	}
+	return &TokenBucketLimiter{
+		cfg:     cfg,
+		public:  newTokenBucket(cfg.PublicRate, cfg.PublicBurst),
+		private: newTokenBucket(cfg.PrivateRate, cfg.PrivateBurst),
+	}
+}
+
+// Wait implements RateLimiter.
+func (l *TokenBucketLimiter) Wait(ctx context.Context, endpoint string, authNeeded bool) error {
+	weight := 1.0
+	if w, ok := l.cfg.Weights[strings.ToLower(endpoint)]; ok {
+		weight = w
+	}
+	return l.bucket(authNeeded).take(ctx, weight)
+}
+
+// Throttled implements RateLimiter. It pauses the bucket used by endpoint for
+// retryAfter, or for an exponentially growing backoff if retryAfter is zero.
+func (l *TokenBucketLimiter) Throttled(endpoint string, authNeeded bool, retryAfter time.Duration) {
+	l.bucket(authNeeded).pause(retryAfter, l.cfg.Backoff, l.cfg.MaxBackoff)
+}
+
+func (l *TokenBucketLimiter) bucket(authNeeded bool) *tokenBucket {
+	if authNeeded {
+		return l.private
+	}
+	return l.public
+}
+
+// tokenBucket is a classic token bucket which may also be paused.
+type tokenBucket struct {
+	mu          sync.Mutex
+	rate        float64 // tokens per second, <= 0 means unlimited
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	strikes     int       // consecutive throttling signals
+	lastStrike  time.Time // time of the last throttling signal
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// take blocks until weight tokens are available and consumes them.
+func (b *tokenBucket) take(ctx context.Context, weight float64) error {
+	if weight > b.burst {
+		weight = b.burst
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		var wait time.Duration
+		if now.Before(b.pausedUntil) {
+			wait = b.pausedUntil.Sub(now)
+		} else if b.rate <= 0 {
+			b.mu.Unlock()
+			return ctx.Err()
+		} else {
+			b.tokens += now.Sub(b.last).Seconds() * b.rate
+			if b.tokens > b.burst {
+				b.tokens = b.burst
+			}
+			b.last = now
+			if b.tokens >= weight {
+				b.tokens -= weight
+				b.mu.Unlock()
+				return nil
+			}
+			wait = time.Duration((weight - b.tokens) / b.rate * float64(time.Second))
+		}
+		b.mu.Unlock()
+		if err := sleepCtx(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// pause stops handing out tokens for retryAfter, or for backoff doubled on
+// every signal without Retry-After, up to maxBackoff. The doubling starts over
+// once no throttling signal came for twice maxBackoff.
+func (b *tokenBucket) pause(retryAfter, backoff, maxBackoff time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	d := retryAfter
+	if d <= 0 {
+		if now.Sub(b.lastStrike) > 2*maxBackoff {
+			b.strikes = 0
+		}
+		d = backoff
+		for i := 0; i < b.strikes && d < maxBackoff; i++ {
+			d *= 2
+		}
+		if d > maxBackoff {
+			d = maxBackoff
+		}
+		b.strikes++
+	}
+	b.lastStrike = now
+	if until := now.Add(d); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.tokens = 0
+	b.last = now
+}
@@ -0,0 +1,109 @@
+package bittrex_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// blocked reports whether a call to endpoint has to wait more than 20ms.
+func blocked(t *testing.T, l *bittrex.TokenBucketLimiter, endpoint string, authNeeded bool) bool {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	return l.Wait(ctx, endpoint, authNeeded) != nil
+}
+
+// waited returns how long a call to a public endpoint waits.
+func waited(t *testing.T, l *bittrex.TokenBucketLimiter) time.Duration {
+	t.Helper()
+	start := time.Now()
+	if err := l.Wait(context.Background(), "public/getticker", false); err != nil {
+		t.Fatal(err)
+	}
+	return time.Since(start)
+}
+
+func TestLimiterBuckets(t *testing.T) {
+	// A token every 1000s: a bucket is empty once its burst is taken.
+	l := bittrex.NewTokenBucketLimiter(bittrex.LimiterConfig{PublicRate: 0.001, PublicBurst: 2, PrivateRate: 0.001, PrivateBurst: 1})
+	for i := 0; i < 2; i++ {
+		if blocked(t, l, "public/getticker", false) {
+			t.Fatalf("public call %d blocked within the burst", i)
+		}
+	}
+	if !blocked(t, l, "public/getticker", false) {
+		t.Fatal("public call past the burst not blocked")
+	}
+	// Authenticated calls have their own bucket.
+	if blocked(t, l, "account/getbalances", true) {
+		t.Fatal("private call blocked by the public bucket")
+	}
+	if !blocked(t, l, "account/getbalances", true) {
+		t.Fatal("private call past the burst not blocked")
+	}
+}
+
+func TestLimiterWeights(t *testing.T) {
+	l := bittrex.NewTokenBucketLimiter(bittrex.LimiterConfig{PublicRate: 0.001, PublicBurst: 4,
+		Weights: map[string]float64{"public/getmarketsummaries": 3}})
+	if blocked(t, l, "public/GetMarketSummaries", false) {
+		t.Fatal("weighted call blocked on a full bucket")
+	}
+	if blocked(t, l, "public/getticker", false) {
+		t.Fatal("call blocked with a token left")
+	}
+	if !blocked(t, l, "public/getticker", false) {
+		t.Fatal("weighted call took a single token")
+	}
+}
+
+func TestLimiterRetryAfter(t *testing.T) {
+	l := bittrex.NewTokenBucketLimiter(bittrex.LimiterConfig{PublicRate: 1000, PublicBurst: 5, PrivateRate: 1000, PrivateBurst: 5})
+	l.Throttled("public/getticker", false, 50*time.Millisecond)
+	if blocked(t, l, "account/getbalances", true) {
+		t.Fatal("private call paused by a public signal")
+	}
+	if d := waited(t, l); d < 40*time.Millisecond {
+		t.Fatalf("waited %v, want the 50ms asked", d)
+	}
+}
+
+func TestLimiterBackoff(t *testing.T) {
+	ms := time.Millisecond
+	l := bittrex.NewTokenBucketLimiter(bittrex.LimiterConfig{PublicRate: 1000, PublicBurst: 5, Backoff: 10 * ms, MaxBackoff: 40 * ms})
+	// Two signals: 10ms, then 20ms.
+	l.Throttled("public/getticker", false, 0)
+	l.Throttled("public/getticker", false, 0)
+	if d := waited(t, l); d < 18*ms {
+		t.Fatalf("waited %v after two signals, want 20ms", d)
+	}
+	// Then 40ms, and 40ms again instead of 80ms.
+	l.Throttled("public/getticker", false, 0)
+	l.Throttled("public/getticker", false, 0)
+	if d := waited(t, l); d < 38*ms || d > 70*ms {
+		t.Fatalf("waited %v after four signals, want 40ms", d)
+	}
+	// After twice MaxBackoff without signal, the pause is back to Backoff.
+	time.Sleep(100 * ms)
+	l.Throttled("public/getticker", false, 0)
+	if d := waited(t, l); d < 8*ms || d > 35*ms {
+		t.Fatalf("waited %v after a quiet period, want 10ms", d)
+	}
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	l := bittrex.NewTokenBucketLimiter(bittrex.DefaultLimiterConfig())
+	l.Throttled("public/getticker", false, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := l.Wait(ctx, "public/getticker", false); err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("returned after %v", d)
+	}
+}
@@ -0,0 +1,564 @@
+package bittrex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Feeds of a Recorder, also the names of their directories.
+const (
+	FeedSummaries = "summaries" // GetMarketSummaries, one record per market and poll
+	FeedOrderBook = "orderbook" // GetOrderBook, one record per market and poll
+	FeedTrades    = "trades"    // GetMarketHistory, each trade recorded once
+	FeedCandles   = "candles"   // GetTicks, each closed candle recorded once
+)
+
+// File formats of a Recorder.
+const (
+	RecordNDJSON = "ndjson" // one JSON object per line: {"time":..., "market":..., "data":...}
+	RecordCSV    = "csv"    // one row per record, or per price level for order books, with a header
+)
+
+// RecorderOption configures a Recorder.
+type RecorderOption func(*Recorder)
+
+// WithRecorderFeed sets how often feed is polled, 0 disables it. Defaults to
+// every minute for the summaries, order books and trades, and every hour for
+// the candles.
+func WithRecorderFeed(feed string, every time.Duration) RecorderOption {
+	return func(r *Recorder) {
+		r.schedule[feed] = every
+	}
+}
+
+// WithRecorderFormat sets the format of the files, RecordNDJSON or RecordCSV.
+// Defaults to RecordNDJSON.
+func WithRecorderFormat(format string) RecorderOption {
+	return func(r *Recorder) {
+		r.format = format
+	}
+}
+
+// WithRecorderCompression sets whether the files are gzipped. Defaults to true.
+func WithRecorderCompression(compress bool) RecorderOption {
+	return func(r *Recorder) {
+		r.compress = compress
+	}
+}
+
+// WithRecorderMaxFileSize makes the recorder start a new file of the day once
+// the current one holds size bytes, before compression. Defaults to 0: one
+// file per day.
+func WithRecorderMaxFileSize(size int64) RecorderOption {
+	return func(r *Recorder) {
+		r.maxSize = size
+	}
+}
+
+// WithRecorderBookDepth sets the number of price levels recorded on each side
+// of the order books. Defaults to 20.
+func WithRecorderBookDepth(depth int) RecorderOption {
+	return func(r *Recorder) {
+		r.depth = depth
+	}
+}
+
+// WithRecorderCandleInterval sets the interval of the recorded candles.
+// Defaults to OneMin.
+func WithRecorderCandleInterval(interval Interval) RecorderOption {
+	return func(r *Recorder) {
+		r.interval = interval
+	}
+}
+
+// WithRecorderBuffer sets the capacity of the error channel. Defaults to 256.
+func WithRecorderBuffer(n int) RecorderOption {
+	return func(r *Recorder) {
+		r.bufSize = n
+	}
+}
+
+// Recorder polls the market data of a set of markets on schedules and writes
+// it to local files, to build a history longer than the one the exchange
+// keeps. Files are partitioned by feed, market and UTC day:
+//
+//	dir/trades/BTC-LTC/2026-10-17.ndjson.gz
+//
+// Snapshots (summaries, order books) fall in the day they were taken, trades
+// and candles in the day of their own time. A file is never appended to once
+// closed: a new part, ex: 2026-10-17.1.ndjson.gz, is started on restart, and
+// when the file reaches the size set by WithRecorderMaxFileSize. Files are
+// flushed after each poll, so that a crash loses at most the poll being
+// written.
+//
+// Trades and candles are deduplicated across polls, but not across restarts:
+// the first poll after a restart records the ones the exchange still returns
+// again.
+type Recorder struct {
+	source   MarketData
+	dir      string
+	markets  []string
+	schedule map[string]time.Duration
+	format   string
+	compress bool
+	maxSize  int64
+	depth    int
+	interval Interval
+	bufSize  int
+
+	errs chan error
+
+	mu      sync.Mutex
+	files   map[string]*recordFile // by feed/market
+	trades  map[string]map[string]time.Time
+	candles map[string]time.Time // time of the last candle recorded, by market
+}
+
+// record is a line, or a few rows, of a recorded file.
+type record struct {
+	time   time.Time // when it was recorded
+	day    time.Time // partition
+	market string
+	data   interface{}
+}
+
+// NewRecorder returns a recorder of markets polling source, typically a
+// *Bittrex, and writing under dir, created if needed.
+func NewRecorder(source MarketData, dir string, markets []string, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		source: source,
+		dir:    dir,
+		schedule: map[string]time.Duration{
+			FeedSummaries: time.Minute,
+			FeedOrderBook: time.Minute,
+			FeedTrades:    time.Minute,
+			FeedCandles:   time.Hour,
+		},
+		format:   RecordNDJSON,
+		compress: true,
+		depth:    20,
+		interval: OneMin,
+		bufSize:  256,
+		files:    map[string]*recordFile{},
+		trades:   map[string]map[string]time.Time{},
+		candles:  map[string]time.Time{},
+	}
+	for _, m := range markets {
+		r.markets = append(r.markets, strings.ToUpper(m))
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch {
+	case len(r.markets) == 0:
+		return nil, errors.New("bittrex: recorder without markets")
+	case r.format != RecordNDJSON && r.format != RecordCSV:
+		return nil, fmt.Errorf("bittrex: unknown record format %q", r.format)
+	case r.interval.Duration() == 0:
+		return nil, fmt.Errorf("bittrex: unknown interval %q", r.interval)
+	}
+	for feed, every := range r.schedule {
+		if feed != FeedSummaries && feed != FeedOrderBook && feed != FeedTrades && feed != FeedCandles {
+			return nil, fmt.Errorf("bittrex: unknown feed %q", feed)
+		}
+		if every < 0 {
+			return nil, fmt.Errorf("bittrex: negative schedule of %s", feed)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	r.errs = make(chan error, r.bufSize)
+	return r, nil
+}
+
+// Errors returns the channel of non fatal errors: failed polls, which are
+// tried again on schedule, and failed writes. Errors are dropped if it is full.
+func (r *Recorder) Errors() <-chan error { return r.errs }
+
+// Run polls the feeds on their schedules, the first time right away, until ctx
+// is done. It then closes the files and returns ctx.Err(), or the error of
+// closing a file. Run may be called only once.
+func (r *Recorder) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for feed, every := range r.schedule {
+		if every == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(feed string, every time.Duration) {
+			defer wg.Done()
+			r.runFeed(ctx, feed, every)
+		}(feed, every)
+	}
+	wg.Wait()
+	if err := r.Close(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (r *Recorder) runFeed(ctx context.Context, feed string, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		r.Poll(ctx, feed)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll polls feed once for all the markets and records the result. Errors are
+// sent on Errors.
+func (r *Recorder) Poll(ctx context.Context, feed string) {
+	if feed == FeedSummaries {
+		r.pollSummaries(ctx)
+		return
+	}
+	for _, market := range r.markets {
+		if ctx.Err() != nil {
+			return
+		}
+		var recs []record
+		var err error
+		switch feed {
+		case FeedOrderBook:
+			recs, err = r.pollOrderBook(ctx, market)
+		case FeedTrades:
+			recs, err = r.pollTrades(ctx, market)
+		case FeedCandles:
+			recs, err = r.pollCandles(ctx, market)
+		default:
+			err = errors.New("unknown feed")
+		}
+		if err == nil {
+			err = r.write(feed, market, recs)
+		}
+		if err != nil && ctx.Err() == nil {
+			r.report(fmt.Errorf("bittrex: recorder: %s %s: %w", feed, market, err))
+		}
+	}
+}
+
+func (r *Recorder) pollSummaries(ctx context.Context) {
+	summaries, err := r.source.GetMarketSummariesCtx(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.report(fmt.Errorf("bittrex: recorder: %s: %w", FeedSummaries, err))
+		}
+		return
+	}
+	now := time.Now().UTC()
+	byMarket := map[string]*MarketSummary{}
+	for _, s := range summaries {
+		byMarket[strings.ToUpper(s.MarketName)] = s
+	}
+	for _, market := range r.markets {
+		s, ok := byMarket[market]
+		if !ok {
+			r.report(fmt.Errorf("bittrex: recorder: %s %s: no summary", FeedSummaries, market))
+			continue
+		}
+		rec := record{time: now, day: now, market: market, data: s}
+		if err = r.write(FeedSummaries, market, []record{rec}); err != nil {
+			r.report(fmt.Errorf("bittrex: recorder: %s %s: %w", FeedSummaries, market, err))
+		}
+	}
+}
+
+func (r *Recorder) pollOrderBook(ctx context.Context, market string) ([]record, error) {
+	book, err := r.source.GetOrderBookCtx(ctx, market, "both", r.depth)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return []record{{time: now, day: now, market: market, data: book}}, nil
+}
+
+// pollTrades returns the trades of market not recorded yet, oldest first.
+func (r *Recorder) pollTrades(ctx context.Context, market string) ([]record, error) {
+	trades, err := r.source.GetMarketHistoryCtx(ctx, market)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	sort.SliceStable(trades, func(i, j int) bool {
+		if !trades[i].TimeStamp.Equal(trades[j].TimeStamp) {
+			return trades[i].TimeStamp.Before(trades[j].TimeStamp)
+		}
+		return trades[i].Id < trades[j].Id
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seen := r.trades[market]
+	if seen == nil {
+		seen = map[string]time.Time{}
+		r.trades[market] = seen
+	}
+	var recs []record
+	for _, t := range trades {
+		key := tradeKey(t)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = t.TimeStamp
+		recs = append(recs, record{time: now, day: t.TimeStamp.UTC(), market: market, data: t})
+	}
+	// The history only holds the latest trades: the ones older than the
+	// oldest returned will not be returned again.
+	if len(trades) > 0 {
+		for key, at := range seen {
+			if at.Before(trades[0].TimeStamp) {
+				delete(seen, key)
+			}
+		}
+	}
+	return recs, nil
+}
+
+// pollCandles returns the closed candles of market newer than the last one
+// recorded, oldest first.
+func (r *Recorder) pollCandles(ctx context.Context, market string) ([]record, error) {
+	candles, err := r.source.GetTicksCtx(ctx, market, r.interval)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	step := r.interval.Duration()
+	sort.SliceStable(candles, func(i, j int) bool { return candles[i].TimeStamp.Before(candles[j].TimeStamp) })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := r.candles[market]
+	var recs []record
+	for _, c := range candles {
+		if !c.TimeStamp.After(last) || c.TimeStamp.Add(step).After(now) {
+			continue
+		}
+		recs = append(recs, record{time: now, day: c.TimeStamp.UTC(), market: market, data: c})
+		last = c.TimeStamp
+	}
+	r.candles[market] = last
+	return recs, nil
+}
+
+// write appends recs to the files of feed and market, then flushes them.
+func (r *Recorder) write(feed, market string, recs []record) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := feed + "/" + market
+	f := r.files[key]
+	for _, rec := range recs {
+		day := rec.day.UTC().Format("2006-01-02")
+		if f != nil && (f.day != day || (r.maxSize > 0 && f.size >= r.maxSize)) {
+			err := f.close()
+			delete(r.files, key)
+			f = nil
+			if err != nil {
+				return err
+			}
+		}
+		if f == nil {
+			var err error
+			if f, err = r.open(feed, market, day); err != nil {
+				return err
+			}
+			r.files[key] = f
+		}
+		if err := f.write(rec); err != nil {
+			return err
+		}
+	}
+	return f.flush()
+}
+
+// open creates the first part of day not written yet.
+func (r *Recorder) open(feed, market, day string) (*recordFile, error) {
+	dir := filepath.Join(r.dir, feed, market)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	ext := "." + r.format
+	if r.compress {
+		ext += ".gz"
+	}
+	for part := 0; ; part++ {
+		name := day + ext
+		if part > 0 {
+			name = day + "." + strconv.Itoa(part) + ext
+		}
+		path := filepath.Join(dir, name)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		f := &recordFile{file: file, day: day}
+		if r.compress {
+			f.gz = gzip.NewWriter(file)
+			f.buf = bufio.NewWriter(f.gz)
+		} else {
+			f.buf = bufio.NewWriter(file)
+		}
+		if r.format == RecordCSV {
+			f.csv = csv.NewWriter(f)
+			if err = f.csv.Write(recordHeader(feed)); err != nil {
+				f.close()
+				return nil, err
+			}
+		}
+		return f, nil
+	}
+}
+
+// Close closes the files being written. Run closes them when it returns.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var first error
+	for key, f := range r.files {
+		if err := f.close(); err != nil && first == nil {
+			first = err
+		}
+		delete(r.files, key)
+	}
+	return first
+}
+
+func (r *Recorder) report(err error) {
+	select {
+	case r.errs <- err:
+	default:
+	}
+}
+
+// recordFile is a file being recorded: the records are buffered, then
+// optionally gzipped, before they reach the file.
+type recordFile struct {
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	csv  *csv.Writer
+	day  string
+	size int64 // bytes written, before compression
+}
+
+// Write lets the csv writer count the bytes it writes.
+func (f *recordFile) Write(p []byte) (int, error) {
+	n, err := f.buf.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *recordFile) write(rec record) error {
+	if f.csv != nil {
+		return f.csv.WriteAll(recordRows(rec))
+	}
+	data, err := json.Marshal(struct {
+		Time   string      `json:"time"`
+		Market string      `json:"market"`
+		Data   interface{} `json:"data"`
+	}{rec.time.Format(time.RFC3339Nano), rec.market, rec.data})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+func (f *recordFile) flush() error {
+	if f.csv != nil {
+		f.csv.Flush()
+		if err := f.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := f.buf.Flush(); err != nil {
+		return err
+	}
+	if f.gz != nil {
+		return f.gz.Flush()
+	}
+	return nil
+}
+
+func (f *recordFile) close() error {
+	err := f.flush()
+	if f.gz != nil {
+		if gzErr := f.gz.Close(); err == nil {
+			err = gzErr
+		}
+	}
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// recordHeader returns the CSV header of feed.
+func recordHeader(feed string) []string {
+	switch feed {
+	case FeedSummaries:
+		return []string{"time", "market", "high", "low", "ask", "bid", "openBuyOrders", "openSellOrders", "volume", "last", "baseVolume", "prevDay", "timeStamp"}
+	case FeedOrderBook:
+		return []string{"time", "market", "side", "level", "rate", "quantity"}
+	case FeedTrades:
+		return []string{"time", "market", "id", "orderUuid", "timeStamp", "quantity", "price", "total", "fillType", "orderType"}
+	case FeedCandles:
+		return []string{"time", "market", "timeStamp", "open", "high", "low", "close", "volume", "baseVolume"}
+	}
+	return nil
+}
+
+// recordRows returns the CSV rows of rec: one per price level for an order
+// book, one otherwise.
+func recordRows(rec record) [][]string {
+	at := rec.time.Format(time.RFC3339Nano)
+	switch d := rec.data.(type) {
+	case *MarketSummary:
+		return [][]string{{at, rec.market, formatNumber(d.High), formatNumber(d.Low), formatNumber(d.Ask), formatNumber(d.Bid),
+			strconv.Itoa(d.OpenBuyOrders), strconv.Itoa(d.OpenSellOrders), formatNumber(d.Volume), formatNumber(d.Last),
+			formatNumber(d.BaseVolume), formatNumber(d.PrevDay), d.TimeStamp}}
+	case *OrderBook:
+		rows := make([][]string, 0, len(d.Buy)+len(d.Sell))
+		for i, o := range d.Buy {
+			rows = append(rows, []string{at, rec.market, "buy", strconv.Itoa(i), formatNumber(o.Rate), formatNumber(o.Quantity)})
+		}
+		for i, o := range d.Sell {
+			rows = append(rows, []string{at, rec.market, "sell", strconv.Itoa(i), formatNumber(o.Rate), formatNumber(o.Quantity)})
+		}
+		return rows
+	case *Trade:
+		return [][]string{{at, rec.market, strconv.FormatInt(d.Id, 10), d.OrderUuid, d.TimeStamp.UTC().Format(TIME_FORMAT),
+			formatNumber(d.Quantity), formatNumber(d.Price), formatNumber(d.Total), d.FillType, d.OrderType}}
+	case *Candle:
+		return [][]string{{at, rec.market, d.TimeStamp.UTC().Format(TIME_FORMAT), formatNumber(d.Open), formatNumber(d.High),
+			formatNumber(d.Low), formatNumber(d.Close), formatNumber(d.Volume), formatNumber(d.BaseVolume)}}
+	}
+	return nil
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,255 @@
+package bittrex_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+)
+
+// marketData answers the calls of a Recorder with the trades, candles and
+// book set, the other MarketData methods are not expected.
+type marketData struct {
+	bittrex.MarketData
+
+	mu      sync.Mutex
+	trades  []*bittrex.Trade
+	candles []*bittrex.Candle
+	book    *bittrex.OrderBook
+}
+
+func (m *marketData) set(trades []*bittrex.Trade, candles []*bittrex.Candle) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trades, m.candles = trades, candles
+}
+
+func (m *marketData) GetMarketHistoryCtx(ctx context.Context, market string) ([]*bittrex.Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*bittrex.Trade(nil), m.trades...), nil
+}
+
+func (m *marketData) GetTicksCtx(ctx context.Context, market string, interval bittrex.Interval) ([]*bittrex.Candle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*bittrex.Candle(nil), m.candles...), nil
+}
+
+func (m *marketData) GetOrderBookCtx(ctx context.Context, market, cat string, depth int) (*bittrex.OrderBook, error) {
+	return m.book, nil
+}
+
+func recorder(t *testing.T, source bittrex.MarketData, opts ...bittrex.RecorderOption) (*bittrex.Recorder, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := bittrex.NewRecorder(source, dir, []string{"btc-ltc"}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r, dir
+}
+
+// files returns the names of the files of feed, sorted.
+func files(t *testing.T, dir, feed string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(dir, feed, "BTC-LTC"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lines returns the lines of the recorded file name of feed, gunzipped if need be.
+func lines(t *testing.T, dir, feed, name string) []string {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, feed, "BTC-LTC", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if filepath.Ext(name) == ".gz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	var lines []string
+	for s := bufio.NewScanner(r); s.Scan(); {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+// recordedTrades returns the ids of the trades recorded in the NDJSON file name.
+func recordedTrades(t *testing.T, dir, name string) []int64 {
+	t.Helper()
+	var ids []int64
+	for _, line := range lines(t, dir, bittrex.FeedTrades, name) {
+		var rec struct {
+			Market string
+			Data   bittrex.Trade
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if rec.Market != "BTC-LTC" {
+			t.Fatalf("market of %s", line)
+		}
+		ids = append(ids, rec.Data.Id)
+	}
+	return ids
+}
+
+var recordDay = time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+
+func recordTrade(id int64, at time.Time) *bittrex.Trade {
+	return &bittrex.Trade{Id: id, TimeStamp: at, Quantity: 1, Price: 0.01, Total: 0.01, FillType: "FILL", OrderType: "BUY"}
+}
+
+func TestRecorderTrades(t *testing.T) {
+	source := &marketData{}
+	r, dir := recorder(t, source)
+	// Trades fall in the day of their own time.
+	source.set([]*bittrex.Trade{recordTrade(2, recordDay.Add(time.Minute)), recordTrade(1, recordDay.Add(-time.Minute))}, nil)
+	r.Poll(context.Background(), bittrex.FeedTrades)
+	// The next poll overlaps the first one.
+	source.set([]*bittrex.Trade{recordTrade(2, recordDay.Add(time.Minute)), recordTrade(3, recordDay.Add(2*time.Minute))}, nil)
+	r.Poll(context.Background(), bittrex.FeedTrades)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := files(t, dir, bittrex.FeedTrades); !reflect.DeepEqual(names, []string{"2026-10-13.ndjson.gz", "2026-10-14.ndjson.gz"}) {
+		t.Fatalf("files %v", names)
+	}
+	if ids := recordedTrades(t, dir, "2026-10-13.ndjson.gz"); !reflect.DeepEqual(ids, []int64{1}) {
+		t.Errorf("2026-10-13: trades %v", ids)
+	}
+	if ids := recordedTrades(t, dir, "2026-10-14.ndjson.gz"); !reflect.DeepEqual(ids, []int64{2, 3}) {
+		t.Errorf("2026-10-14: trades %v", ids)
+	}
+}
+
+func TestRecorderRotation(t *testing.T) {
+	source := &marketData{}
+	// Each file holds one line: a new part starts on every record.
+	r, dir := recorder(t, source, bittrex.WithRecorderCompression(false), bittrex.WithRecorderMaxFileSize(1))
+	source.set([]*bittrex.Trade{recordTrade(1, recordDay), recordTrade(2, recordDay.Add(time.Second)), recordTrade(3, recordDay.Add(2*time.Second))}, nil)
+	r.Poll(context.Background(), bittrex.FeedTrades)
+	r.Close()
+	want := []string{"2026-10-14.1.ndjson", "2026-10-14.2.ndjson", "2026-10-14.ndjson"}
+	if names := files(t, dir, bittrex.FeedTrades); !reflect.DeepEqual(names, want) {
+		t.Fatalf("files %v, want %v", names, want)
+	}
+	if ids := recordedTrades(t, dir, "2026-10-14.2.ndjson"); !reflect.DeepEqual(ids, []int64{3}) {
+		t.Fatalf("last part: trades %v", ids)
+	}
+
+	// A restarted recorder starts a new part instead of appending.
+	r, err := bittrex.NewRecorder(source, dir, []string{"BTC-LTC"}, bittrex.WithRecorderCompression(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.set([]*bittrex.Trade{recordTrade(4, recordDay.Add(3*time.Second))}, nil)
+	r.Poll(context.Background(), bittrex.FeedTrades)
+	r.Close()
+	if ids := recordedTrades(t, dir, "2026-10-14.3.ndjson"); !reflect.DeepEqual(ids, []int64{4}) {
+		t.Fatalf("part after restart: trades %v", ids)
+	}
+}
+
+func TestRecorderCandles(t *testing.T) {
+	source := &marketData{}
+	r, dir := recorder(t, source, bittrex.WithRecorderFormat(bittrex.RecordCSV), bittrex.WithRecorderCompression(false))
+	candle := func(at time.Time, price float64) *bittrex.Candle {
+		return &bittrex.Candle{TimeStamp: at, Open: price, High: price, Low: price, Close: price, Volume: 1, BaseVolume: price}
+	}
+	// Yesterday's minutes, so that the files are of one day; the last one is still open.
+	start := time.Now().UTC().Truncate(time.Minute).Add(-24 * time.Hour)
+	old, open := start.Add(-2*time.Minute), time.Now().UTC().Truncate(time.Minute)
+	source.set(nil, []*bittrex.Candle{candle(start, 2), candle(old, 1), candle(open, 9)})
+	r.Poll(context.Background(), bittrex.FeedCandles)
+	// The next poll returns the same candles, and a new closed one.
+	source.set(nil, []*bittrex.Candle{candle(old, 1), candle(start, 2), candle(start.Add(time.Minute), 3), candle(open, 9)})
+	r.Poll(context.Background(), bittrex.FeedCandles)
+	r.Close()
+
+	var rows [][]string
+	for _, name := range files(t, dir, bittrex.FeedCandles) {
+		f, err := os.Open(filepath.Join(dir, bittrex.FeedCandles, "BTC-LTC", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"time", "market", "timeStamp", "open", "high", "low", "close", "volume", "baseVolume"}; !reflect.DeepEqual(records[0], want) {
+			t.Fatalf("%s: header %v", name, records[0])
+		}
+		rows = append(rows, records[1:]...)
+	}
+	var closes []string
+	for _, row := range rows {
+		if row[1] != "BTC-LTC" || row[7] != "1" {
+			t.Fatalf("row %v", row)
+		}
+		closes = append(closes, row[6])
+	}
+	if !reflect.DeepEqual(closes, []string{"1", "2", "3"}) {
+		t.Fatalf("closes %v, want each closed candle once", closes)
+	}
+	if rows[1][2] != start.Format(bittrex.TIME_FORMAT) {
+		t.Fatalf("row %v", rows[1])
+	}
+}
+
+func TestRecorderOrderBookCSV(t *testing.T) {
+	source := &marketData{book: &bittrex.OrderBook{
+		Buy:  []bittrex.Orderb{{Quantity: 4, Rate: 0.009}, {Quantity: 10, Rate: 0.008}},
+		Sell: []bittrex.Orderb{{Quantity: 5, Rate: 0.01}},
+	}}
+	r, dir := recorder(t, source, bittrex.WithRecorderFormat(bittrex.RecordCSV))
+	r.Poll(context.Background(), bittrex.FeedOrderBook)
+	r.Close()
+
+	names := files(t, dir, bittrex.FeedOrderBook)
+	if len(names) != 1 || filepath.Ext(names[0]) != ".gz" {
+		t.Fatalf("files %v", names)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.Join(lines(t, dir, bittrex.FeedOrderBook, names[0]), "\n"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One row per price level.
+	var levels [][]string
+	for _, row := range records[1:] {
+		levels = append(levels, row[2:])
+	}
+	want := [][]string{{"buy", "0", "0.009", "4"}, {"buy", "1", "0.008", "10"}, {"sell", "0", "0.01", "5"}}
+	if !reflect.DeepEqual(records[0], []string{"time", "market", "side", "level", "rate", "quantity"}) || !reflect.DeepEqual(levels, want) {
+		t.Fatalf("rows %v", records)
+	}
+}
@@ -0,0 +1,141 @@
+package bittrex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var intervalDurations = map[Interval]time.Duration{
+	OneMin:    time.Minute,
+	FiveMin:   5 * time.Minute,
+	ThirtyMin: 30 * time.Minute,
+	Hour:      time.Hour,
+	Day:       day,
+}
+
+// Duration returns the span of a candle of interval i, 0 if i is unknown.
+func (i Interval) Duration() time.Duration {
+	return intervalDurations[i]
+}
+
+// ParseTimeframe parses a candle timeframe: a number followed by m (minutes),
+// h (hours), d (days) or w (weeks), ex: 15m, 4h, 1w, or an Interval name, ex:
+// hour. Timeframes align to UTC boundaries: they must divide a day, or be a
+// whole number of days. Weeks start on Monday.
+func ParseTimeframe(s string) (time.Duration, error) {
+	if d := Interval(s).Duration(); d > 0 {
+		return d, nil
+	}
+	if len(s) < 2 {
+		return 0, fmt.Errorf("bittrex: invalid timeframe %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("bittrex: invalid timeframe %q", s)
+	}
+	var unit time.Duration
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "m":
+		unit = time.Minute
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = day
+	case "w":
+		unit = week
+	default:
+		return 0, fmt.Errorf("bittrex: invalid timeframe %q", s)
+	}
+	d := time.Duration(n) * unit
+	if (d < day && day%d != 0) || (d > day && d%day != 0) {
+		return 0, fmt.Errorf("bittrex: timeframe %q does not align on UTC days", s)
+	}
+	return d, nil
+}
+
+// Resample aggregates candles of the source interval into bars of timeframe,
+// aligned to UTC boundaries: each bar opens with its first candle, closes with
+// its last one, spans their high and low, and sums their volumes. The bars are
+// stamped with their start time, oldest first.
+//
+// partial reports whether the last bar is still incomplete: the candles end
+// before its end, or it ends after now, as the last candle may itself be
+// still open. Pass the zero time as now for candles known to be closed. The
+// first bar may also cover less than timeframe, if the candles start after
+// its start.
+func Resample(candles []*Candle, source Interval, timeframe time.Duration, now time.Time) (bars []*Candle, partial bool, err error) {
+	step := source.Duration()
+	if step == 0 {
+		return nil, false, fmt.Errorf("bittrex: unknown interval %q", source)
+	}
+	if timeframe < step || timeframe%step != 0 {
+		return nil, false, fmt.Errorf("bittrex: cannot resample %s candles to %s", source, timeframe)
+	}
+	sorted := append([]*Candle(nil), candles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TimeStamp.Before(sorted[j].TimeStamp) })
+
+	var bar *Candle
+	for _, c := range sorted {
+		// The zero time is a Monday, so that weeks truncate to Mondays.
+		start := c.TimeStamp.UTC().Truncate(timeframe)
+		if bar == nil || !start.Equal(bar.TimeStamp) {
+			bar = &Candle{TimeStamp: start, Open: c.Open, High: c.High, Low: c.Low}
+			bars = append(bars, bar)
+		}
+		bar.High = math.Max(bar.High, c.High)
+		bar.Low = math.Min(bar.Low, c.Low)
+		bar.Close = c.Close
+		bar.Volume += c.Volume
+		bar.BaseVolume += c.BaseVolume
+	}
+	if bar != nil {
+		end := bar.TimeStamp.Add(timeframe)
+		last := sorted[len(sorted)-1]
+		partial = last.TimeStamp.Add(step).Before(end) || (!now.IsZero() && now.Before(end))
+	}
+	return bars, partial, nil
+}
+
+// sourceInterval returns the longest interval which divides timeframe.
+func sourceInterval(timeframe time.Duration) (Interval, bool) {
+	for _, i := range []Interval{Day, Hour, ThirtyMin, FiveMin, OneMin} {
+		if timeframe%i.Duration() == 0 {
+			return i, true
+		}
+	}
+	return "", false
+}
+
+// GetTicksResampled returns the candles of market over timeframe, see
+// ParseTimeframe, built with Resample from the longest interval GetTicks
+// offers which divides it. partial reports whether the last candle is still open.
+func (b *Bittrex) GetTicksResampled(market, timeframe string) (candles []*Candle, partial bool, err error) {
+	return b.GetTicksResampledCtx(context.Background(), market, timeframe)
+}
+
+// GetTicksResampledCtx is like GetTicksResampled but carries ctx to the underlying HTTP request.
+func (b *Bittrex) GetTicksResampledCtx(ctx context.Context, market, timeframe string) (candles []*Candle, partial bool, err error) {
+	d, err := ParseTimeframe(timeframe)
+	if err != nil {
+		return nil, false, err
+	}
+	source, ok := sourceInterval(d)
+	if !ok {
+		return nil, false, fmt.Errorf("bittrex: no interval to build %s candles from", timeframe)
+	}
+	candles, err = b.GetTicksCtx(ctx, market, source)
+	if err != nil {
+		return nil, false, err
+	}
+	return Resample(candles, source, d, time.Now())
+}
@@ -0,0 +1,151 @@
+package bittrex_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+// candles returns n candles of interval step from start, the candle i
+// trading from i+1 to i+3, ex: to check the bars aggregate them.
+func candles(start time.Time, step time.Duration, n int) []*bittrex.Candle {
+	cs := make([]*bittrex.Candle, n)
+	for i := range cs {
+		p := float64(i + 1)
+		cs[i] = &bittrex.Candle{TimeStamp: start.Add(time.Duration(i) * step), Open: p + 1, High: p + 2, Low: p, Close: p + 1, Volume: 1, BaseVolume: p}
+	}
+	return cs
+}
+
+func TestParseTimeframe(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"15m": 15 * time.Minute, "4h": 4 * time.Hour, "2d": 48 * time.Hour, "1w": 7 * 24 * time.Hour, "hour": time.Hour, "thirtyMin": 30 * time.Minute,
+	} {
+		if got, err := bittrex.ParseTimeframe(s); err != nil || got != want {
+			t.Errorf("ParseTimeframe(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	// 7m and 5h do not divide a day, 25h is not whole days.
+	for _, s := range []string{"", "m", "0m", "-1h", "1x", "7m", "5h", "25h"} {
+		if _, err := bittrex.ParseTimeframe(s); err == nil {
+			t.Errorf("ParseTimeframe(%q): no error", s)
+		}
+	}
+}
+
+func TestResampleAlignment(t *testing.T) {
+	for _, c := range []struct {
+		timeframe  string
+		source     bittrex.Interval
+		start      time.Time
+		n          int
+		wantStarts []time.Time
+	}{
+		// The first bar starts on the boundary before the first candle.
+		{"15m", bittrex.FiveMin, time.Date(2026, 10, 14, 0, 10, 0, 0, time.UTC), 4,
+			[]time.Time{time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 14, 0, 15, 0, 0, time.UTC)}},
+		{"4h", bittrex.Hour, time.Date(2026, 10, 14, 3, 0, 0, 0, time.UTC), 6,
+			[]time.Time{time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 14, 4, 0, 0, 0, time.UTC), time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)}},
+		// 2026-10-14 is a Wednesday: weeks start on the Mondays 12 and 19.
+		{"1w", bittrex.Day, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), 7,
+			[]time.Time{time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)}},
+	} {
+		timeframe, err := bittrex.ParseTimeframe(c.timeframe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bars, _, err := bittrex.Resample(candles(c.start, c.source.Duration(), c.n), c.source, timeframe, time.Time{})
+		if err != nil {
+			t.Fatalf("%s: %v", c.timeframe, err)
+		}
+		if len(bars) != len(c.wantStarts) {
+			t.Fatalf("%s: %d bars, want %d", c.timeframe, len(bars), len(c.wantStarts))
+		}
+		for i, bar := range bars {
+			if !bar.TimeStamp.Equal(c.wantStarts[i]) {
+				t.Errorf("%s: bar %d starts at %v, want %v", c.timeframe, i, bar.TimeStamp, c.wantStarts[i])
+			}
+		}
+	}
+}
+
+func TestResampleAggregates(t *testing.T) {
+	start := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	cs := candles(start, time.Hour, 8)
+	// The order of the candles does not matter.
+	cs[0], cs[3] = cs[3], cs[0]
+	bars, partial, err := bittrex.Resample(cs, bittrex.Hour, 4*time.Hour, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if partial || len(bars) != 2 {
+		t.Fatalf("%d bars, partial %v", len(bars), partial)
+	}
+	// Candles 0 to 3: open 2, close 5, high 6, low 1.
+	want := bittrex.Candle{TimeStamp: start, Open: 2, High: 6, Low: 1, Close: 5, Volume: 4, BaseVolume: 1 + 2 + 3 + 4}
+	if *bars[0] != want {
+		t.Errorf("bar %+v, want %+v", *bars[0], want)
+	}
+	want = bittrex.Candle{TimeStamp: start.Add(4 * time.Hour), Open: 6, High: 10, Low: 5, Close: 9, Volume: 4, BaseVolume: 5 + 6 + 7 + 8}
+	if *bars[1] != want {
+		t.Errorf("bar %+v, want %+v", *bars[1], want)
+	}
+
+	if _, _, err = bittrex.Resample(cs, bittrex.Hour, 30*time.Minute, time.Time{}); err == nil {
+		t.Error("resampled to a shorter timeframe")
+	}
+	if _, _, err = bittrex.Resample(cs, bittrex.Hour, 90*time.Minute, time.Time{}); err == nil {
+		t.Error("resampled to a timeframe which is not a multiple")
+	}
+	if bars, partial, err = bittrex.Resample(nil, bittrex.Hour, 4*time.Hour, time.Now()); err != nil || len(bars) != 0 || partial {
+		t.Errorf("no candles: %v, %v, %v", bars, partial, err)
+	}
+}
+
+func TestResamplePartial(t *testing.T) {
+	start := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	four := candles(start, time.Hour, 4)
+	for _, c := range []struct {
+		name    string
+		candles []*bittrex.Candle
+		now     time.Time
+		want    bool
+	}{
+		{"complete", four, time.Time{}, false},
+		{"complete, now after its end", four, start.Add(4 * time.Hour), false},
+		{"missing its last candles", four[:3], time.Time{}, true},
+		// The candle of 03:00 is there, but it is still open.
+		{"last candle open", four, start.Add(3*time.Hour + 20*time.Minute), true},
+	} {
+		if _, partial, err := bittrex.Resample(c.candles, bittrex.Hour, 4*time.Hour, c.now); err != nil || partial != c.want {
+			t.Errorf("%s: partial %v, %v, want %v", c.name, partial, err, c.want)
+		}
+	}
+}
+
+func TestGetTicksResampled(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	// Hour candles, from which 2h ones are built.
+	start := time.Now().UTC().Truncate(2 * time.Hour).Add(-4 * time.Hour)
+	s.SetCandles("BTC-LTC", bittrex.Hour, candles(start, time.Hour, 4)...)
+
+	bars, partial, err := s.Bittrex().GetTicksResampled("BTC-LTC", "2h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bars) != 2 || partial || !bars[0].TimeStamp.Equal(start) || bars[1].Close != 5 {
+		t.Fatalf("bars %+v, partial %v", bars, partial)
+	}
+
+	// The current bar is open, even with its candles all there.
+	s.SetCandles("BTC-LTC", bittrex.Hour, candles(start, time.Hour, 6)...)
+	if bars, partial, err = s.Bittrex().GetTicksResampled("BTC-LTC", "2h"); err != nil || len(bars) != 3 || !partial {
+		t.Fatalf("bars %+v, partial %v, %v", bars, partial, err)
+	}
+	if _, _, err = s.Bittrex().GetTicksResampled("BTC-LTC", "7m"); err == nil {
+		t.Fatal("no error for an invalid timeframe")
+	}
+}
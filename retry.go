@@ -0,0 +1,130 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// mutatingEndpoints lists the resources that change account state.
+// They are never retried automatically, whatever the retry policy says,
+// as a retry after a lost response could place an order or a withdrawal twice.
+var mutatingEndpoints = map[string]bool{
+	"market/buylimit":   true,
+	"market/buymarket":  true,
+	"market/selllimit":  true,
+	"market/sellmarket": true,
+	"market/cancel":     true,
+	"account/withdraw":  true,
+
+	"key/market/tradebuy":  true,
+	"key/market/tradesell": true,
+}
+
+// IsIdempotent reports whether endpoint (ex: public/getticker) only reads data
+// and can be safely sent again.
+func IsIdempotent(endpoint string) bool {
+	return !mutatingEndpoints[strings.ToLower(endpoint)]
+}
+
+// IsTransient reports whether err is likely to go away on a later attempt:
+// network errors, timeouts, rate limiting and 5xx answers (including the
+// Cloudflare 52x pages).
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 ||
+			apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode == http.StatusRequestTimeout ||
+			errors.Is(apiErr, ErrRateLimited)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryPolicy controls how failed calls are retried. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, the first one included.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps a single delay. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay that is randomized.
+	Jitter float64
+	// Retryable decides whether err, returned by endpoint, is worth another attempt.
+	// Defaults to IsTransient. It is never consulted for mutating endpoints.
+	Retryable func(endpoint string, err error) bool
+	// OnRetry, if set, is called before sleeping for each retry.
+	OnRetry func(attempt int, endpoint string, err error, delay time.Duration)
+}
+
+// DefaultRetryPolicy returns a policy making up to 4 attempts, starting with a
+// 250ms delay capped at 5s, with 50% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		Jitter:      0.5,
+	}
+}
+
+// WithRetryPolicy makes the client retry failed idempotent calls according to p.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *client) {
+		c.retry = p
+	}
+}
+
+// shouldRetry reports whether a call to an idempotent endpoint which failed
+// with err on its attempt-th attempt should be tried again.
+func (p RetryPolicy) shouldRetry(ctx context.Context, attempt int, endpoint string, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(endpoint, err)
+	}
+	return IsTransient(err)
+}
+
+// delay returns how long to wait after the attempt-th attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,216 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+// retried is a policy retrying at once, up to 3 attempts.
+var retried = bittrex.RetryPolicy{MaxAttempts: 3}
+
+func TestIsIdempotent(t *testing.T) {
+	for endpoint, want := range map[string]bool{
+		"public/getticker":     true,
+		"account/getbalances":  true,
+		"market/getopenorders": true,
+		"market/buylimit":      false,
+		"market/SellMarket":    false,
+		"market/cancel":        false,
+		"account/withdraw":     false,
+		"key/market/TradeBuy":  false,
+		"key/market/TradeSell": false,
+	} {
+		if got := bittrex.IsIdempotent(endpoint); got != want {
+			t.Errorf("IsIdempotent(%s) = %v, want %v", endpoint, got, want)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&bittrex.APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&bittrex.APIError{StatusCode: 522}, true},
+		{&bittrex.APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{&bittrex.APIError{StatusCode: http.StatusRequestTimeout}, true},
+		{&bittrex.APIError{StatusCode: http.StatusOK, Message: "THROTTLED"}, true},
+		{&bittrex.APIError{StatusCode: http.StatusBadRequest}, false},
+		{&bittrex.APIError{StatusCode: http.StatusNotFound}, false},
+		{&bittrex.APIError{StatusCode: http.StatusOK, Message: "INSUFFICIENT_FUNDS"}, false},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{&url.Error{Op: "Get", URL: "http://x", Err: context.Canceled}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{errors.New("bad JSON"), false},
+	} {
+		if got := bittrex.IsTransient(test.err); got != test.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	b := s.Bittrex(bittrex.WithRetryPolicy(retried))
+
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusRequestTimeout} {
+		s.FailNextStatus("public/getticker", status)
+		s.FailNextStatus("public/getticker", status)
+		if _, err := b.GetTicker("BTC-LTC"); err != nil {
+			t.Errorf("%d: %v after retries", status, err)
+		}
+	}
+	if n := len(s.Requests()); n != 9 {
+		t.Fatalf("%d requests, want 3 attempts per call", n)
+	}
+
+	// The last failure is returned once the attempts are exhausted.
+	for i := 0; i < 3; i++ {
+		s.FailNextStatus("public/getticker", http.StatusBadGateway)
+	}
+	if _, err := b.GetTicker("BTC-LTC"); !errors.Is(err, &bittrex.APIError{StatusCode: http.StatusBadGateway}) {
+		t.Fatalf("got %v, want a 502", err)
+	}
+}
+
+func TestRetrySkipsPermanentFailures(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	b := s.Bittrex(bittrex.WithRetryPolicy(retried))
+
+	s.FailNextStatus("public/getticker", http.StatusBadRequest)
+	if _, err := b.GetTicker("BTC-LTC"); err == nil {
+		t.Fatal("no error")
+	}
+	s.FailNext("public/getticker", "INVALID_MARKET")
+	if _, err := b.GetTicker("BTC-LTC"); !errors.Is(err, bittrex.ErrInvalidMarket) {
+		t.Fatalf("got %v, want ErrInvalidMarket", err)
+	}
+	if got := s.Requests(); len(got) != 2 {
+		t.Fatalf("requests %v, want one per call", got)
+	}
+
+	// A canceled call is not tried again.
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	b = s.Bittrex(bittrex.WithRetryPolicy(bittrex.RetryPolicy{MaxAttempts: 3, OnRetry: func(int, string, error, time.Duration) {
+		attempts++
+		cancel()
+	}}))
+	s.FailNextStatus("public/getticker", http.StatusServiceUnavailable)
+	s.FailNextStatus("public/getticker", http.StatusServiceUnavailable)
+	if _, err := b.GetTickerCtx(ctx, "BTC-LTC"); err == nil || attempts != 1 {
+		t.Fatalf("got %v after %d retries, want the first failure", err, attempts)
+	}
+}
+
+func TestRetryNeverResendsOrders(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Bid: 0.009, Ask: 0.011, Last: 0.01})
+	s.SetBalance("BTC", 1)
+	// The policy would retry anything: mutating endpoints must not ask it.
+	b := s.Bittrex(bittrex.WithRetryPolicy(bittrex.RetryPolicy{MaxAttempts: 3, Retryable: func(string, error) bool { return true }}))
+
+	calls := []struct {
+		endpoint string
+		call     func() error
+	}{
+		{"market/buylimit", func() error { _, err := b.BuyLimit("BTC-LTC", 1, 0.01); return err }},
+		{"account/withdraw", func() error { _, err := b.Withdraw("somewhere", "BTC", 0.1); return err }},
+		{"key/market/TradeBuy", func() error {
+			_, err := b.TradeBuy(bittrex.TradeOrder{Market: "BTC-LTC", Quantity: 1, Rate: 0.01})
+			return err
+		}},
+	}
+	for _, c := range calls {
+		s.FailNextStatus(c.endpoint, http.StatusServiceUnavailable)
+		if err := c.call(); !errors.Is(err, &bittrex.APIError{StatusCode: http.StatusServiceUnavailable}) {
+			t.Errorf("%s: got %v, want the 503", c.endpoint, err)
+		}
+	}
+	want := []string{"market/buylimit", "account/withdraw", "key/market/TradeBuy"}
+	if got := s.Requests(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests %v, want %v", got, want)
+	}
+	if len(s.Orders()) != 0 {
+		t.Fatalf("orders %+v", s.Orders())
+	}
+}
+
+func TestRetryV3OnlyReads(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		http.Error(w, `{"code":"SERVICE_UNAVAILABLE"}`, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	v3 := bittrex.New("key", "secret", bittrex.WithV3BaseURL(ts.URL), bittrex.WithHTTPClient(ts.Client()),
+		bittrex.WithRetryPolicy(retried)).V3()
+
+	ctx := context.Background()
+	v3.GetTicker(ctx, "LTC-BTC")
+	v3.PlaceOrder(ctx, bittrex.V3NewOrder{MarketSymbol: "LTC-BTC", Direction: bittrex.V3DirectionBuy, Type: bittrex.V3OrderTypeMarket})
+	v3.CancelOrder(ctx, "o1")
+	want := map[string]int{"GET /markets/LTC-BTC/ticker": 3, "POST /orders": 1, "DELETE /orders/o1": 1}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("requests %v, want %v", requests, want)
+	}
+}
+
+func TestRetryDelays(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	var delays []time.Duration
+	policy := bittrex.RetryPolicy{MaxAttempts: 6, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond,
+		OnRetry: func(attempt int, endpoint string, err error, delay time.Duration) {
+			delays = append(delays, delay)
+		}}
+	b := s.Bittrex(bittrex.WithRetryPolicy(policy))
+	for i := 0; i < 5; i++ {
+		s.FailNextStatus("public/getticker", http.StatusServiceUnavailable)
+	}
+	if _, err := b.GetTicker("BTC-LTC"); err != nil {
+		t.Fatal(err)
+	}
+	// Doubling from BaseDelay, capped at MaxDelay.
+	ms := time.Millisecond
+	if want := []time.Duration{ms, 2 * ms, 4 * ms, 4 * ms, 4 * ms}; !reflect.DeepEqual(delays, want) {
+		t.Fatalf("delays %v, want %v", delays, want)
+	}
+
+	// Jitter only shortens a delay.
+	delays = nil
+	policy.Jitter = 0.5
+	b = s.Bittrex(bittrex.WithRetryPolicy(policy))
+	for i := 0; i < 5; i++ {
+		s.FailNextStatus("public/getticker", http.StatusServiceUnavailable)
+	}
+	b.GetTicker("BTC-LTC")
+	for _, d := range delays {
+		if d < ms/2 || d > 4*ms {
+			t.Fatalf("delays %v out of bounds", delays)
+		}
+	}
+}
@@ -0,0 +1,675 @@
+package bittrex
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yangou/go-bittrex/internal/ws"
+)
+
+const streamHub = "c3" // name of the Bittrex v3 SignalR hub
+
+// Channels which do not depend on a market. Use TickerChannel, TradeChannel
+// and OrderBookChannel for market channels.
+const (
+	HeartbeatChannel = "heartbeat"
+	OrderChannel     = "order"   // needs authentication
+	BalanceChannel   = "balance" // needs authentication
+)
+
+// TickerChannel returns the ticker channel of a v3 market symbol (ex: LTC-BTC).
+func TickerChannel(symbol string) string {
+	return "ticker_" + strings.ToUpper(symbol)
+}
+
+// TradeChannel returns the trade channel of a v3 market symbol.
+func TradeChannel(symbol string) string {
+	return "trade_" + strings.ToUpper(symbol)
+}
+
+// OrderBookChannel returns the order book channel of a v3 market symbol.
+// depth: 1, 25 or 500
+func OrderBookChannel(symbol string, depth int) string {
+	return fmt.Sprintf("orderbook_%s_%d", strings.ToUpper(symbol), depth)
+}
+
+var (
+	errStreamNotConnected = errors.New("bittrex: stream not connected")
+	errStreamTimeout      = errors.New("bittrex: stream timeout, no message received")
+)
+
+// StreamConn is a message oriented connection to the socket feed.
+type StreamConn interface {
+	ReadMessage() ([]byte, error)
+	WriteMessage(p []byte) error
+	Close() error
+}
+
+// StreamDialer opens a StreamConn. The default dialer negotiates a SignalR
+// websocket connection with the stream URL.
+type StreamDialer func(ctx context.Context) (StreamConn, error)
+
+// StreamOption configures a Stream.
+type StreamOption func(*Stream)
+
+// WithStreamURL sets the SignalR root of the socket feed, ex: http://127.0.0.1:8080/signalr
+func WithStreamURL(root string) StreamOption {
+	return func(s *Stream) {
+		s.url = strings.TrimRight(root, "/")
+	}
+}
+
+// WithStreamDialer replaces the SignalR websocket dialer.
+func WithStreamDialer(dial StreamDialer) StreamOption {
+	return func(s *Stream) {
+		s.dial = dial
+	}
+}
+
+// WithStreamHTTPClient sets the http client used to negotiate connections.
+func WithStreamHTTPClient(httpClient *http.Client) StreamOption {
+	return func(s *Stream) {
+		s.httpClient = httpClient
+	}
+}
+
+// WithStreamBuffer sets the capacity of the event channels.
+func WithStreamBuffer(n int) StreamOption {
+	return func(s *Stream) {
+		s.bufSize = n
+	}
+}
+
+// WithStreamReconnect sets the delay before reconnecting, doubled after each
+// failed connection from min up to max.
+func WithStreamReconnect(min, max time.Duration) StreamOption {
+	return func(s *Stream) {
+		s.minDelay, s.maxDelay = min, max
+	}
+}
+
+// WithStreamTimeout sets how long the stream waits for a message, or for the
+// answer to an invocation, before considering the connection dead.
+func WithStreamTimeout(timeout time.Duration) StreamOption {
+	return func(s *Stream) {
+		s.timeout = timeout
+	}
+}
+
+// Stream is a client of the Bittrex v3 socket feed. It connects, authenticates
+// when credentials are set, subscribes to channels and delivers the events on
+// Go channels, reconnecting and subscribing again when the connection is lost.
+//
+// Events of a subscribed kind must be received, or the stream blocks.
+type Stream struct {
+	apiKey     string
+	apiSecret  string
+	httpClient *http.Client
+	url        string
+	dial       StreamDialer
+	bufSize    int
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	timeout    time.Duration
+
+	tickers    chan *V3Ticker
+	trades     chan *StreamTrade
+	orderBooks chan *StreamOrderBook
+	orders     chan *StreamOrder
+	balances   chan *StreamBalance
+	errs       chan error
+
+	mu       sync.Mutex
+	channels map[string]bool
+	conn     StreamConn
+	nextID   int
+	pending  map[string]chan invocationResult
+	running  bool
+	closed   bool
+}
+
+type invocationResult struct {
+	result json.RawMessage
+	err    error
+}
+
+// NewStream returns a stream of the Bittrex v3 socket feed. apiKey and
+// apiSecret may be empty if only public channels are used.
+func NewStream(apiKey, apiSecret string, opts ...StreamOption) *Stream {
+	s := &Stream{
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		url:        SOCKET_V3_BASE,
+		bufSize:    256,
+		minDelay:   time.Second,
+		maxDelay:   time.Minute,
+		timeout:    30 * time.Second,
+		channels:   map[string]bool{},
+		pending:    map[string]chan invocationResult{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.dial == nil {
+		s.dial = s.dialSignalR
+	}
+	s.tickers = make(chan *V3Ticker, s.bufSize)
+	s.trades = make(chan *StreamTrade, s.bufSize)
+	s.orderBooks = make(chan *StreamOrderBook, s.bufSize)
+	s.orders = make(chan *StreamOrder, s.bufSize)
+	s.balances = make(chan *StreamBalance, s.bufSize)
+	s.errs = make(chan error, s.bufSize)
+	return s
+}
+
+// NewStream returns a stream of the socket feed using b's credentials.
+func (b *Bittrex) NewStream(opts ...StreamOption) *Stream {
+	opts = append([]StreamOption{WithStreamHTTPClient(b.client.httpClient)}, opts...)
+	return NewStream(b.client.apiKey, b.client.apiSecret, opts...)
+}
+
+// Tickers returns the channel of ticker events.
+func (s *Stream) Tickers() <-chan *V3Ticker { return s.tickers }
+
+// Trades returns the channel of trade events.
+func (s *Stream) Trades() <-chan *StreamTrade { return s.trades }
+
+// OrderBooks returns the channel of order book delta events.
+func (s *Stream) OrderBooks() <-chan *StreamOrderBook { return s.orderBooks }
+
+// Orders returns the channel of order events.
+func (s *Stream) Orders() <-chan *StreamOrder { return s.orders }
+
+// Balances returns the channel of balance events.
+func (s *Stream) Balances() <-chan *StreamBalance { return s.balances }
+
+// Errors returns the channel of non fatal errors: disconnections, refused
+// subscriptions, undecodable messages. Errors are dropped if it is full.
+func (s *Stream) Errors() <-chan error { return s.errs }
+
+// Subscribe adds channels to the subscriptions. If the stream is connected the
+// subscription is sent right away, otherwise it is sent on connection.
+func (s *Stream) Subscribe(ctx context.Context, channels ...string) error {
+	s.mu.Lock()
+	for _, c := range channels {
+		s.channels[c] = true
+	}
+	connected := s.conn != nil
+	s.mu.Unlock()
+	if !connected {
+		return nil
+	}
+	err := s.subscribe(ctx, "Subscribe", channels)
+	if errors.Is(err, errStreamNotConnected) {
+		return nil
+	}
+	return err
+}
+
+// Unsubscribe removes channels from the subscriptions.
+func (s *Stream) Unsubscribe(ctx context.Context, channels ...string) error {
+	s.mu.Lock()
+	for _, c := range channels {
+		delete(s.channels, c)
+	}
+	connected := s.conn != nil
+	s.mu.Unlock()
+	if !connected {
+		return nil
+	}
+	err := s.subscribe(ctx, "Unsubscribe", channels)
+	if errors.Is(err, errStreamNotConnected) {
+		return nil
+	}
+	return err
+}
+
+// Run connects to the feed and delivers events until ctx is done. It
+// reconnects on its own and returns ctx.Err(). The event channels are closed
+// when Run returns, so Run may be called only once.
+func (s *Stream) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errors.New("bittrex: stream already running")
+	}
+	s.running = true
+	s.mu.Unlock()
+	defer s.close()
+
+	delay := s.minDelay
+	for {
+		start := time.Now()
+		err := s.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.report(fmt.Errorf("bittrex: stream disconnected: %w", err))
+		if time.Since(start) > s.maxDelay {
+			delay = s.minDelay
+		}
+		if sleepCtx(ctx, delay) != nil {
+			return ctx.Err()
+		}
+		if delay *= 2; delay > s.maxDelay {
+			delay = s.maxDelay
+		}
+	}
+}
+
+// session runs a single connection until it fails or ctx is done.
+func (s *Stream) session(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	var readErr error
+	done := make(chan struct{})
+	go func() {
+		readErr = s.readLoop(ctx, conn)
+		close(done)
+	}()
+	go func() {
+		// unblock readLoop once the session is over
+		<-ctx.Done()
+		conn.Close()
+	}()
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	defer func() {
+		cancel()
+		conn.Close()
+		<-done
+		s.mu.Lock()
+		s.conn = nil
+		for id, ch := range s.pending {
+			ch <- invocationResult{err: errStreamNotConnected}
+			delete(s.pending, id)
+		}
+		s.mu.Unlock()
+	}()
+
+	if s.apiKey != "" {
+		if err = s.authenticate(ctx); err != nil {
+			return err
+		}
+	}
+	if channels := s.subscriptions(); len(channels) > 0 {
+		if err = s.subscribe(ctx, "Subscribe", channels); err != nil {
+			if errors.Is(err, errStreamNotConnected) || errors.Is(err, errStreamTimeout) {
+				return err
+			}
+			s.report(err)
+		}
+	}
+	<-done
+	return readErr
+}
+
+func (s *Stream) subscriptions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channels := make([]string, 0, len(s.channels))
+	for c := range s.channels {
+		channels = append(channels, c)
+	}
+	return channels
+}
+
+// readLoop reads and dispatches messages until the connection fails.
+func (s *Stream) readLoop(ctx context.Context, conn StreamConn) error {
+	var timedOut int32
+	watchdog := time.AfterFunc(s.timeout, func() {
+		atomic.StoreInt32(&timedOut, 1)
+		conn.Close()
+	})
+	defer watchdog.Stop()
+	for {
+		data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if atomic.LoadInt32(&timedOut) == 1 {
+				return errStreamTimeout
+			}
+			return err
+		}
+		watchdog.Reset(s.timeout)
+
+		var frame struct {
+			M []struct {
+				H string            `json:"H"`
+				M string            `json:"M"`
+				A []json.RawMessage `json:"A"`
+			} `json:"M"`
+			R json.RawMessage `json:"R"`
+			E string          `json:"E"`
+			I string          `json:"I"`
+		}
+		if err = json.Unmarshal(data, &frame); err != nil {
+			s.report(fmt.Errorf("bittrex: stream: undecodable message: %w", err))
+			continue
+		}
+		if frame.I != "" {
+			s.resolve(frame.I, frame.R, frame.E)
+			continue
+		}
+		for _, m := range frame.M {
+			if err = s.dispatch(ctx, m.M, m.A); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				s.report(err)
+			}
+		}
+	}
+}
+
+// dispatch decodes a hub message and delivers its event.
+func (s *Stream) dispatch(ctx context.Context, method string, args []json.RawMessage) (err error) {
+	method = strings.ToLower(method)
+	switch method {
+	case "heartbeat":
+		return nil
+	case "authenticationexpiring":
+		go func() {
+			if err := s.authenticate(ctx); err != nil {
+				s.report(err)
+			}
+		}()
+		return nil
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("bittrex: stream: %s message without payload", method)
+	}
+	switch method {
+	case "ticker":
+		event := &V3Ticker{}
+		if err = decodeStreamPayload(args[0], event); err == nil {
+			select {
+			case s.tickers <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	case "trade":
+		event := &StreamTrade{}
+		if err = decodeStreamPayload(args[0], event); err == nil {
+			select {
+			case s.trades <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	case "orderbook":
+		event := &StreamOrderBook{}
+		if err = decodeStreamPayload(args[0], event); err == nil {
+			select {
+			case s.orderBooks <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	case "order":
+		event := &StreamOrder{}
+		if err = decodeStreamPayload(args[0], event); err == nil {
+			select {
+			case s.orders <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	case "balance":
+		event := &StreamBalance{}
+		if err = decodeStreamPayload(args[0], event); err == nil {
+			select {
+			case s.balances <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("bittrex: stream: could not decode %s message: %w", method, err)
+	}
+	return nil
+}
+
+// decodeStreamPayload decodes a hub message argument, which is JSON compressed
+// with raw deflate and base64 encoded.
+func decodeStreamPayload(arg json.RawMessage, v interface{}) error {
+	var encoded string
+	if err := json.Unmarshal(arg, &encoded); err != nil {
+		return err
+	}
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+	r := flate.NewReader(bytes.NewReader(compressed))
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// invoke calls a hub method and waits for its result.
+func (s *Stream) invoke(ctx context.Context, method string, args ...interface{}) (json.RawMessage, error) {
+	s.mu.Lock()
+	conn := s.conn
+	if conn == nil {
+		s.mu.Unlock()
+		return nil, errStreamNotConnected
+	}
+	s.nextID++
+	id := s.nextID
+	ch := make(chan invocationResult, 1)
+	s.pending[strconv.Itoa(id)] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, strconv.Itoa(id))
+		s.mu.Unlock()
+	}()
+
+	msg, err := json.Marshal(struct {
+		H string        `json:"H"`
+		M string        `json:"M"`
+		A []interface{} `json:"A"`
+		I int           `json:"I"`
+	}{streamHub, method, args, id})
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.WriteMessage(msg); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case r := <-ch:
+		return r.result, r.err
+	case <-timer.C:
+		return nil, errStreamTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve hands the result of an invocation to its caller.
+func (s *Stream) resolve(id string, result json.RawMessage, hubErr string) {
+	s.mu.Lock()
+	ch, ok := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	r := invocationResult{result: result}
+	if hubErr != "" {
+		r.err = fmt.Errorf("bittrex: stream: %s", hubErr)
+	}
+	ch <- r
+}
+
+type streamResponse struct {
+	Success   bool   `json:"Success"`
+	ErrorCode string `json:"ErrorCode"`
+}
+
+// authenticate signs in the connection with the API credentials.
+func (s *Stream) authenticate(ctx context.Context) error {
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	random := make([]byte, 16)
+	rand.Read(random)
+	randomContent := hex.EncodeToString(random)
+	mac := hmac.New(sha512.New, []byte(s.apiSecret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + randomContent))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	r, err := s.invoke(ctx, "Authenticate", s.apiKey, timestamp, randomContent, signature)
+	if err != nil {
+		return err
+	}
+	var response streamResponse
+	if err = json.Unmarshal(r, &response); err != nil {
+		return err
+	}
+	if !response.Success {
+		return &APIError{Message: response.ErrorCode, Endpoint: "stream/Authenticate"}
+	}
+	return nil
+}
+
+// subscribe invokes method (Subscribe or Unsubscribe) for channels.
+func (s *Stream) subscribe(ctx context.Context, method string, channels []string) error {
+	r, err := s.invoke(ctx, method, channels)
+	if err != nil {
+		return err
+	}
+	var responses []streamResponse
+	if err = json.Unmarshal(r, &responses); err != nil {
+		return err
+	}
+	var failed []string
+	for i, response := range responses {
+		if !response.Success && i < len(channels) {
+			failed = append(failed, channels[i]+": "+response.ErrorCode)
+		}
+	}
+	if len(failed) > 0 {
+		return &APIError{Message: strings.Join(failed, ", "), Endpoint: "stream/" + method}
+	}
+	return nil
+}
+
+// report delivers a non fatal error, dropping it if nobody listens.
+func (s *Stream) report(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.errs <- err:
+	default:
+	}
+}
+
+func (s *Stream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.tickers)
+	close(s.trades)
+	close(s.orderBooks)
+	close(s.orders)
+	close(s.balances)
+	close(s.errs)
+}
+
+// dialSignalR negotiates a SignalR connection and opens its websocket.
+func (s *Stream) dialSignalR(ctx context.Context) (StreamConn, error) {
+	q := url.Values{}
+	q.Set("clientProtocol", "1.5")
+	q.Set("connectionData", `[{"name":"`+streamHub+`"}]`)
+	var negotiate struct {
+		ConnectionToken string `json:"ConnectionToken"`
+	}
+	if err := s.getJSON(ctx, s.url+"/negotiate?"+q.Encode(), &negotiate); err != nil {
+		return nil, err
+	}
+
+	q.Set("transport", "webSockets")
+	q.Set("connectionToken", negotiate.ConnectionToken)
+	conn, err := ws.Dial(ctx, strings.Replace(s.url, "http", "ws", 1)+"/connect?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var start struct {
+		Response string `json:"Response"`
+	}
+	if err = s.getJSON(ctx, s.url+"/start?"+q.Encode(), &start); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if start.Response != "started" {
+		conn.Close()
+		return nil, fmt.Errorf("bittrex: stream: unexpected start response %q", start.Response)
+	}
+	return conn, nil
+}
+
+func (s *Stream) getJSON(ctx context.Context, rawurl string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawurl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode), Endpoint: s.endpoint(rawurl), Body: body}
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (s *Stream) endpoint(rawurl string) string {
+	if i := strings.IndexByte(rawurl, '?'); i >= 0 {
+		rawurl = rawurl[:i]
+	}
+	return strings.TrimPrefix(rawurl, s.url+"/")
+}
@@ -0,0 +1,34 @@
+package bittrex
+
+// Events delivered by Stream. Tickers are delivered as *V3Ticker.
+
+// StreamTrade is a batch of trades executed on a market.
+type StreamTrade struct {
+	MarketSymbol string    `json:"marketSymbol"`
+	Sequence     int64     `json:"sequence"`
+	Deltas       []V3Trade `json:"deltas"`
+}
+
+// StreamOrderBook is a set of changes to the order book of a market.
+// A delta with a zero Quantity removes the price level.
+type StreamOrderBook struct {
+	MarketSymbol string             `json:"marketSymbol"`
+	Depth        int                `json:"depth"`
+	Sequence     int64              `json:"sequence"`
+	BidDeltas    []V3OrderBookEntry `json:"bidDeltas"`
+	AskDeltas    []V3OrderBookEntry `json:"askDeltas"`
+}
+
+// StreamOrder is a change to one of your orders.
+type StreamOrder struct {
+	AccountID string  `json:"accountId"`
+	Sequence  int64   `json:"sequence"`
+	Delta     V3Order `json:"delta"`
+}
+
+// StreamBalance is a change to one of your balances.
+type StreamBalance struct {
+	AccountID string    `json:"accountId"`
+	Sequence  int64     `json:"sequence"`
+	Delta     V3Balance `json:"delta"`
+}
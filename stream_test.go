@@ -0,0 +1,238 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+// waitFor fails t unless cond becomes true within a few seconds.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// runStream runs stream until the test ends.
+func runStream(t *testing.T, stream *bittrex.Stream) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func subscribed(s *bittrextest.StreamServer, channels ...string) func() bool {
+	return func() bool {
+		got := s.Subscriptions()
+		for _, c := range channels {
+			found := false
+			for _, g := range got {
+				found = found || g == c
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func TestStreamDecodesEvents(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := s.Stream()
+	ticker, trade, book := bittrex.TickerChannel("ltc-btc"), bittrex.TradeChannel("LTC-BTC"), bittrex.OrderBookChannel("LTC-BTC", 25)
+	if err := stream.Subscribe(context.Background(), ticker, trade, book); err != nil {
+		t.Fatal(err)
+	}
+	runStream(t, stream)
+	waitFor(t, "subscriptions", subscribed(s, "ticker_LTC-BTC", "trade_LTC-BTC", "orderbook_LTC-BTC_25"))
+
+	wantTicker := &bittrex.V3Ticker{Symbol: "LTC-BTC", LastTradeRate: 0.0123, BidRate: 0.0122, AskRate: 0.0124}
+	if n, err := s.Publish(ticker, wantTicker); err != nil || n != 1 {
+		t.Fatalf("published to %d connections: %v", n, err)
+	}
+	select {
+	case got := <-stream.Tickers():
+		if !reflect.DeepEqual(got, wantTicker) {
+			t.Fatalf("ticker %+v, want %+v", got, wantTicker)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ticker")
+	}
+
+	executed := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	wantTrade := &bittrex.StreamTrade{MarketSymbol: "LTC-BTC", Sequence: 7, Deltas: []bittrex.V3Trade{
+		{ID: "t1", ExecutedAt: executed, Quantity: 1.5, Rate: 0.0123, TakerSide: "BUY"},
+	}}
+	s.Publish(trade, wantTrade)
+	select {
+	case got := <-stream.Trades():
+		if !reflect.DeepEqual(got, wantTrade) {
+			t.Fatalf("trade %+v, want %+v", got, wantTrade)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no trade")
+	}
+
+	wantBook := &bittrex.StreamOrderBook{MarketSymbol: "LTC-BTC", Depth: 25, Sequence: 8,
+		BidDeltas: []bittrex.V3OrderBookEntry{{Quantity: 0, Rate: 0.0121}},
+		AskDeltas: []bittrex.V3OrderBookEntry{{Quantity: 3, Rate: 0.0125}},
+	}
+	s.Publish(book, wantBook)
+	select {
+	case got := <-stream.OrderBooks():
+		if !reflect.DeepEqual(got, wantBook) {
+			t.Fatalf("order book %+v, want %+v", got, wantBook)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no order book")
+	}
+}
+
+func TestStreamAuthenticatedChannels(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := s.Stream()
+	stream.Subscribe(context.Background(), bittrex.OrderChannel, bittrex.BalanceChannel)
+	runStream(t, stream)
+	waitFor(t, "subscriptions", subscribed(s, bittrex.OrderChannel, bittrex.BalanceChannel))
+
+	want := &bittrex.StreamBalance{AccountID: "a1", Sequence: 3, Delta: bittrex.V3Balance{CurrencySymbol: "BTC", Total: 1.5, Available: 1}}
+	s.Publish(bittrex.BalanceChannel, want)
+	select {
+	case got := <-stream.Balances():
+		if got.Delta.CurrencySymbol != "BTC" || got.Delta.Total != 1.5 || got.Delta.Available != 1 {
+			t.Fatalf("balance %+v, want %+v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no balance")
+	}
+}
+
+func TestStreamBadCredentials(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := bittrex.NewStream(bittrextest.APIKey, "wrong secret",
+		bittrex.WithStreamURL(s.StreamURL()), bittrex.WithStreamReconnect(time.Second, time.Second))
+	stream.Subscribe(context.Background(), bittrex.OrderChannel)
+	runStream(t, stream)
+	select {
+	case err := <-stream.Errors():
+		var apiErr *bittrex.APIError
+		if !errors.As(err, &apiErr) || apiErr.Message != "INVALID_SIGNATURE" {
+			t.Fatalf("got %v, want INVALID_SIGNATURE", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no error")
+	}
+	if got := s.Subscriptions(); len(got) != 0 {
+		t.Fatalf("subscribed to %v without authentication", got)
+	}
+}
+
+func TestStreamSubscribeRefused(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	s.FailSubscribe("ticker_BAD-BTC", "INVALID_CHANNEL")
+	stream := s.Stream()
+	runStream(t, stream)
+	waitFor(t, "connection", func() bool { return s.Connections() == 1 })
+
+	// The stream may still be authenticating, retry until connected.
+	var err error
+	waitFor(t, "refused subscription", func() bool {
+		err = stream.Subscribe(context.Background(), bittrex.TickerChannel("BAD-BTC"), bittrex.TickerChannel("LTC-BTC"))
+		return err != nil
+	})
+	if !strings.Contains(err.Error(), "ticker_BAD-BTC: INVALID_CHANNEL") {
+		t.Fatalf("got %v, want the refused channel", err)
+	}
+	if got := s.Subscriptions(); !reflect.DeepEqual(got, []string{"ticker_LTC-BTC"}) {
+		t.Fatalf("subscriptions %v, want only ticker_LTC-BTC", got)
+	}
+
+	if err = stream.Unsubscribe(context.Background(), bittrex.TickerChannel("LTC-BTC")); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Subscriptions(); len(got) != 0 {
+		t.Fatalf("subscriptions %v after unsubscribe", got)
+	}
+}
+
+func TestStreamReconnects(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := s.Stream()
+	channel := bittrex.TickerChannel("LTC-BTC")
+	stream.Subscribe(context.Background(), channel)
+	runStream(t, stream)
+	waitFor(t, "subscription", subscribed(s, channel))
+
+	s.Disconnect()
+	select {
+	case err := <-stream.Errors():
+		if !strings.Contains(err.Error(), "disconnected") {
+			t.Fatalf("got %v, want a disconnection", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("disconnection not reported")
+	}
+	waitFor(t, "reconnection", func() bool { return s.Connections() == 2 && subscribed(s, channel)() })
+
+	s.Publish(channel, &bittrex.V3Ticker{Symbol: "LTC-BTC", LastTradeRate: 1})
+	select {
+	case got := <-stream.Tickers():
+		if got.LastTradeRate != 1 {
+			t.Fatalf("ticker %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no ticker after reconnection")
+	}
+}
+
+func TestStreamTimeout(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := s.Stream(bittrex.WithStreamTimeout(100 * time.Millisecond))
+	runStream(t, stream)
+	waitFor(t, "reconnection after silence", func() bool { return s.Connections() >= 2 })
+}
+
+func TestStreamRunClosesChannels(t *testing.T) {
+	s := bittrextest.NewStreamServer()
+	defer s.Close()
+	stream := s.Stream()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- stream.Run(ctx) }()
+	waitFor(t, "connection", func() bool { return s.Connections() == 1 })
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v", err)
+	}
+	if _, ok := <-stream.Tickers(); ok {
+		t.Fatal("tickers not closed")
+	}
+	if err := stream.Run(context.Background()); err == nil {
+		t.Fatal("second Run succeeded")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 // Used in getmarkethistory
 type Trade struct {
+	Id        int64
 	OrderUuid string
 	TimeStamp time.Time
 	Quantity  float64
@@ -18,6 +19,7 @@ type Trade struct {
 
 func (t *Trade) UnmarshalJSON(data []byte) (err error) {
 	s := struct {
+		Id        int64   `json:"Id"`
 		OrderUuid string  `json:"OrderUuid"`
 		TimeStamp string  `json:"TimeStamp"`
 		Quantity  float64 `json:"Quantity"`
@@ -37,6 +39,7 @@ func (t *Trade) UnmarshalJSON(data []byte) (err error) {
 		}
 	}
 	*t = Trade{
+		Id:        s.Id,
 		OrderUuid: s.OrderUuid,
 		TimeStamp: _t,
 		Quantity:  s.Quantity,
@@ -55,6 +58,7 @@ func (t Trade) MarshalJSON() ([]byte, error) {
 	}
 	return json.Marshal(
 		struct {
+			Id        int64   `json:"Id"`
 			OrderUuid string  `json:"OrderUuid"`
 			TimeStamp string  `json:"TimeStamp"`
 			Quantity  float64 `json:"Quantity"`
@@ -63,6 +67,7 @@ func (t Trade) MarshalJSON() ([]byte, error) {
 			FillType  string  `json:"FillType"`
 			OrderType string  `json:"OrderType"`
 		}{
+			Id:        t.Id,
 			OrderUuid: t.OrderUuid,
 			TimeStamp: _t,
 			Quantity:  t.Quantity,
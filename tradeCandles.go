@@ -0,0 +1,236 @@
+package bittrex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CandleUpdate is a change of the candle built by a TradeAggregator.
+type CandleUpdate struct {
+	Candle Candle
+	Closed bool // the candle is complete, the next updates are about a new one
+}
+
+// TradeAggregator builds candles from trades: time bars of a fixed interval,
+// tick bars of a fixed number of trades, or volume bars of a fixed quantity.
+//
+// Trades may be added more than once, as overlapping polls of
+// GetMarketHistory return them: they are recognized by their Id, by their
+// OrderUuid when Id is not set (v3 trade ids), or else by their time, price
+// and quantity. Trades older than the current candle, or than the end of
+// the last closed time bar, are dropped, as the candles are never amended
+// once closed.
+//
+// Time bars are aligned to UTC boundaries and stamped with their start time.
+// Intervals without trades have no candle. Tick and volume bars are stamped
+// with the time of their first trade.
+type TradeAggregator struct {
+	interval time.Duration // time bars
+	ticks    int           // tick bars
+	volume   float64       // volume bars
+
+	updates chan *CandleUpdate
+
+	mu         sync.Mutex
+	current    *Candle
+	count      int       // trades in current
+	end        time.Time // end of the current time bar
+	first      time.Time // time of the first trade of current
+	last       time.Time // time of the last trade of current
+	lastClosed time.Time // end of the last closed time bar, or time of the last trade of the last closed candle
+	seen       map[string]time.Time
+}
+
+// NewTimeAggregator returns an aggregator of time bars of interval, which
+// must divide a day or be a whole number of days, see ParseTimeframe.
+func NewTimeAggregator(interval time.Duration) *TradeAggregator {
+	if interval <= 0 {
+		panic("bittrex: aggregator interval must be positive")
+	}
+	return newTradeAggregator(&TradeAggregator{interval: interval})
+}
+
+// NewTickAggregator returns an aggregator of bars of trades trades.
+func NewTickAggregator(trades int) *TradeAggregator {
+	if trades <= 0 {
+		panic("bittrex: aggregator trade count must be positive")
+	}
+	return newTradeAggregator(&TradeAggregator{ticks: trades})
+}
+
+// NewVolumeAggregator returns an aggregator of bars closing once they hold
+// volume, in market currency. The trade reaching volume stays in its bar, so
+// bars may hold more than volume.
+func NewVolumeAggregator(volume float64) *TradeAggregator {
+	if volume <= 0 {
+		panic("bittrex: aggregator volume must be positive")
+	}
+	return newTradeAggregator(&TradeAggregator{volume: volume})
+}
+
+func newTradeAggregator(a *TradeAggregator) *TradeAggregator {
+	a.updates = make(chan *CandleUpdate, 256)
+	a.seen = map[string]time.Time{}
+	return a
+}
+
+// Updates returns the channel of the updates sent by Run: one per change of
+// the current candle, and one per closed candle. It is closed when Run returns.
+func (a *TradeAggregator) Updates() <-chan *CandleUpdate { return a.updates }
+
+// Current returns the candle being built, ok is false if there is none.
+func (a *TradeAggregator) Current() (candle Candle, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil {
+		return candle, false
+	}
+	return *a.current, true
+}
+
+// Add adds trades, in any order, and returns the candles they closed, oldest first.
+func (a *TradeAggregator) Add(trades ...*Trade) (closed []*Candle) {
+	sorted := append([]*Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].TimeStamp.Equal(sorted[j].TimeStamp) {
+			return sorted[i].TimeStamp.Before(sorted[j].TimeStamp)
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, t := range sorted {
+		key := tradeKey(t)
+		if _, ok := a.seen[key]; ok || t.TimeStamp.Before(a.lastClosed) {
+			continue
+		}
+		if a.current != nil && a.interval > 0 && !t.TimeStamp.Before(a.end) {
+			closed = append(closed, a.closeLocked())
+		}
+		if a.current != nil && t.TimeStamp.Before(a.current.TimeStamp) {
+			continue // older than the current time bar
+		}
+		a.seen[key] = t.TimeStamp
+		a.addLocked(t)
+		if (a.ticks > 0 && a.count >= a.ticks) || (a.volume > 0 && a.current.Volume >= a.volume-1e-12) {
+			closed = append(closed, a.closeLocked())
+		}
+	}
+	return closed
+}
+
+// Advance closes the current time bar if now is past its end, without
+// waiting for the first trade of the next one.
+func (a *TradeAggregator) Advance(now time.Time) *Candle {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current == nil || a.interval == 0 || now.Before(a.end) {
+		return nil
+	}
+	return a.closeLocked()
+}
+
+func (a *TradeAggregator) addLocked(t *Trade) {
+	total := t.Total
+	if total == 0 {
+		total = t.Quantity * t.Price
+	}
+	if a.current == nil {
+		start := t.TimeStamp
+		if a.interval > 0 {
+			start = t.TimeStamp.UTC().Truncate(a.interval)
+			a.end = start.Add(a.interval)
+		}
+		a.current = &Candle{TimeStamp: start, Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price}
+		a.first, a.last, a.count = t.TimeStamp, t.TimeStamp, 0
+	}
+	c := a.current
+	c.High = math.Max(c.High, t.Price)
+	c.Low = math.Min(c.Low, t.Price)
+	if t.TimeStamp.Before(a.first) {
+		c.Open, a.first = t.Price, t.TimeStamp
+	}
+	if !t.TimeStamp.Before(a.last) {
+		c.Close, a.last = t.Price, t.TimeStamp
+	}
+	c.Volume = round8(c.Volume + t.Quantity)
+	c.BaseVolume = round8(c.BaseVolume + total)
+	a.count++
+}
+
+// closeLocked closes the current candle and forgets the trades it can no longer receive.
+func (a *TradeAggregator) closeLocked() *Candle {
+	c := a.current
+	a.current = nil
+	a.lastClosed = a.last
+	if a.interval > 0 {
+		// A late trade of the interval must not open its candle again.
+		a.lastClosed = a.end
+	}
+	for key, at := range a.seen {
+		if at.Before(a.lastClosed) {
+			delete(a.seen, key)
+		}
+	}
+	return c
+}
+
+// tradeKey identifies a trade across polls.
+func tradeKey(t *Trade) string {
+	switch {
+	case t.Id != 0:
+		return "id:" + strconv.FormatInt(t.Id, 10)
+	case t.OrderUuid != "":
+		return "uuid:" + t.OrderUuid
+	}
+	return fmt.Sprintf("%d/%v/%v/%s", t.TimeStamp.UnixNano(), t.Price, t.Quantity, t.OrderType)
+}
+
+// Run polls the trades of market from source every interval, until ctx is
+// done, and sends the resulting updates on Updates. Failed polls are retried
+// on the next one. The update channel is closed when Run returns, so Run may
+// be called only once.
+func (a *TradeAggregator) Run(ctx context.Context, source MarketData, market string, interval time.Duration) error {
+	defer close(a.updates)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if trades, err := source.GetMarketHistoryCtx(ctx, market); err == nil {
+			before, _ := a.Current()
+			closed := a.Add(trades...)
+			if c := a.Advance(time.Now()); c != nil {
+				closed = append(closed, c)
+			}
+			for _, c := range closed {
+				if err = a.send(ctx, &CandleUpdate{Candle: *c, Closed: true}); err != nil {
+					return err
+				}
+			}
+			if c, ok := a.Current(); ok && (len(closed) > 0 || c != before) {
+				if err = a.send(ctx, &CandleUpdate{Candle: c}); err != nil {
+					return err
+				}
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (a *TradeAggregator) send(ctx context.Context, update *CandleUpdate) error {
+	select {
+	case a.updates <- update:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package bittrex_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+var tradesStart = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+// trade returns the trade id, seconds after tradesStart.
+func trade(id int64, seconds int, price, quantity float64) *bittrex.Trade {
+	return &bittrex.Trade{Id: id, TimeStamp: tradesStart.Add(time.Duration(seconds) * time.Second), Price: price, Quantity: quantity}
+}
+
+func TestTimeAggregator(t *testing.T) {
+	a := bittrex.NewTimeAggregator(5 * time.Minute)
+	// Out of order: the open is the earliest trade, the close the latest.
+	if closed := a.Add(trade(2, 60, 11, 1), trade(1, 0, 10, 2), trade(3, 120, 9, 1)); len(closed) != 0 {
+		t.Fatalf("closed %+v", closed)
+	}
+	c, ok := a.Current()
+	want := bittrex.Candle{TimeStamp: tradesStart, Open: 10, High: 11, Low: 9, Close: 9, Volume: 4, BaseVolume: 20 + 11 + 9}
+	if !ok || c != want {
+		t.Fatalf("current %+v, want %+v", c, want)
+	}
+
+	// A trade of the next interval closes the candle. The 10:10 interval,
+	// without trades, has no candle.
+	closed := a.Add(trade(4, 5*60, 12, 1), trade(5, 16*60, 13, 1))
+	if len(closed) != 2 || *closed[0] != want || closed[1].TimeStamp != tradesStart.Add(5*time.Minute) || closed[1].Close != 12 {
+		t.Fatalf("closed %+v", closed)
+	}
+	if c, _ = a.Current(); c.TimeStamp != tradesStart.Add(15*time.Minute) || c.Open != 13 {
+		t.Fatalf("current %+v", c)
+	}
+}
+
+func TestTradeAggregatorDedupe(t *testing.T) {
+	a := bittrex.NewTimeAggregator(time.Minute)
+	// Overlapping polls: by Id, by OrderUuid, or by time, price and quantity.
+	byUuid := &bittrex.Trade{OrderUuid: "u1", TimeStamp: tradesStart.Add(time.Second), Price: 10, Quantity: 1}
+	anonymous := &bittrex.Trade{TimeStamp: tradesStart.Add(2 * time.Second), Price: 10, Quantity: 1}
+	a.Add(trade(1, 0, 10, 1), byUuid, anonymous)
+	a.Add(trade(1, 0, 10, 1), byUuid, anonymous, trade(2, 3, 10, 1))
+	if c, _ := a.Current(); c.Volume != 4 {
+		t.Fatalf("volume %v, want 4 trades counted once", c.Volume)
+	}
+	// The same price and quantity at another time is another trade.
+	a.Add(&bittrex.Trade{TimeStamp: tradesStart.Add(4 * time.Second), Price: 10, Quantity: 1})
+	if c, _ := a.Current(); c.Volume != 5 {
+		t.Fatalf("volume %v, want 5", c.Volume)
+	}
+}
+
+func TestTimeAggregatorLateTrades(t *testing.T) {
+	a := bittrex.NewTimeAggregator(5 * time.Minute)
+	a.Add(trade(1, 10, 10, 1), trade(2, 100, 11, 1))
+	if a.Advance(tradesStart.Add(4*time.Minute)) != nil {
+		t.Fatal("closed before the end of the interval")
+	}
+	closed := a.Advance(tradesStart.Add(5*time.Minute + time.Second))
+	if closed == nil || closed.Volume != 2 {
+		t.Fatalf("Advance closed %+v", closed)
+	}
+
+	// A trade of the closed interval, after its last one, comes too late: it
+	// does not open the candle again.
+	if closed := a.Add(trade(3, 200, 12, 1)); len(closed) != 0 {
+		t.Fatalf("closed %+v", closed)
+	}
+	if c, ok := a.Current(); ok {
+		t.Fatalf("late trade reopened %+v", c)
+	}
+	// A trade on the end of the interval starts the next candle.
+	a.Add(trade(4, 5*60, 13, 1))
+	if c, ok := a.Current(); !ok || c.TimeStamp != tradesStart.Add(5*time.Minute) || c.Volume != 1 {
+		t.Fatalf("current %+v", c)
+	}
+	// Also too late: a trade older than the current candle.
+	a.Add(trade(5, 250, 14, 1))
+	if c, _ := a.Current(); c.Volume != 1 || c.High != 13 {
+		t.Fatalf("current %+v after an old trade", c)
+	}
+}
+
+func TestTickAndVolumeAggregators(t *testing.T) {
+	ticks := bittrex.NewTickAggregator(2)
+	closed := ticks.Add(trade(1, 0, 10, 1), trade(2, 1, 11, 1), trade(3, 2, 12, 1))
+	if len(closed) != 1 || closed[0].TimeStamp != tradesStart || closed[0].Close != 11 {
+		t.Fatalf("tick bars %+v", closed)
+	}
+	if ticks.Advance(tradesStart.Add(time.Hour)) != nil {
+		t.Fatal("Advance closed a tick bar")
+	}
+
+	volume := bittrex.NewVolumeAggregator(3)
+	// The trade reaching the volume stays in its bar.
+	closed = volume.Add(trade(1, 0, 10, 2), trade(2, 1, 11, 2), trade(3, 2, 12, 1))
+	if len(closed) != 1 || closed[0].Volume != 4 || closed[0].Close != 11 {
+		t.Fatalf("volume bars %+v", closed)
+	}
+	if c, _ := volume.Current(); c.TimeStamp != tradesStart.Add(2*time.Second) || c.Volume != 1 {
+		t.Fatalf("current %+v", c)
+	}
+}
+
+func TestTradeAggregatorRun(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetTrades("BTC-LTC", trade(2, 30, 0.011, 1), trade(1, 0, 0.01, 2))
+
+	a := bittrex.NewTimeAggregator(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx, s.Bittrex(), "BTC-LTC", time.Hour) }()
+
+	// The trades are long gone: their candle closes on the first poll.
+	select {
+	case u := <-a.Updates():
+		if !u.Closed || u.Candle.Open != 0.01 || u.Candle.Close != 0.011 || u.Candle.Volume != 3 {
+			t.Fatalf("update %+v", u)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, ok := <-a.Updates(); ok {
+		t.Fatal("updates not closed")
+	}
+}
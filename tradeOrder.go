@@ -0,0 +1,164 @@
+package bittrex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Time in force of a TradeOrder.
+const (
+	GoodTilCancelled  = "GOOD_TIL_CANCELLED"
+	ImmediateOrCancel = "IMMEDIATE_OR_CANCEL"
+	FillOrKill        = "FILL_OR_KILL"
+)
+
+// Conditions of a TradeOrder: the order is placed once the last price of the
+// market is greater, or less, than or equal to the target.
+const (
+	ConditionNone        = "NONE"
+	ConditionGreaterThan = "GREATER_THAN" // ex: a buy stop, or a sell take-profit
+	ConditionLessThan    = "LESS_THAN"    // ex: a sell stop-loss
+)
+
+// TradeOrder is an order placed through the extended trade endpoint, which
+// supports conditions and time in force.
+type TradeOrder struct {
+	Market      string // ex: BTC-LTC
+	Type        string // LIMIT or MARKET, defaults to LIMIT
+	Quantity    float64
+	Rate        float64 // limit, ignored for market orders
+	TimeInForce string  // GoodTilCancelled (default), ImmediateOrCancel or FillOrKill
+	Condition   string  // ConditionNone (default), ConditionGreaterThan or ConditionLessThan
+	Target      float64 // price triggering a conditional order
+}
+
+// normalize returns o with its defaults set and upper cased, or an error if it is invalid.
+func (o TradeOrder) normalize() (TradeOrder, error) {
+	t := o
+	t.Market = strings.ToUpper(o.Market)
+	t.Type, t.TimeInForce, t.Condition = "LIMIT", GoodTilCancelled, ConditionNone
+	if o.Type != "" {
+		t.Type = strings.ToUpper(o.Type)
+	}
+	if o.TimeInForce != "" {
+		t.TimeInForce = strings.ToUpper(o.TimeInForce)
+	}
+	if o.Condition != "" {
+		t.Condition = strings.ToUpper(o.Condition)
+	}
+	switch {
+	case t.Type != "LIMIT" && t.Type != "MARKET":
+		return t, errors.New("bittrex: order type must be LIMIT or MARKET")
+	case t.TimeInForce != GoodTilCancelled && t.TimeInForce != ImmediateOrCancel && t.TimeInForce != FillOrKill:
+		return t, errors.New("bittrex: invalid time in force " + o.TimeInForce)
+	case t.Condition != ConditionNone && t.Condition != ConditionGreaterThan && t.Condition != ConditionLessThan:
+		return t, errors.New("bittrex: invalid condition " + o.Condition)
+	case t.Condition != ConditionNone && o.Target <= 0:
+		return t, errors.New("bittrex: a conditional order needs a target")
+	}
+	return t, nil
+}
+
+// query returns the parameters of the v2.0 trade request of o, a normalized order.
+func (o *TradeOrder) query() url.Values {
+	q := url.Values{}
+	q.Set("MarketName", o.Market)
+	q.Set("OrderType", o.Type)
+	q.Set("Quantity", strconv.FormatFloat(o.Quantity, 'f', 8, 64))
+	q.Set("Rate", strconv.FormatFloat(o.Rate, 'f', 8, 64))
+	q.Set("TimeInEffect", o.TimeInForce)
+	q.Set("ConditionType", o.Condition)
+	q.Set("Target", strconv.FormatFloat(o.Target, 'f', 8, 64))
+	return q
+}
+
+// tradeResult is the answer of the v2.0 trade endpoints.
+type tradeResult struct {
+	OrderId        string
+	MarketName     string
+	MarketCurrency string
+	BuyOrSell      string
+	OrderType      string
+	Quantity       float64
+	Rate           float64
+}
+
+// TradeBuy places a buy order with a condition and a time in force. It uses
+// the v2.0 API, or with WithAPIV3 a v3 order, and a v3 conditional order for a
+// conditional one. The returned Order is built from the request and the
+// answer: GetOrder gives its state later on. With v3, the OrderUuid of a
+// conditional order is the id of the conditional order: the order it creates
+// once triggered gets its own.
+func (b *Bittrex) TradeBuy(order TradeOrder) (*Order, error) {
+	return b.TradeBuyCtx(context.Background(), order)
+}
+
+// TradeBuyCtx is like TradeBuy but carries ctx to the underlying HTTP request.
+func (b *Bittrex) TradeBuyCtx(ctx context.Context, order TradeOrder) (*Order, error) {
+	return b.trade(ctx, "key/market/TradeBuy", "BUY", order)
+}
+
+// TradeSell places a sell order with a condition and a time in force. See TradeBuy.
+func (b *Bittrex) TradeSell(order TradeOrder) (*Order, error) {
+	return b.TradeSellCtx(context.Background(), order)
+}
+
+// TradeSellCtx is like TradeSell but carries ctx to the underlying HTTP request.
+func (b *Bittrex) TradeSellCtx(ctx context.Context, order TradeOrder) (*Order, error) {
+	return b.trade(ctx, "key/market/TradeSell", "SELL", order)
+}
+
+func (b *Bittrex) trade(ctx context.Context, ressource, side string, order TradeOrder) (*Order, error) {
+	t, err := order.normalize()
+	if err != nil {
+		return nil, err
+	}
+	o := &Order{
+		Exchange:          t.Market,
+		Type:              t.Type + "_" + side,
+		Quantity:          t.Quantity,
+		QuantityRemaining: t.Quantity,
+		Limit:             t.Rate,
+		IsOpen:            true,
+		ImmediateOrCancel: t.TimeInForce != GoodTilCancelled,
+		IsConditional:     t.Condition != ConditionNone,
+		Condition:         t.Condition,
+	}
+	if o.IsConditional {
+		o.ConditionTarget = strconv.FormatFloat(t.Target, 'f', -1, 64)
+	}
+	if b.client.useV3 {
+		if o.OrderUuid, err = b.v3Trade(ctx, side, t); err != nil {
+			return nil, err
+		}
+		return o, nil
+	}
+
+	r, err := b.client.do(ctx, "POST", b.client.v2(ressource+"?"+t.query().Encode()), "", true)
+	if err != nil {
+		return nil, err
+	}
+	var response jsonResponse
+	if err = json.Unmarshal(r, &response); err != nil {
+		return nil, err
+	}
+	var result tradeResult
+	if err = json.Unmarshal(response.Result, &result); err != nil {
+		return nil, err
+	}
+	o.OrderUuid = result.OrderId
+	if result.MarketName != "" {
+		o.Exchange = result.MarketName
+	}
+	if result.Quantity != 0 {
+		o.Quantity, o.QuantityRemaining = result.Quantity, result.Quantity
+	}
+	if result.Rate != 0 {
+		o.Limit = result.Rate
+	}
+	return o, nil
+}
@@ -0,0 +1,76 @@
+package bittrex_test
+
+import (
+	"testing"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+func TestTradeOrder(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Bid: 0.009, Ask: 0.011, Last: 0.01})
+	s.SetBalance("BTC", 1)
+	s.SetBalance("LTC", 10)
+	b := s.Bittrex()
+
+	stop, err := b.TradeSell(bittrex.TradeOrder{Market: "btc-ltc", Quantity: 2, Rate: 0.008, Condition: "less_than", Target: 0.0085})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stop.Exchange != "BTC-LTC" || stop.Type != "LIMIT_SELL" || !stop.IsConditional || stop.Condition != bittrex.ConditionLessThan || stop.ConditionTarget != "0.0085" {
+		t.Fatalf("order %+v", stop)
+	}
+	o, ok := s.Order(stop.OrderUuid)
+	if !ok || !o.IsOpen || !o.IsConditional || o.Condition != bittrex.ConditionLessThan || o.ConditionTarget != "0.0085" || o.Quantity != 2 {
+		t.Fatalf("server order %+v", o)
+	}
+
+	// A limit order does not cross on placement: an immediate-or-cancel one closes right away.
+	ioc, err := b.TradeBuy(bittrex.TradeOrder{Market: "BTC-LTC", Quantity: 1, Rate: 0.01, TimeInForce: bittrex.ImmediateOrCancel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ioc.ImmediateOrCancel || ioc.IsConditional || ioc.Type != "LIMIT_BUY" {
+		t.Fatalf("order %+v", ioc)
+	}
+	if o, _ = s.Order(ioc.OrderUuid); o.IsOpen || !o.ImmediateOrCancel {
+		t.Fatalf("server order %+v", o)
+	}
+	if btc := s.Balance("BTC"); btc.Available != 1 {
+		t.Fatalf("BTC %+v after the immediate-or-cancel order", btc)
+	}
+
+	for _, invalid := range []bittrex.TradeOrder{
+		{Market: "BTC-LTC", Quantity: 1, Type: "STOP"},
+		{Market: "BTC-LTC", Quantity: 1, TimeInForce: "FOREVER"},
+		{Market: "BTC-LTC", Quantity: 1, Condition: "EQUAL", Target: 1},
+		{Market: "BTC-LTC", Quantity: 1, Condition: bittrex.ConditionGreaterThan},
+	} {
+		if _, err = b.TradeBuy(invalid); err == nil {
+			t.Errorf("%+v: no error", invalid)
+		}
+	}
+	if got := s.Requests(); len(got) != 2 || got[0] != "key/market/TradeSell" || got[1] != "key/market/TradeBuy" {
+		t.Fatalf("requests %v", got)
+	}
+}
+
+func TestTradeOrderV3DoesNotCallV2(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetTicker("BTC-LTC", &bittrex.Ticker{Last: 0.01})
+	s.SetBalance("BTC", 1)
+	// The fake server answers v1.1 and v2.0 only: a v3 client gets a 404.
+	b := s.Bittrex(bittrex.WithAPIV3(), bittrex.WithV3BaseURL(s.URL+"/v3"))
+	if _, err := b.TradeBuy(bittrex.TradeOrder{Market: "BTC-LTC", Quantity: 1, Rate: 0.01, Condition: bittrex.ConditionGreaterThan, Target: 0.02}); err == nil {
+		t.Fatal("no error from the v3 routes")
+	}
+	if got := s.Requests(); len(got) != 0 {
+		t.Fatalf("a v3 client called %v", got)
+	}
+	if orders := s.Orders(); len(orders) != 0 {
+		t.Fatalf("orders %+v", orders)
+	}
+}
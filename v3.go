@@ -0,0 +1,267 @@
+package bittrex
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// V3 gives access to the Bittrex v3 REST API. Markets are named the v3 way,
+// market currency first (ex: LTC-BTC), and every call takes a context.
+type V3 struct {
+	client *client
+}
+
+// V3 returns the v3 API sharing b's credentials, http client, retry policy and rate limiter.
+func (b *Bittrex) V3() *V3 {
+	return &V3{b.client}
+}
+
+// V3Symbol converts a v1.1 market name (ex: BTC-LTC) to a v3 market symbol (ex: LTC-BTC).
+// As both formats are two currencies joined by a dash, it also converts the other way round.
+func V3Symbol(market string) string {
+	parts := strings.SplitN(strings.ToUpper(market), "-", 2)
+	if len(parts) != 2 {
+		return strings.ToUpper(market)
+	}
+	return parts[1] + "-" + parts[0]
+}
+
+// withQuery appends the non empty values of params to ressource.
+func withQuery(ressource string, params ...string) string {
+	q := url.Values{}
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] != "" {
+			q.Set(params[i], params[i+1])
+		}
+	}
+	if len(q) == 0 {
+		return ressource
+	}
+	return ressource + "?" + q.Encode()
+}
+
+// Market data
+
+// GetMarkets is used to get the list of markets.
+func (v *V3) GetMarkets(ctx context.Context) ([]*V3Market, error) {
+	markets := []*V3Market{}
+	_, err := v.client.doV3(ctx, "GET", "markets", nil, &markets, false)
+	return markets, err
+}
+
+// GetCurrencies is used to get the list of currencies.
+func (v *V3) GetCurrencies(ctx context.Context) ([]*V3Currency, error) {
+	currencies := []*V3Currency{}
+	_, err := v.client.doV3(ctx, "GET", "currencies", nil, &currencies, false)
+	return currencies, err
+}
+
+// GetMarketSummaries is used to get the last 24 hour summary of every market.
+func (v *V3) GetMarketSummaries(ctx context.Context) ([]*V3MarketSummary, error) {
+	summaries := []*V3MarketSummary{}
+	_, err := v.client.doV3(ctx, "GET", "markets/summaries", nil, &summaries, false)
+	return summaries, err
+}
+
+// GetMarketSummary is used to get the last 24 hour summary of a market.
+func (v *V3) GetMarketSummary(ctx context.Context, symbol string) (*V3MarketSummary, error) {
+	summary := V3MarketSummary{}
+	_, err := v.client.doV3(ctx, "GET", "markets/"+url.PathEscape(strings.ToUpper(symbol))+"/summary", nil, &summary, false)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetTickers is used to get the ticker of every market.
+func (v *V3) GetTickers(ctx context.Context) ([]*V3Ticker, error) {
+	tickers := []*V3Ticker{}
+	_, err := v.client.doV3(ctx, "GET", "markets/tickers", nil, &tickers, false)
+	return tickers, err
+}
+
+// GetTicker is used to get the current ticker of a market.
+func (v *V3) GetTicker(ctx context.Context, symbol string) (*V3Ticker, error) {
+	ticker := V3Ticker{}
+	_, err := v.client.doV3(ctx, "GET", "markets/"+url.PathEscape(strings.ToUpper(symbol))+"/ticker", nil, &ticker, false)
+	if err != nil {
+		return nil, err
+	}
+	return &ticker, nil
+}
+
+// GetOrderBook is used to get the order book of a market.
+// depth: 1, 25 or 500, other values are rounded up to the next allowed one.
+func (v *V3) GetOrderBook(ctx context.Context, symbol string, depth int) (*V3OrderBook, error) {
+	book := V3OrderBook{}
+	ressource := withQuery("markets/"+url.PathEscape(strings.ToUpper(symbol))+"/orderbook", "depth", strconv.Itoa(v3BookDepth(depth)))
+	header, err := v.client.doV3(ctx, "GET", ressource, nil, &book, false)
+	if err != nil {
+		return nil, err
+	}
+	book.Sequence, _ = strconv.ParseInt(header.Get("Sequence"), 10, 64)
+	return &book, nil
+}
+
+// v3BookDepth rounds depth up to the next order book depth allowed by v3.
+func v3BookDepth(depth int) int {
+	switch {
+	case depth <= 1:
+		return 1
+	case depth <= 25:
+		return 25
+	}
+	return 500
+}
+
+// GetTrades is used to get the latest trades of a market.
+func (v *V3) GetTrades(ctx context.Context, symbol string) ([]*V3Trade, error) {
+	trades := []*V3Trade{}
+	_, err := v.client.doV3(ctx, "GET", "markets/"+url.PathEscape(strings.ToUpper(symbol))+"/trades", nil, &trades, false)
+	return trades, err
+}
+
+// GetCandles is used to get the recent candles of a market.
+func (v *V3) GetCandles(ctx context.Context, symbol string, interval V3CandleInterval) ([]*V3Candle, error) {
+	candles := []*V3Candle{}
+	ressource := "markets/" + url.PathEscape(strings.ToUpper(symbol)) + "/candles/" + string(interval) + "/recent"
+	_, err := v.client.doV3(ctx, "GET", ressource, nil, &candles, false)
+	return candles, err
+}
+
+// Orders
+
+// PlaceOrder is used to create an order.
+func (v *V3) PlaceOrder(ctx context.Context, order V3NewOrder) (*V3Order, error) {
+	created := V3Order{}
+	if _, err := v.client.doV3(ctx, "POST", "orders", order, &created, true); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetOrder is used to retrieve a single order.
+func (v *V3) GetOrder(ctx context.Context, orderID string) (*V3Order, error) {
+	order := V3Order{}
+	if _, err := v.client.doV3(ctx, "GET", "orders/"+url.PathEscape(orderID), nil, &order, true); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CancelOrder is used to cancel an open order.
+func (v *V3) CancelOrder(ctx context.Context, orderID string) (*V3Order, error) {
+	order := V3Order{}
+	if _, err := v.client.doV3(ctx, "DELETE", "orders/"+url.PathEscape(orderID), nil, &order, true); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// PlaceConditionalOrder is used to create a conditional order.
+func (v *V3) PlaceConditionalOrder(ctx context.Context, order V3NewConditionalOrder) (*V3ConditionalOrder, error) {
+	created := V3ConditionalOrder{}
+	if _, err := v.client.doV3(ctx, "POST", "conditional-orders", order, &created, true); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetOpenOrders is used to list open orders. If symbol is empty, orders of every market are returned.
+func (v *V3) GetOpenOrders(ctx context.Context, symbol string) ([]*V3Order, error) {
+	orders := []*V3Order{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("orders/open", "marketSymbol", strings.ToUpper(symbol)), nil, &orders, true)
+	return orders, err
+}
+
+// GetClosedOrders is used to list closed orders. If symbol is empty, orders of every market are returned.
+func (v *V3) GetClosedOrders(ctx context.Context, symbol string) ([]*V3Order, error) {
+	orders := []*V3Order{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("orders/closed", "marketSymbol", strings.ToUpper(symbol)), nil, &orders, true)
+	return orders, err
+}
+
+// Account
+
+// GetBalances is used to retrieve all balances from your account.
+func (v *V3) GetBalances(ctx context.Context) ([]*V3Balance, error) {
+	balances := []*V3Balance{}
+	_, err := v.client.doV3(ctx, "GET", "balances", nil, &balances, true)
+	return balances, err
+}
+
+// GetBalance is used to retrieve the balance of a currency (ex: BTC).
+func (v *V3) GetBalance(ctx context.Context, currency string) (*V3Balance, error) {
+	balance := V3Balance{}
+	if _, err := v.client.doV3(ctx, "GET", "balances/"+url.PathEscape(strings.ToUpper(currency)), nil, &balance, true); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// GetOpenDeposits is used to list pending deposits. If currency is empty, every currency is returned.
+func (v *V3) GetOpenDeposits(ctx context.Context, currency string) ([]*V3Deposit, error) {
+	deposits := []*V3Deposit{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("deposits/open", "currencySymbol", strings.ToUpper(currency)), nil, &deposits, true)
+	return deposits, err
+}
+
+// GetClosedDeposits is used to list completed deposits. If currency is empty, every currency is returned.
+func (v *V3) GetClosedDeposits(ctx context.Context, currency string) ([]*V3Deposit, error) {
+	deposits := []*V3Deposit{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("deposits/closed", "currencySymbol", strings.ToUpper(currency)), nil, &deposits, true)
+	return deposits, err
+}
+
+// Withdraw is used to withdraw funds from your account.
+func (v *V3) Withdraw(ctx context.Context, withdrawal V3NewWithdrawal) (*V3Withdrawal, error) {
+	created := V3Withdrawal{}
+	if _, err := v.client.doV3(ctx, "POST", "withdrawals", withdrawal, &created, true); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetOpenWithdrawals is used to list pending withdrawals. If currency is empty, every currency is returned.
+func (v *V3) GetOpenWithdrawals(ctx context.Context, currency string) ([]*V3Withdrawal, error) {
+	withdrawals := []*V3Withdrawal{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("withdrawals/open", "currencySymbol", strings.ToUpper(currency)), nil, &withdrawals, true)
+	return withdrawals, err
+}
+
+// GetClosedWithdrawals is used to list completed withdrawals. If currency is empty, every currency is returned.
+func (v *V3) GetClosedWithdrawals(ctx context.Context, currency string) ([]*V3Withdrawal, error) {
+	withdrawals := []*V3Withdrawal{}
+	_, err := v.client.doV3(ctx, "GET", withQuery("withdrawals/closed", "currencySymbol", strings.ToUpper(currency)), nil, &withdrawals, true)
+	return withdrawals, err
+}
+
+// GetAddresses is used to list the deposit addresses of your account.
+func (v *V3) GetAddresses(ctx context.Context) ([]*V3Address, error) {
+	addresses := []*V3Address{}
+	_, err := v.client.doV3(ctx, "GET", "addresses", nil, &addresses, true)
+	return addresses, err
+}
+
+// GetAddress is used to retrieve the deposit address of a currency.
+func (v *V3) GetAddress(ctx context.Context, currency string) (*V3Address, error) {
+	address := V3Address{}
+	if _, err := v.client.doV3(ctx, "GET", "addresses/"+url.PathEscape(strings.ToUpper(currency)), nil, &address, true); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// ProvisionAddress is used to request a new deposit address for a currency.
+func (v *V3) ProvisionAddress(ctx context.Context, currency string) (*V3Address, error) {
+	address := V3Address{}
+	body := struct {
+		CurrencySymbol string `json:"currencySymbol"`
+	}{strings.ToUpper(currency)}
+	if _, err := v.client.doV3(ctx, "POST", "addresses", body, &address, true); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
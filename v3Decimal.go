@@ -0,0 +1,298 @@
+package bittrex
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file backs the Decimal flavoured Bittrex methods by the v3 API, see
+// v3compat.go. The v3 models are mirrored with Decimal fields, so that the
+// strings sent by v3 are decoded, and the quantities and rates sent to v3
+// encoded, without going through float64.
+
+type v3DecimalTicker struct {
+	Symbol        string  `json:"symbol"`
+	LastTradeRate Decimal `json:"lastTradeRate"`
+	BidRate       Decimal `json:"bidRate"`
+	AskRate       Decimal `json:"askRate"`
+}
+
+type v3DecimalMarketSummary struct {
+	Symbol        string    `json:"symbol"`
+	High          Decimal   `json:"high"`
+	Low           Decimal   `json:"low"`
+	Volume        Decimal   `json:"volume"`
+	QuoteVolume   Decimal   `json:"quoteVolume"`
+	PercentChange Decimal   `json:"percentChange"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type v3DecimalOrderBookEntry struct {
+	Quantity Decimal `json:"quantity"`
+	Rate     Decimal `json:"rate"`
+}
+
+type v3DecimalOrderBook struct {
+	Bid []v3DecimalOrderBookEntry `json:"bid"`
+	Ask []v3DecimalOrderBookEntry `json:"ask"`
+}
+
+type v3DecimalOrder struct {
+	ID           string    `json:"id"`
+	MarketSymbol string    `json:"marketSymbol"`
+	Direction    string    `json:"direction"`
+	Type         string    `json:"type"`
+	Quantity     Decimal   `json:"quantity"`
+	Limit        Decimal   `json:"limit"`
+	TimeInForce  string    `json:"timeInForce"`
+	FillQuantity Decimal   `json:"fillQuantity"`
+	Commission   Decimal   `json:"commission"`
+	Proceeds     Decimal   `json:"proceeds"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"createdAt"`
+	ClosedAt     time.Time `json:"closedAt"`
+}
+
+type v3DecimalBalance struct {
+	CurrencySymbol string  `json:"currencySymbol"`
+	Total          Decimal `json:"total"`
+	Available      Decimal `json:"available"`
+}
+
+type v3DecimalCandle struct {
+	StartsAt    time.Time `json:"startsAt"`
+	Open        Decimal   `json:"open"`
+	High        Decimal   `json:"high"`
+	Low         Decimal   `json:"low"`
+	Close       Decimal   `json:"close"`
+	Volume      Decimal   `json:"volume"`
+	QuoteVolume Decimal   `json:"quoteVolume"`
+}
+
+// v3Order returns o with the fields v3OrderType and v3TimeFormat look at.
+func (o *v3DecimalOrder) v3Order() *V3Order {
+	return &V3Order{Direction: o.Direction, Type: o.Type, CreatedAt: o.CreatedAt, ClosedAt: o.ClosedAt}
+}
+
+func (o *v3DecimalOrder) pricePerUnit() Decimal {
+	if o.FillQuantity.IsZero() {
+		return Decimal{}
+	}
+	return o.Proceeds.Div(o.FillQuantity, 8)
+}
+
+func (o *v3DecimalOrder) toOrderHistory() *DecimalOrderHistory {
+	return &DecimalOrderHistory{
+		OrderUuid:         o.ID,
+		Exchange:          V3Symbol(o.MarketSymbol),
+		TimeStamp:         o.CreatedAt.UTC(),
+		OrderType:         v3OrderType(o.v3Order()),
+		Limit:             o.Limit,
+		Quantity:          o.Quantity,
+		QuantityRemaining: o.Quantity.Sub(o.FillQuantity),
+		Commission:        o.Commission,
+		Price:             o.Proceeds,
+		PricePerUnit:      o.pricePerUnit(),
+	}
+}
+
+func (o *v3DecimalOrder) toOrder() *DecimalOrder {
+	return &DecimalOrder{
+		OrderUuid:         o.ID,
+		Exchange:          V3Symbol(o.MarketSymbol),
+		Type:              v3OrderType(o.v3Order()),
+		Quantity:          o.Quantity,
+		QuantityRemaining: o.Quantity.Sub(o.FillQuantity),
+		Limit:             o.Limit,
+		CommissionPaid:    o.Commission,
+		Price:             o.Proceeds,
+		PricePerUnit:      o.pricePerUnit(),
+		Opened:            v3TimeFormat(o.v3Order(), false),
+		Closed:            v3TimeFormat(o.v3Order(), true),
+		IsOpen:            o.Status == "OPEN",
+		ImmediateOrCancel: o.TimeInForce == V3ImmediateOrCancel || o.TimeInForce == V3FillOrKill,
+	}
+}
+
+func v3ToDecimalOrderbs(entries []v3DecimalOrderBookEntry, depth int) []DecimalOrderb {
+	if len(entries) > depth {
+		entries = entries[:depth]
+	}
+	orderbs := make([]DecimalOrderb, len(entries))
+	for i, e := range entries {
+		orderbs[i] = DecimalOrderb{Quantity: e.Quantity, Rate: e.Rate}
+	}
+	return orderbs
+}
+
+var decimalHundred = NewDecimalFromInt(100)
+
+func v3ToDecimalMarketSummary(s *v3DecimalMarketSummary, t *v3DecimalTicker) *DecimalMarketSummary {
+	summary := &DecimalMarketSummary{
+		MarketName: V3Symbol(s.Symbol),
+		High:       s.High,
+		Low:        s.Low,
+		Volume:     s.Volume,
+		BaseVolume: s.QuoteVolume,
+	}
+	if !s.UpdatedAt.IsZero() {
+		summary.TimeStamp = s.UpdatedAt.UTC().Format(TIME_FORMAT)
+	}
+	if t != nil {
+		summary.Bid = t.BidRate
+		summary.Ask = t.AskRate
+		summary.Last = t.LastTradeRate
+		// PrevDay = Last / (1 + PercentChange/100)
+		if base := decimalHundred.Add(s.PercentChange); !base.IsZero() {
+			summary.PrevDay = t.LastTradeRate.Mul(decimalHundred).Div(base, 8)
+		}
+	}
+	return summary
+}
+
+func v3ToDecimalBalance(b *v3DecimalBalance) *DecimalBalance {
+	return &DecimalBalance{Currency: b.CurrencySymbol, Balance: b.Total, Available: b.Available}
+}
+
+func v3ToDecimalOrderHistories(v3Orders []*v3DecimalOrder) []*DecimalOrderHistory {
+	orders := make([]*DecimalOrderHistory, len(v3Orders))
+	for i, o := range v3Orders {
+		orders[i] = o.toOrderHistory()
+	}
+	return orders
+}
+
+func (b *Bittrex) v3GetTickerDecimal(ctx context.Context, market string) (*DecimalTicker, error) {
+	t := v3DecimalTicker{}
+	if _, err := b.client.doV3(ctx, "GET", "markets/"+url.PathEscape(V3Symbol(market))+"/ticker", nil, &t, false); err != nil {
+		return nil, err
+	}
+	return &DecimalTicker{Bid: t.BidRate, Ask: t.AskRate, Last: t.LastTradeRate}, nil
+}
+
+func (b *Bittrex) v3GetMarketSummariesDecimal(ctx context.Context) ([]*DecimalMarketSummary, error) {
+	summaries := []*v3DecimalMarketSummary{}
+	if _, err := b.client.doV3(ctx, "GET", "markets/summaries", nil, &summaries, false); err != nil {
+		return nil, err
+	}
+	tickers := []*v3DecimalTicker{}
+	if _, err := b.client.doV3(ctx, "GET", "markets/tickers", nil, &tickers, false); err != nil {
+		return nil, err
+	}
+	bySymbol := make(map[string]*v3DecimalTicker, len(tickers))
+	for _, t := range tickers {
+		bySymbol[t.Symbol] = t
+	}
+	marketSummaries := make([]*DecimalMarketSummary, len(summaries))
+	for i, s := range summaries {
+		marketSummaries[i] = v3ToDecimalMarketSummary(s, bySymbol[s.Symbol])
+	}
+	return marketSummaries, nil
+}
+
+func (b *Bittrex) v3GetOrderBookDecimal(ctx context.Context, market string, depth int) (*DecimalOrderBook, error) {
+	book := v3DecimalOrderBook{}
+	ressource := withQuery("markets/"+url.PathEscape(V3Symbol(market))+"/orderbook", "depth", strconv.Itoa(v3BookDepth(depth)))
+	if _, err := b.client.doV3(ctx, "GET", ressource, nil, &book, false); err != nil {
+		return nil, err
+	}
+	return &DecimalOrderBook{Buy: v3ToDecimalOrderbs(book.Bid, depth), Sell: v3ToDecimalOrderbs(book.Ask, depth)}, nil
+}
+
+func (b *Bittrex) v3PlaceOrderDecimal(ctx context.Context, market, direction, orderType string, quantity, rate Decimal) (string, error) {
+	body := v3OrderBody{
+		MarketSymbol: V3Symbol(market),
+		Direction:    direction,
+		Type:         orderType,
+		Quantity:     quantity.String(),
+		TimeInForce:  V3GoodTilCancelled,
+	}
+	if orderType == V3OrderTypeMarket {
+		body.TimeInForce = V3ImmediateOrCancel
+	} else {
+		body.Limit = rate.String()
+	}
+	created := v3DecimalOrder{}
+	if _, err := b.client.doV3(ctx, "POST", "orders", body, &created, true); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// v3GetOrdersDecimal lists the open or closed orders of market, "all" for every market.
+func (b *Bittrex) v3GetOrdersDecimal(ctx context.Context, state, market string) ([]*DecimalOrderHistory, error) {
+	symbol := ""
+	if market != "all" {
+		symbol = V3Symbol(market)
+	}
+	orders := []*v3DecimalOrder{}
+	if _, err := b.client.doV3(ctx, "GET", withQuery("orders/"+state, "marketSymbol", symbol), nil, &orders, true); err != nil {
+		return nil, err
+	}
+	return v3ToDecimalOrderHistories(orders), nil
+}
+
+func (b *Bittrex) v3GetOrderDecimal(ctx context.Context, orderID string) (*DecimalOrder, error) {
+	o := v3DecimalOrder{}
+	if _, err := b.client.doV3(ctx, "GET", "orders/"+url.PathEscape(orderID), nil, &o, true); err != nil {
+		return nil, err
+	}
+	return o.toOrder(), nil
+}
+
+func (b *Bittrex) v3GetBalancesDecimal(ctx context.Context) ([]*DecimalBalance, error) {
+	v3Balances := []*v3DecimalBalance{}
+	if _, err := b.client.doV3(ctx, "GET", "balances", nil, &v3Balances, true); err != nil {
+		return nil, err
+	}
+	balances := make([]*DecimalBalance, len(v3Balances))
+	for i, balance := range v3Balances {
+		balances[i] = v3ToDecimalBalance(balance)
+	}
+	return balances, nil
+}
+
+func (b *Bittrex) v3GetBalanceDecimal(ctx context.Context, currency string) (*DecimalBalance, error) {
+	balance := v3DecimalBalance{}
+	if _, err := b.client.doV3(ctx, "GET", "balances/"+url.PathEscape(strings.ToUpper(currency)), nil, &balance, true); err != nil {
+		return nil, err
+	}
+	return v3ToDecimalBalance(&balance), nil
+}
+
+func (b *Bittrex) v3WithdrawDecimal(ctx context.Context, address, currency string, quantity Decimal) (string, error) {
+	body := v3WithdrawalBody{
+		CurrencySymbol: strings.ToUpper(currency),
+		Quantity:       quantity.String(),
+		CryptoAddress:  address,
+	}
+	created := V3Withdrawal{}
+	if _, err := b.client.doV3(ctx, "POST", "withdrawals", body, &created, true); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (b *Bittrex) v3GetTicksDecimal(ctx context.Context, market string, interval V3CandleInterval) ([]*DecimalCandle, error) {
+	v3Candles := []*v3DecimalCandle{}
+	ressource := "markets/" + url.PathEscape(V3Symbol(market)) + "/candles/" + string(interval) + "/recent"
+	if _, err := b.client.doV3(ctx, "GET", ressource, nil, &v3Candles, false); err != nil {
+		return nil, err
+	}
+	candles := make([]*DecimalCandle, len(v3Candles))
+	for i, c := range v3Candles {
+		candles[i] = &DecimalCandle{
+			TimeStamp:  c.StartsAt.UTC(),
+			Open:       c.Open,
+			Close:      c.Close,
+			High:       c.High,
+			Low:        c.Low,
+			Volume:     c.Volume,
+			BaseVolume: c.QuoteVolume,
+		}
+	}
+	return candles, nil
+}
@@ -0,0 +1,125 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// v3Server answers every v3 request with the JSON of routes[method+" "+path],
+// and records the bodies it receives.
+func v3Server(t *testing.T, routes map[string]string) (*Bittrex, map[string]string) {
+	t.Helper()
+	bodies := map[string]string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		bodies[key] = string(body)
+		answer, ok := routes[key]
+		if !ok {
+			http.Error(w, `{"code":"NOT_FOUND"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, answer)
+	}))
+	t.Cleanup(s.Close)
+	return New("key", "secret", WithAPIV3(), WithV3BaseURL(s.URL), WithHTTPClient(s.Client())), bodies
+}
+
+func TestV3DecimalOrdersAreExact(t *testing.T) {
+	b, bodies := v3Server(t, map[string]string{
+		"POST /orders":      `{"id":"o1"}`,
+		"POST /withdrawals": `{"id":"w1"}`,
+	})
+	// 17 significant digits do not survive a float64, nor more than 8 decimals 'f' 8.
+	quantity, rate := MustDecimal("12345678.123456789"), MustDecimal("0.000000001")
+	id, err := b.BuyLimitDecimal("BTC-LTC", quantity, rate)
+	if err != nil || id != "o1" {
+		t.Fatalf("got %q, %v", id, err)
+	}
+	var order map[string]string
+	if err = json.Unmarshal([]byte(bodies["POST /orders"]), &order); err != nil {
+		t.Fatal(err)
+	}
+	if order["marketSymbol"] != "LTC-BTC" || order["direction"] != V3DirectionBuy || order["type"] != V3OrderTypeLimit ||
+		order["quantity"] != "12345678.123456789" || order["limit"] != "0.000000001" || order["timeInForce"] != V3GoodTilCancelled {
+		t.Fatalf("order body %s", bodies["POST /orders"])
+	}
+
+	if _, err = b.SellMarketDecimal("BTC-LTC", quantity); err != nil {
+		t.Fatal(err)
+	}
+	order = nil
+	json.Unmarshal([]byte(bodies["POST /orders"]), &order)
+	if _, ok := order["limit"]; ok || order["quantity"] != "12345678.123456789" || order["timeInForce"] != V3ImmediateOrCancel {
+		t.Fatalf("market order body %s", bodies["POST /orders"])
+	}
+
+	if id, err = b.WithdrawDecimal("addr", "btc", MustDecimal("0.123456789012")); err != nil || id != "w1" {
+		t.Fatalf("got %q, %v", id, err)
+	}
+	var withdrawal map[string]string
+	json.Unmarshal([]byte(bodies["POST /withdrawals"]), &withdrawal)
+	if withdrawal["quantity"] != "0.123456789012" || withdrawal["currencySymbol"] != "BTC" || withdrawal["cryptoAddress"] != "addr" {
+		t.Fatalf("withdrawal body %s", bodies["POST /withdrawals"])
+	}
+}
+
+func TestV3DecimalResultsAreExact(t *testing.T) {
+	b, _ := v3Server(t, map[string]string{
+		"GET /markets/LTC-BTC/ticker": `{"symbol":"LTC-BTC","lastTradeRate":"0.00012345678901234567","bidRate":"0.1","askRate":"0.30000000"}`,
+		"GET /orders/o1": `{"id":"o1","marketSymbol":"LTC-BTC","direction":"SELL","type":"LIMIT","quantity":"0.3","limit":"0.10000000",
+			"timeInForce":"GOOD_TIL_CANCELLED","fillQuantity":"0.1","commission":"0.00000001","proceeds":"0.02","status":"OPEN",
+			"createdAt":"2026-10-17T12:00:00Z"}`,
+		"GET /balances":          `[{"currencySymbol":"BTC","total":"1.123456789123","available":"0.000000000001"}]`,
+		"GET /orders/closed":     `[{"id":"o2","marketSymbol":"LTC-BTC","direction":"BUY","type":"LIMIT","quantity":"1","fillQuantity":"1","proceeds":"1","status":"CLOSED"}]`,
+		"GET /markets/summaries": `[{"symbol":"LTC-BTC","high":"0.2","low":"0.1","volume":"10","quoteVolume":"1.5","percentChange":"25"}]`,
+		"GET /markets/tickers":   `[{"symbol":"LTC-BTC","lastTradeRate":"0.15","bidRate":"0.14","askRate":"0.16"}]`,
+	})
+
+	ticker, err := b.GetTickerDecimal("BTC-LTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.Last.String() != "0.00012345678901234567" || ticker.Bid.String() != "0.1" || ticker.Ask.String() != "0.30000000" {
+		t.Fatalf("ticker %s %s %s", ticker.Last, ticker.Bid, ticker.Ask)
+	}
+
+	order, err := b.GetOrderDecimal("o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.3 - 0.1 is not 0.2 in float64.
+	if order.QuantityRemaining.String() != "0.2" || order.Limit.String() != "0.10000000" || order.PricePerUnit.String() != "0.20000000" ||
+		order.Type != "LIMIT_SELL" || order.Exchange != "BTC-LTC" || !order.IsOpen || order.Opened != "2026-10-17T12:00:00" {
+		t.Fatalf("order %+v", order)
+	}
+
+	balances, err := b.GetBalancesDecimal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Balance.String() != "1.123456789123" || balances[0].Available.String() != "0.000000000001" {
+		t.Fatalf("balances %+v", balances)
+	}
+
+	history, err := b.GetOrderHistoryDecimal("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || !history[0].QuantityRemaining.IsZero() || history[0].OrderType != "LIMIT_BUY" {
+		t.Fatalf("history %+v", history)
+	}
+
+	summaries, err := b.GetMarketSummariesDecimal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 || summaries[0].MarketName != "BTC-LTC" || summaries[0].Last.String() != "0.15" ||
+		summaries[0].BaseVolume.String() != "1.5" || summaries[0].PrevDay.String() != "0.12000000" {
+		t.Fatalf("summaries %+v", summaries[0])
+	}
+}
@@ -0,0 +1,270 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// v3 sends every decimal as a JSON string, hence the ",string" options below.
+
+// V3 order directions, types and time in force values.
+const (
+	V3DirectionBuy  = "BUY"
+	V3DirectionSell = "SELL"
+
+	V3OrderTypeLimit  = "LIMIT"
+	V3OrderTypeMarket = "MARKET"
+
+	V3GoodTilCancelled  = "GOOD_TIL_CANCELLED"
+	V3ImmediateOrCancel = "IMMEDIATE_OR_CANCEL"
+	V3FillOrKill        = "FILL_OR_KILL"
+)
+
+type V3Market struct {
+	Symbol              string    `json:"symbol"`
+	BaseCurrencySymbol  string    `json:"baseCurrencySymbol"`
+	QuoteCurrencySymbol string    `json:"quoteCurrencySymbol"`
+	MinTradeSize        float64   `json:"minTradeSize,string"`
+	Precision           int       `json:"precision"`
+	Status              string    `json:"status"`
+	CreatedAt           time.Time `json:"createdAt"`
+	Notice              string    `json:"notice"`
+}
+
+type V3MarketSummary struct {
+	Symbol        string    `json:"symbol"`
+	High          float64   `json:"high,string"`
+	Low           float64   `json:"low,string"`
+	Volume        float64   `json:"volume,string"`
+	QuoteVolume   float64   `json:"quoteVolume,string"`
+	PercentChange float64   `json:"percentChange,string"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type V3Ticker struct {
+	Symbol        string  `json:"symbol"`
+	LastTradeRate float64 `json:"lastTradeRate,string"`
+	BidRate       float64 `json:"bidRate,string"`
+	AskRate       float64 `json:"askRate,string"`
+}
+
+type V3OrderBookEntry struct {
+	Quantity float64 `json:"quantity,string"`
+	Rate     float64 `json:"rate,string"`
+}
+
+type V3OrderBook struct {
+	Bid []V3OrderBookEntry `json:"bid"`
+	Ask []V3OrderBookEntry `json:"ask"`
+	// Sequence is read from the Sequence response header. Streamed order book
+	// deltas carry the same sequence numbers.
+	Sequence int64 `json:"-"`
+}
+
+type V3Trade struct {
+	ID         string    `json:"id"`
+	ExecutedAt time.Time `json:"executedAt"`
+	Quantity   float64   `json:"quantity,string"`
+	Rate       float64   `json:"rate,string"`
+	TakerSide  string    `json:"takerSide"`
+}
+
+// V3CandleInterval is the candle interval understood by v3.
+type V3CandleInterval string
+
+const (
+	V3Minute1 V3CandleInterval = "MINUTE_1"
+	V3Minute5 V3CandleInterval = "MINUTE_5"
+	V3Hour1   V3CandleInterval = "HOUR_1"
+	V3Day1    V3CandleInterval = "DAY_1"
+)
+
+type V3Candle struct {
+	StartsAt    time.Time `json:"startsAt"`
+	Open        float64   `json:"open,string"`
+	High        float64   `json:"high,string"`
+	Low         float64   `json:"low,string"`
+	Close       float64   `json:"close,string"`
+	Volume      float64   `json:"volume,string"`
+	QuoteVolume float64   `json:"quoteVolume,string"`
+}
+
+type V3Currency struct {
+	Symbol           string  `json:"symbol"`
+	Name             string  `json:"name"`
+	CoinType         string  `json:"coinType"`
+	Status           string  `json:"status"`
+	MinConfirmations int     `json:"minConfirmations"`
+	Notice           string  `json:"notice"`
+	TxFee            float64 `json:"txFee,string"`
+	LogoUrl          string  `json:"logoUrl"`
+}
+
+type V3Order struct {
+	ID            string    `json:"id"`
+	MarketSymbol  string    `json:"marketSymbol"`
+	Direction     string    `json:"direction"`
+	Type          string    `json:"type"`
+	Quantity      float64   `json:"quantity,string"`
+	Limit         float64   `json:"limit,string"`
+	Ceiling       float64   `json:"ceiling,string"`
+	TimeInForce   string    `json:"timeInForce"`
+	ClientOrderID string    `json:"clientOrderId"`
+	FillQuantity  float64   `json:"fillQuantity,string"`
+	Commission    float64   `json:"commission,string"`
+	Proceeds      float64   `json:"proceeds,string"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	ClosedAt      time.Time `json:"closedAt"`
+}
+
+// V3NewOrder is the body of a v3 order creation.
+type V3NewOrder struct {
+	MarketSymbol  string
+	Direction     string
+	Type          string
+	Quantity      float64
+	Limit         float64 // ignored for market orders
+	TimeInForce   string
+	ClientOrderID string
+}
+
+// v3OrderBody is the JSON body of a v3 order creation, decimals written as strings.
+type v3OrderBody struct {
+	MarketSymbol  string `json:"marketSymbol"`
+	Direction     string `json:"direction"`
+	Type          string `json:"type"`
+	Quantity      string `json:"quantity"`
+	Limit         string `json:"limit,omitempty"`
+	TimeInForce   string `json:"timeInForce"`
+	ClientOrderID string `json:"clientOrderId,omitempty"`
+}
+
+func (o V3NewOrder) MarshalJSON() ([]byte, error) {
+	s := v3OrderBody{
+		MarketSymbol:  o.MarketSymbol,
+		Direction:     o.Direction,
+		Type:          o.Type,
+		Quantity:      strconv.FormatFloat(o.Quantity, 'f', 8, 64),
+		TimeInForce:   o.TimeInForce,
+		ClientOrderID: o.ClientOrderID,
+	}
+	if o.Type != V3OrderTypeMarket {
+		s.Limit = strconv.FormatFloat(o.Limit, 'f', 8, 64)
+	}
+	return json.Marshal(s)
+}
+
+// V3 conditional order operators: the order is created once the price is
+// greater, or less, than or equal to the trigger price.
+const (
+	V3OperatorGreaterOrEqual = "GTE"
+	V3OperatorLessOrEqual    = "LTE"
+)
+
+type V3ConditionalOrder struct {
+	ID                       string    `json:"id"`
+	MarketSymbol             string    `json:"marketSymbol"`
+	Operator                 string    `json:"operator"`
+	TriggerPrice             float64   `json:"triggerPrice,string"`
+	CreatedOrderID           string    `json:"createdOrderId"`
+	ClientConditionalOrderID string    `json:"clientConditionalOrderId"`
+	Status                   string    `json:"status"`
+	OrderCreationErrorCode   string    `json:"orderCreationErrorCode"`
+	CreatedAt                time.Time `json:"createdAt"`
+	UpdatedAt                time.Time `json:"updatedAt"`
+	ClosedAt                 time.Time `json:"closedAt"`
+}
+
+// V3NewConditionalOrder is the body of a v3 conditional order creation.
+type V3NewConditionalOrder struct {
+	MarketSymbol             string
+	Operator                 string
+	TriggerPrice             float64
+	OrderToCreate            *V3NewOrder // placed once triggered
+	ClientConditionalOrderID string
+}
+
+func (o V3NewConditionalOrder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MarketSymbol             string      `json:"marketSymbol"`
+		Operator                 string      `json:"operator"`
+		TriggerPrice             string      `json:"triggerPrice"`
+		OrderToCreate            *V3NewOrder `json:"orderToCreate,omitempty"`
+		ClientConditionalOrderID string      `json:"clientConditionalOrderId,omitempty"`
+	}{
+		MarketSymbol:             o.MarketSymbol,
+		Operator:                 o.Operator,
+		TriggerPrice:             strconv.FormatFloat(o.TriggerPrice, 'f', 8, 64),
+		OrderToCreate:            o.OrderToCreate,
+		ClientConditionalOrderID: o.ClientConditionalOrderID,
+	})
+}
+
+type V3Balance struct {
+	CurrencySymbol string    `json:"currencySymbol"`
+	Total          float64   `json:"total,string"`
+	Available      float64   `json:"available,string"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+type V3Deposit struct {
+	ID               string    `json:"id"`
+	CurrencySymbol   string    `json:"currencySymbol"`
+	Quantity         float64   `json:"quantity,string"`
+	CryptoAddress    string    `json:"cryptoAddress"`
+	CryptoAddressTag string    `json:"cryptoAddressTag"`
+	TxID             string    `json:"txId"`
+	Confirmations    int       `json:"confirmations"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	CompletedAt      time.Time `json:"completedAt"`
+	Status           string    `json:"status"`
+	Source           string    `json:"source"`
+}
+
+type V3Withdrawal struct {
+	ID               string    `json:"id"`
+	CurrencySymbol   string    `json:"currencySymbol"`
+	Quantity         float64   `json:"quantity,string"`
+	CryptoAddress    string    `json:"cryptoAddress"`
+	CryptoAddressTag string    `json:"cryptoAddressTag"`
+	TxCost           float64   `json:"txCost,string"`
+	TxID             string    `json:"txId"`
+	Status           string    `json:"status"`
+	CreatedAt        time.Time `json:"createdAt"`
+	CompletedAt      time.Time `json:"completedAt"`
+}
+
+// V3NewWithdrawal is the body of a v3 withdrawal request.
+type V3NewWithdrawal struct {
+	CurrencySymbol   string
+	Quantity         float64
+	CryptoAddress    string
+	CryptoAddressTag string
+}
+
+// v3WithdrawalBody is the JSON body of a v3 withdrawal request, decimals written as strings.
+type v3WithdrawalBody struct {
+	CurrencySymbol   string `json:"currencySymbol"`
+	Quantity         string `json:"quantity"`
+	CryptoAddress    string `json:"cryptoAddress"`
+	CryptoAddressTag string `json:"cryptoAddressTag,omitempty"`
+}
+
+func (w V3NewWithdrawal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v3WithdrawalBody{
+		CurrencySymbol:   w.CurrencySymbol,
+		Quantity:         strconv.FormatFloat(w.Quantity, 'f', 8, 64),
+		CryptoAddress:    w.CryptoAddress,
+		CryptoAddressTag: w.CryptoAddressTag,
+	})
+}
+
+type V3Address struct {
+	Status           string `json:"status"`
+	CurrencySymbol   string `json:"currencySymbol"`
+	CryptoAddress    string `json:"cryptoAddress"`
+	CryptoAddressTag string `json:"cryptoAddressTag"`
+}
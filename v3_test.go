@@ -0,0 +1,193 @@
+package bittrex
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// signedServer answers the requests with the JSON of routes[method+" "+path],
+// the v3 ones under /v3. Like Bittrex, it rejects a signed request whose
+// content hash or signature, by the secret "secret", does not match, and a
+// private v3 request without credentials. It returns a v3 client of it using
+// secret, and the requests it received.
+func signedServer(t *testing.T, secret string, routes map[string]string) (*Bittrex, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + r.URL.Path
+		mu.Lock()
+		requests = append(requests, key+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if code := checkV3Signature(r, body, "secret"); code != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"code":"`+code+`"}`)
+			return
+		}
+		answer, ok := routes[key]
+		if !ok {
+			http.Error(w, `{"code":"NOT_FOUND"}`, http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == "/v3/markets/LTC-BTC/orderbook" {
+			w.Header().Set("Sequence", "42")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, answer)
+	}))
+	t.Cleanup(s.Close)
+	b := New("key", secret, WithAPIV3(), WithV3BaseURL(s.URL+"/v3"), WithBaseURL(s.URL+"/api/v1.1"),
+		WithV2BaseURL(s.URL+"/Api/v2.0"), WithHTTPClient(s.Client()))
+	return b, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+// checkV3Signature returns the error code of a v3 request failing the v3
+// authentication, or "".
+func checkV3Signature(r *http.Request, body []byte, secret string) string {
+	private := strings.HasPrefix(r.URL.Path, "/v3/balances") || strings.HasPrefix(r.URL.Path, "/v3/orders")
+	if r.Header.Get("Api-Key") == "" {
+		if private {
+			return "APIKEY_INVALID"
+		}
+		return ""
+	}
+	hash := sha512.Sum512(body)
+	contentHash := hex.EncodeToString(hash[:])
+	if r.Header.Get("Api-Content-Hash") != contentHash {
+		return "INVALID_CONTENT_HASH"
+	}
+	mac := hmac.New(sha512.New, []byte(secret))
+	io.WriteString(mac, r.Header.Get("Api-Timestamp")+"http://"+r.Host+r.URL.RequestURI()+r.Method+contentHash)
+	if !hmac.Equal([]byte(r.Header.Get("Api-Signature")), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return "INVALID_SIGNATURE"
+	}
+	return ""
+}
+
+func TestV3Signature(t *testing.T) {
+	routes := map[string]string{
+		"GET /v3/balances":               `[{"currencySymbol":"BTC","total":"1.5","available":"1"}]`,
+		"POST /v3/orders":                `{"id":"o1"}`,
+		"DELETE /v3/orders/o1":           `{"id":"o1","status":"CLOSED"}`,
+		"GET /v3/markets/LTC-BTC/ticker": `{"symbol":"LTC-BTC","lastTradeRate":"0.01"}`,
+	}
+	b, _ := signedServer(t, "secret", routes)
+	ctx := context.Background()
+	balances, err := b.V3().GetBalances(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Total != 1.5 {
+		t.Fatalf("balances %+v", balances)
+	}
+	// The body is hashed and signed too.
+	if _, err = b.V3().PlaceOrder(ctx, V3NewOrder{MarketSymbol: "LTC-BTC", Direction: V3DirectionBuy, Type: V3OrderTypeLimit,
+		Quantity: 1, Limit: 0.01, TimeInForce: V3GoodTilCancelled}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.V3().CancelOrder(ctx, "o1"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ = signedServer(t, "wrong", routes)
+	if _, err = b.V3().GetBalances(ctx); !errors.Is(err, &APIError{Message: "INVALID_SIGNATURE"}) {
+		t.Fatalf("got %v, want INVALID_SIGNATURE", err)
+	}
+	// Public resources are not signed.
+	if _, err = b.V3().GetTicker(ctx, "LTC-BTC"); err != nil {
+		t.Fatal(err)
+	}
+	b.client.apiKey = ""
+	if _, err = b.V3().GetBalances(ctx); err == nil {
+		t.Fatal("private call without credentials")
+	}
+}
+
+func TestV3BookDepth(t *testing.T) {
+	for depth, want := range map[int]int{-1: 1, 0: 1, 1: 1, 2: 25, 25: 25, 26: 500, 500: 500, 1000: 500} {
+		if got := v3BookDepth(depth); got != want {
+			t.Errorf("v3BookDepth(%d) = %d, want %d", depth, got, want)
+		}
+	}
+}
+
+func TestV3OrderBook(t *testing.T) {
+	b, requests := signedServer(t, "secret", map[string]string{
+		"GET /v3/markets/LTC-BTC/orderbook": `{"bid":[{"quantity":"4","rate":"0.009"},{"quantity":"10","rate":"0.008"}],"ask":[{"quantity":"5","rate":"0.01"}]}`,
+	})
+	book, err := b.V3().GetOrderBook(context.Background(), "ltc-btc", 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.Sequence != 42 || len(book.Bid) != 2 || book.Ask[0].Rate != 0.01 {
+		t.Fatalf("book %+v", book)
+	}
+	if got := requests(); len(got) != 1 || got[0] != "GET /v3/markets/LTC-BTC/orderbook?depth=500" {
+		t.Fatalf("requests %v", got)
+	}
+}
+
+func TestV3BacksV1Methods(t *testing.T) {
+	b, requests := signedServer(t, "secret", map[string]string{
+		"GET /v3/markets/LTC-BTC/ticker":                    `{"symbol":"LTC-BTC","lastTradeRate":"0.01","bidRate":"0.009","askRate":"0.011"}`,
+		"GET /v3/markets/LTC-BTC/orderbook":                 `{"bid":[{"quantity":"4","rate":"0.009"},{"quantity":"10","rate":"0.008"},{"quantity":"20","rate":"0.007"}],"ask":[{"quantity":"5","rate":"0.01"}]}`,
+		"GET /v3/balances":                                  `[{"currencySymbol":"BTC","total":"1.5","available":"1"}]`,
+		"DELETE /v3/orders/o1":                              `{"id":"o1","status":"CLOSED"}`,
+		"GET /Api/v2.0/pub/currency/GetBalanceDistribution": `{"success":true,"message":"","result":{}}`,
+	})
+	ticker, err := b.GetTicker("btc-ltc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.Bid != 0.009 || ticker.Ask != 0.011 || ticker.Last != 0.01 {
+		t.Fatalf("ticker %+v", ticker)
+	}
+	// The v1.1 depth is rounded up for v3, and the answer cut back to it.
+	book, err := b.GetOrderBook("BTC-LTC", "buy", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(book.Buy, []Orderb{{Quantity: 4, Rate: 0.009}, {Quantity: 10, Rate: 0.008}}) || book.Sell != nil {
+		t.Fatalf("book %+v", book)
+	}
+	balances, err := b.GetBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Currency != "BTC" || balances[0].Balance != 1.5 || balances[0].Available != 1 {
+		t.Fatalf("balances %+v", balances)
+	}
+	if err = b.CancelOrder("o1"); err != nil {
+		t.Fatal(err)
+	}
+	// Methods without a v3 equivalent keep calling v2.0.
+	if _, err = b.GetDistribution("btc"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /v3/markets/LTC-BTC/ticker?",
+		"GET /v3/markets/LTC-BTC/orderbook?depth=25",
+		"GET /v3/balances?",
+		"DELETE /v3/orders/o1?",
+		"GET /Api/v2.0/pub/currency/GetBalanceDistribution?currencyName=BTC",
+	}
+	if got := requests(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("requests %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,428 @@
+package bittrex
+
+import (
+	"context"
+	"strings"
+)
+
+// This file backs the v1.1 flavoured Bittrex methods by the v3 API, see WithAPIV3.
+// Results are converted to the v1.1 types, market names included.
+
+func v3Active(status string) bool {
+	return status == "ONLINE"
+}
+
+// v3OrderType returns the v1.1 order type (ex: LIMIT_BUY) of o.
+func v3OrderType(o *V3Order) string {
+	return o.Type + "_" + o.Direction
+}
+
+// v3TimeFormat returns the opening time of o, or its closing time if closed is
+// set, formatted like v1.1 does. It returns "" for an unknown time.
+func v3TimeFormat(o *V3Order, closed bool) string {
+	t := o.CreatedAt
+	if closed {
+		t = o.ClosedAt
+	}
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TIME_FORMAT)
+}
+
+func v3PricePerUnit(o *V3Order) float64 {
+	if o.FillQuantity == 0 {
+		return 0
+	}
+	return o.Proceeds / o.FillQuantity
+}
+
+func v3ToOrderHistory(o *V3Order) *OrderHistory {
+	return &OrderHistory{
+		OrderUuid:         o.ID,
+		Exchange:          V3Symbol(o.MarketSymbol),
+		TimeStamp:         o.CreatedAt.UTC(),
+		OrderType:         v3OrderType(o),
+		Limit:             o.Limit,
+		Quantity:          o.Quantity,
+		QuantityRemaining: o.Quantity - o.FillQuantity,
+		Commission:        o.Commission,
+		Price:             o.Proceeds,
+		PricePerUnit:      v3PricePerUnit(o),
+	}
+}
+
+func v3ToOrder(o *V3Order) *Order {
+	return &Order{
+		OrderUuid:         o.ID,
+		Exchange:          V3Symbol(o.MarketSymbol),
+		Type:              v3OrderType(o),
+		Quantity:          o.Quantity,
+		QuantityRemaining: o.Quantity - o.FillQuantity,
+		Limit:             o.Limit,
+		CommissionPaid:    o.Commission,
+		Price:             o.Proceeds,
+		PricePerUnit:      v3PricePerUnit(o),
+		Opened:            v3TimeFormat(o, false),
+		Closed:            v3TimeFormat(o, true),
+		IsOpen:            o.Status == "OPEN",
+		ImmediateOrCancel: o.TimeInForce == V3ImmediateOrCancel || o.TimeInForce == V3FillOrKill,
+	}
+}
+
+func v3ToOrderbs(entries []V3OrderBookEntry, depth int) []Orderb {
+	if len(entries) > depth {
+		entries = entries[:depth]
+	}
+	orderbs := make([]Orderb, len(entries))
+	for i, e := range entries {
+		orderbs[i] = Orderb{Quantity: e.Quantity, Rate: e.Rate}
+	}
+	return orderbs
+}
+
+func v3ToMarketSummary(s *V3MarketSummary, t *V3Ticker) *MarketSummary {
+	summary := &MarketSummary{
+		MarketName: V3Symbol(s.Symbol),
+		High:       s.High,
+		Low:        s.Low,
+		Volume:     s.Volume,
+		BaseVolume: s.QuoteVolume,
+	}
+	if !s.UpdatedAt.IsZero() {
+		summary.TimeStamp = s.UpdatedAt.UTC().Format(TIME_FORMAT)
+	}
+	if t != nil {
+		summary.Bid = t.BidRate
+		summary.Ask = t.AskRate
+		summary.Last = t.LastTradeRate
+		if s.PercentChange != -100 {
+			summary.PrevDay = t.LastTradeRate / (1 + s.PercentChange/100)
+		}
+	}
+	return summary
+}
+
+// v3CandleIntervals maps the v1.1 intervals having a v3 equivalent.
+var v3CandleIntervals = map[Interval]V3CandleInterval{
+	OneMin:  V3Minute1,
+	FiveMin: V3Minute5,
+	Hour:    V3Hour1,
+	Day:     V3Day1,
+}
+
+func (b *Bittrex) v3GetCurrencies(ctx context.Context) ([]*Currency, error) {
+	v3Currencies, err := b.V3().GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currencies := make([]*Currency, len(v3Currencies))
+	for i, c := range v3Currencies {
+		currencies[i] = &Currency{
+			Currency:        c.Symbol,
+			CurrencyLong:    c.Name,
+			MinConfirmation: c.MinConfirmations,
+			TxFee:           c.TxFee,
+			IsActive:        v3Active(c.Status),
+			CoinType:        c.CoinType,
+			Notice:          c.Notice,
+		}
+	}
+	return currencies, nil
+}
+
+func (b *Bittrex) v3GetMarkets(ctx context.Context) ([]*Market, error) {
+	v3Markets, err := b.V3().GetMarkets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	markets := make([]*Market, len(v3Markets))
+	for i, m := range v3Markets {
+		markets[i] = &Market{
+			MarketCurrency: m.BaseCurrencySymbol,
+			BaseCurrency:   m.QuoteCurrencySymbol,
+			MinTradeSize:   m.MinTradeSize,
+			MarketName:     V3Symbol(m.Symbol),
+			IsActive:       v3Active(m.Status),
+			Notice:         m.Notice,
+		}
+	}
+	return markets, nil
+}
+
+func (b *Bittrex) v3GetTicker(ctx context.Context, market string) (*Ticker, error) {
+	t, err := b.V3().GetTicker(ctx, V3Symbol(market))
+	if err != nil {
+		return nil, err
+	}
+	return &Ticker{Bid: t.BidRate, Ask: t.AskRate, Last: t.LastTradeRate}, nil
+}
+
+func (b *Bittrex) v3GetMarketSummaries(ctx context.Context) ([]*MarketSummary, error) {
+	summaries, err := b.V3().GetMarketSummaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tickers, err := b.V3().GetTickers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bySymbol := make(map[string]*V3Ticker, len(tickers))
+	for _, t := range tickers {
+		bySymbol[t.Symbol] = t
+	}
+	marketSummaries := make([]*MarketSummary, len(summaries))
+	for i, s := range summaries {
+		marketSummaries[i] = v3ToMarketSummary(s, bySymbol[s.Symbol])
+	}
+	return marketSummaries, nil
+}
+
+func (b *Bittrex) v3GetMarketSummary(ctx context.Context, market string) ([]*MarketSummary, error) {
+	s, err := b.V3().GetMarketSummary(ctx, V3Symbol(market))
+	if err != nil {
+		return nil, err
+	}
+	t, err := b.V3().GetTicker(ctx, V3Symbol(market))
+	if err != nil {
+		return nil, err
+	}
+	return []*MarketSummary{v3ToMarketSummary(s, t)}, nil
+}
+
+func (b *Bittrex) v3GetOrderBook(ctx context.Context, market string, depth int) (*OrderBook, error) {
+	book, err := b.V3().GetOrderBook(ctx, V3Symbol(market), depth)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderBook{Buy: v3ToOrderbs(book.Bid, depth), Sell: v3ToOrderbs(book.Ask, depth)}, nil
+}
+
+func (b *Bittrex) v3GetMarketHistory(ctx context.Context, market string) ([]*Trade, error) {
+	v3Trades, err := b.V3().GetTrades(ctx, V3Symbol(market))
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]*Trade, len(v3Trades))
+	for i, t := range v3Trades {
+		trades[i] = &Trade{
+			OrderUuid: t.ID,
+			TimeStamp: t.ExecutedAt.UTC(),
+			Quantity:  t.Quantity,
+			Price:     t.Rate,
+			Total:     t.Quantity * t.Rate,
+			FillType:  "FILL",
+			OrderType: t.TakerSide,
+		}
+	}
+	return trades, nil
+}
+
+func (b *Bittrex) v3PlaceOrder(ctx context.Context, market, direction, orderType string, quantity, rate float64) (string, error) {
+	order := V3NewOrder{
+		MarketSymbol: V3Symbol(market),
+		Direction:    direction,
+		Type:         orderType,
+		Quantity:     quantity,
+		Limit:        rate,
+		TimeInForce:  V3GoodTilCancelled,
+	}
+	if orderType == V3OrderTypeMarket {
+		order.TimeInForce = V3ImmediateOrCancel
+	}
+	created, err := b.V3().PlaceOrder(ctx, order)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// v3Trade places t, a normalized TradeOrder, and returns its id. A
+// conditional order is placed as a v3 conditional order, whose id is returned.
+func (b *Bittrex) v3Trade(ctx context.Context, side string, t TradeOrder) (string, error) {
+	order := V3NewOrder{
+		MarketSymbol: V3Symbol(t.Market),
+		Direction:    side,
+		Type:         t.Type,
+		Quantity:     t.Quantity,
+		Limit:        t.Rate,
+		TimeInForce:  t.TimeInForce,
+	}
+	if t.Type == V3OrderTypeMarket && t.TimeInForce == GoodTilCancelled {
+		// v3 refuses a market order that could rest.
+		order.TimeInForce = V3ImmediateOrCancel
+	}
+	if t.Condition == ConditionNone {
+		created, err := b.V3().PlaceOrder(ctx, order)
+		if err != nil {
+			return "", err
+		}
+		return created.ID, nil
+	}
+	conditional := V3NewConditionalOrder{
+		MarketSymbol:  order.MarketSymbol,
+		Operator:      V3OperatorGreaterOrEqual,
+		TriggerPrice:  t.Target,
+		OrderToCreate: &order,
+	}
+	if t.Condition == ConditionLessThan {
+		conditional.Operator = V3OperatorLessOrEqual
+	}
+	created, err := b.V3().PlaceConditionalOrder(ctx, conditional)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (b *Bittrex) v3GetOpenOrders(ctx context.Context, market string) ([]*OrderHistory, error) {
+	symbol := ""
+	if market != "all" {
+		symbol = V3Symbol(market)
+	}
+	v3Orders, err := b.V3().GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*OrderHistory, len(v3Orders))
+	for i, o := range v3Orders {
+		orders[i] = v3ToOrderHistory(o)
+	}
+	return orders, nil
+}
+
+func (b *Bittrex) v3GetOrderHistory(ctx context.Context, market string) ([]*OrderHistory, error) {
+	symbol := ""
+	if market != "all" {
+		symbol = V3Symbol(market)
+	}
+	v3Orders, err := b.V3().GetClosedOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*OrderHistory, len(v3Orders))
+	for i, o := range v3Orders {
+		orders[i] = v3ToOrderHistory(o)
+	}
+	return orders, nil
+}
+
+func (b *Bittrex) v3GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	o, err := b.V3().GetOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return v3ToOrder(o), nil
+}
+
+func v3ToBalance(b *V3Balance) *Balance {
+	return &Balance{Currency: b.CurrencySymbol, Balance: b.Total, Available: b.Available}
+}
+
+func (b *Bittrex) v3GetBalances(ctx context.Context) ([]*Balance, error) {
+	v3Balances, err := b.V3().GetBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]*Balance, len(v3Balances))
+	for i, balance := range v3Balances {
+		balances[i] = v3ToBalance(balance)
+	}
+	return balances, nil
+}
+
+func (b *Bittrex) v3GetBalance(ctx context.Context, currency string) (*Balance, error) {
+	balance, err := b.V3().GetBalance(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return v3ToBalance(balance), nil
+}
+
+func (b *Bittrex) v3GetDepositAddress(ctx context.Context, currency string) (*Address, error) {
+	address, err := b.V3().GetAddress(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{Currency: address.CurrencySymbol, Address: address.CryptoAddress}, nil
+}
+
+func (b *Bittrex) v3Withdraw(ctx context.Context, address, currency string, quantity float64) (string, error) {
+	w, err := b.V3().Withdraw(ctx, V3NewWithdrawal{
+		CurrencySymbol: strings.ToUpper(currency),
+		Quantity:       quantity,
+		CryptoAddress:  address,
+	})
+	if err != nil {
+		return "", err
+	}
+	return w.ID, nil
+}
+
+func (b *Bittrex) v3GetWithdrawalHistory(ctx context.Context, currency string) ([]*Withdrawal, error) {
+	if currency == "all" {
+		currency = ""
+	}
+	v3Withdrawals, err := b.V3().GetClosedWithdrawals(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	withdrawals := make([]*Withdrawal, len(v3Withdrawals))
+	for i, w := range v3Withdrawals {
+		withdrawals[i] = &Withdrawal{
+			PaymentUuid:    w.ID,
+			Currency:       w.CurrencySymbol,
+			Amount:         w.Quantity,
+			Address:        w.CryptoAddress,
+			Opened:         w.CreatedAt.UTC(),
+			Authorized:     w.Status != "REQUESTED",
+			PendingPayment: w.Status == "AUTHORIZED" || w.Status == "PENDING",
+			TxCost:         w.TxCost,
+			TxId:           w.TxID,
+			Canceled:       w.Status == "CANCELLED",
+		}
+	}
+	return withdrawals, nil
+}
+
+func (b *Bittrex) v3GetDepositHistory(ctx context.Context, currency string) ([]*Deposit, error) {
+	if currency == "all" {
+		currency = ""
+	}
+	v3Deposits, err := b.V3().GetClosedDeposits(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	deposits := make([]*Deposit, len(v3Deposits))
+	for i, d := range v3Deposits {
+		deposits[i] = &Deposit{
+			Amount:        d.Quantity,
+			Currency:      d.CurrencySymbol,
+			Confirmations: d.Confirmations,
+			LastUpdated:   d.UpdatedAt.UTC(),
+			TxId:          d.TxID,
+			CryptoAddress: d.CryptoAddress,
+		}
+	}
+	return deposits, nil
+}
+
+func (b *Bittrex) v3GetTicks(ctx context.Context, market string, interval V3CandleInterval) ([]*Candle, error) {
+	v3Candles, err := b.V3().GetCandles(ctx, V3Symbol(market), interval)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([]*Candle, len(v3Candles))
+	for i, c := range v3Candles {
+		candles[i] = &Candle{
+			TimeStamp:  c.StartsAt.UTC(),
+			Open:       c.Open,
+			Close:      c.Close,
+			High:       c.High,
+			Low:        c.Low,
+			Volume:     c.Volume,
+			BaseVolume: c.QuoteVolume,
+		}
+	}
+	return candles, nil
+}
@@ -0,0 +1,68 @@
+package bittrex
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestV3Trade(t *testing.T) {
+	b, bodies := v3Server(t, map[string]string{
+		"POST /orders":             `{"id":"o1","status":"OPEN"}`,
+		"POST /conditional-orders": `{"id":"c1","status":"OPEN"}`,
+	})
+
+	o, err := b.TradeBuy(TradeOrder{Market: "btc-ltc", Quantity: 1.5, Rate: 0.01, TimeInForce: FillOrKill})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.OrderUuid != "o1" || o.Exchange != "BTC-LTC" || o.Type != "LIMIT_BUY" || !o.ImmediateOrCancel || o.IsConditional {
+		t.Fatalf("order %+v", o)
+	}
+	var order map[string]string
+	json.Unmarshal([]byte(bodies["POST /orders"]), &order)
+	if order["marketSymbol"] != "LTC-BTC" || order["direction"] != V3DirectionBuy || order["type"] != V3OrderTypeLimit ||
+		order["quantity"] != "1.50000000" || order["limit"] != "0.01000000" || order["timeInForce"] != V3FillOrKill {
+		t.Fatalf("order body %s", bodies["POST /orders"])
+	}
+
+	// v3 has no resting market order.
+	if _, err = b.TradeSell(TradeOrder{Market: "BTC-LTC", Type: "market", Quantity: 1}); err != nil {
+		t.Fatal(err)
+	}
+	order = nil
+	json.Unmarshal([]byte(bodies["POST /orders"]), &order)
+	if _, ok := order["limit"]; ok || order["type"] != V3OrderTypeMarket || order["direction"] != V3DirectionSell || order["timeInForce"] != V3ImmediateOrCancel {
+		t.Fatalf("market order body %s", bodies["POST /orders"])
+	}
+
+	stop, err := b.TradeSell(TradeOrder{Market: "BTC-LTC", Quantity: 2, Rate: 0.008, Condition: ConditionLessThan, Target: 0.0085})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stop.OrderUuid != "c1" || !stop.IsConditional || stop.Condition != ConditionLessThan || stop.ConditionTarget != "0.0085" {
+		t.Fatalf("conditional order %+v", stop)
+	}
+	var conditional struct {
+		MarketSymbol  string            `json:"marketSymbol"`
+		Operator      string            `json:"operator"`
+		TriggerPrice  string            `json:"triggerPrice"`
+		OrderToCreate map[string]string `json:"orderToCreate"`
+	}
+	if err = json.Unmarshal([]byte(bodies["POST /conditional-orders"]), &conditional); err != nil {
+		t.Fatal(err)
+	}
+	if conditional.MarketSymbol != "LTC-BTC" || conditional.Operator != V3OperatorLessOrEqual || conditional.TriggerPrice != "0.00850000" ||
+		conditional.OrderToCreate["direction"] != V3DirectionSell || conditional.OrderToCreate["limit"] != "0.00800000" ||
+		conditional.OrderToCreate["quantity"] != "2.00000000" || conditional.OrderToCreate["timeInForce"] != V3GoodTilCancelled {
+		t.Fatalf("conditional order body %s", bodies["POST /conditional-orders"])
+	}
+
+	if _, err = b.TradeBuy(TradeOrder{Market: "BTC-LTC", Quantity: 1, Rate: 0.02, Condition: ConditionGreaterThan, Target: 0.015}); err != nil {
+		t.Fatal(err)
+	}
+	conditional.Operator = ""
+	json.Unmarshal([]byte(bodies["POST /conditional-orders"]), &conditional)
+	if conditional.Operator != V3OperatorGreaterOrEqual {
+		t.Fatalf("conditional order body %s", bodies["POST /conditional-orders"])
+	}
+}
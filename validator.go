@@ -0,0 +1,296 @@
+package bittrex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons an OrderValidator rejects an order. The *ValidationError returned
+// wraps one of them, and also matches the error Bittrex would have answered
+// with: ErrInvalidMarket or ErrMinTradeRequirementNotMet.
+var (
+	ErrUnknownMarket     = errors.New("unknown market")
+	ErrMarketInactive    = errors.New("market is not active")
+	ErrBelowMinTradeSize = errors.New("quantity below the minimum trade size")
+	ErrBelowMinNotional  = errors.New("total below the minimum order value")
+	ErrPrecision         = errors.New("too many decimals")
+)
+
+// DefaultMinNotional is the minimum total (quantity * rate), by base currency,
+// of an order accepted by Bittrex: 50K satoshis on BTC markets.
+var DefaultMinNotional = map[string]float64{
+	"BTC": 0.0005,
+}
+
+// ValidationError is returned by an OrderValidator for an order it rejects.
+type ValidationError struct {
+	Market   string
+	Quantity float64
+	Rate     float64 // 0 for a market order
+	Limit    float64 // limit which was not met, if any
+	Field    string  // "quantity" or "rate", the value having too many decimals for ErrPrecision
+	Err      error   // one of ErrUnknownMarket, ErrMarketInactive...
+}
+
+func (e *ValidationError) Error() string {
+	switch e.Err {
+	case ErrBelowMinTradeSize:
+		return fmt.Sprintf("bittrex: %s: %s: %v < %v", e.Market, e.Err, e.Quantity, e.Limit)
+	case ErrBelowMinNotional:
+		return fmt.Sprintf("bittrex: %s: %s: %v < %v", e.Market, e.Err, round8(e.Quantity*e.Rate), e.Limit)
+	case ErrPrecision:
+		value := e.Quantity
+		if e.Field == "rate" {
+			value = e.Rate
+		}
+		return fmt.Sprintf("bittrex: %s: %s: %s %v, %v allowed", e.Market, e.Err, e.Field, value, e.Limit)
+	}
+	return fmt.Sprintf("bittrex: %s: %s", e.Market, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Is makes a ValidationError match the sentinel of the API error it prevented.
+func (e *ValidationError) Is(target error) bool {
+	switch e.Err {
+	case ErrUnknownMarket:
+		return target == ErrInvalidMarket
+	case ErrBelowMinTradeSize, ErrBelowMinNotional:
+		return target == ErrMinTradeRequirementNotMet
+	}
+	return false
+}
+
+// ValidatorOption configures an OrderValidator.
+type ValidatorOption func(*OrderValidator)
+
+// WithValidatorTTL sets how long the markets are cached. Defaults to 1h.
+func WithValidatorTTL(ttl time.Duration) ValidatorOption {
+	return func(v *OrderValidator) {
+		v.ttl = ttl
+	}
+}
+
+// WithMinNotional sets the minimum total of the orders on the markets of a
+// base currency, ex: WithMinNotional("USDT", 5). 0 removes the minimum.
+func WithMinNotional(baseCurrency string, min float64) ValidatorOption {
+	return func(v *OrderValidator) {
+		v.minNotional[strings.ToUpper(baseCurrency)] = min
+	}
+}
+
+// WithPrecision sets the number of decimals allowed for the quantity and the
+// rate of the orders on market. Defaults to 8 for both, what the API accepts.
+func WithPrecision(market string, quantityDecimals, rateDecimals int) ValidatorOption {
+	return func(v *OrderValidator) {
+		v.precisions[strings.ToUpper(market)] = [2]int{quantityDecimals, rateDecimals}
+	}
+}
+
+// WithRounding makes the validator round the quantity down, and the rate to
+// the nearest, allowed value instead of rejecting them with ErrPrecision.
+// The minimums are checked after rounding.
+func WithRounding() ValidatorOption {
+	return func(v *OrderValidator) {
+		v.round = true
+	}
+}
+
+// OrderValidator checks orders against the market metadata before they are
+// sent, so that they fail locally with a *ValidationError instead of on the
+// exchange. It caches the markets returned by GetMarkets.
+type OrderValidator struct {
+	source      MarketData
+	ttl         time.Duration
+	minNotional map[string]float64
+	precisions  map[string][2]int
+	round       bool
+
+	mu       sync.Mutex
+	markets  map[string]*Market
+	loadedAt time.Time
+}
+
+// NewOrderValidator returns a validator of the orders on the markets of source.
+func NewOrderValidator(source MarketData, opts ...ValidatorOption) *OrderValidator {
+	v := &OrderValidator{
+		source:      source,
+		ttl:         time.Hour,
+		minNotional: map[string]float64{},
+		precisions:  map[string][2]int{},
+	}
+	for currency, min := range DefaultMinNotional {
+		v.minNotional[currency] = min
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Refresh reloads the markets.
+func (v *OrderValidator) Refresh(ctx context.Context) error {
+	markets, err := v.source.GetMarketsCtx(ctx)
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.markets = make(map[string]*Market, len(markets))
+	for _, m := range markets {
+		v.markets[strings.ToUpper(m.MarketName)] = m
+	}
+	v.loadedAt = time.Now()
+	return nil
+}
+
+// Market returns the cached metadata of market, loading the markets if they
+// are not cached yet or are older than the TTL.
+func (v *OrderValidator) Market(ctx context.Context, market string) (*Market, error) {
+	v.mu.Lock()
+	stale := v.markets == nil || time.Since(v.loadedAt) > v.ttl
+	v.mu.Unlock()
+	if stale {
+		if err := v.Refresh(ctx); err != nil {
+			return nil, err
+		}
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	m, ok := v.markets[strings.ToUpper(market)]
+	if !ok {
+		return nil, &ValidationError{Market: market, Err: ErrUnknownMarket}
+	}
+	return m, nil
+}
+
+// Validate checks an order of quantity at rate on market, rate being 0 for a
+// market order. It returns the quantity and rate to send: rounded with
+// WithRounding, unchanged otherwise.
+func (v *OrderValidator) Validate(ctx context.Context, market string, quantity, rate float64) (float64, float64, error) {
+	m, err := v.Market(ctx, market)
+	if err != nil {
+		return 0, 0, err
+	}
+	fail := func(reason error, limit float64) (float64, float64, error) {
+		return 0, 0, &ValidationError{Market: m.MarketName, Quantity: quantity, Rate: rate, Limit: limit, Err: reason}
+	}
+	imprecise := func(field string, decimals int) (float64, float64, error) {
+		return 0, 0, &ValidationError{Market: m.MarketName, Quantity: quantity, Rate: rate, Limit: float64(decimals), Field: field, Err: ErrPrecision}
+	}
+	if !m.IsActive {
+		return fail(ErrMarketInactive, 0)
+	}
+
+	precision, ok := v.precisions[strings.ToUpper(m.MarketName)]
+	if !ok {
+		precision = [2]int{8, 8}
+	}
+	if v.round {
+		quantity = roundDown(quantity, precision[0])
+		rate = roundTo(rate, precision[1])
+	} else if !hasPrecision(quantity, precision[0]) {
+		return imprecise("quantity", precision[0])
+	} else if !hasPrecision(rate, precision[1]) {
+		return imprecise("rate", precision[1])
+	}
+
+	if quantity < m.MinTradeSize || quantity <= 0 {
+		return fail(ErrBelowMinTradeSize, m.MinTradeSize)
+	}
+	if min := v.minNotional[strings.ToUpper(m.BaseCurrency)]; rate > 0 && round8(quantity*rate) < min {
+		return fail(ErrBelowMinNotional, min)
+	}
+	return quantity, rate, nil
+}
+
+// roundTo rounds f to the nearest value with decimals decimals.
+func roundTo(f float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	return math.Round(f*p) / p
+}
+
+// roundDown rounds f toward zero to decimals decimals. It tolerates the
+// representation error of f, so that 0.3 is not rounded down to 0.29999999.
+func roundDown(f float64, decimals int) float64 {
+	if hasPrecision(f, decimals) {
+		return roundTo(f, decimals)
+	}
+	p := math.Pow10(decimals)
+	return math.Trunc(f*p) / p
+}
+
+// hasPrecision reports whether f has at most decimals decimals, give or take
+// the float64 representation error.
+func hasPrecision(f float64, decimals int) bool {
+	return math.Abs(f-roundTo(f, decimals)) <= math.Abs(f)*1e-12
+}
+
+// ValidatingTrading is a Trading checking the orders with an OrderValidator
+// before placing them.
+type ValidatingTrading struct {
+	Trading
+	Validator *OrderValidator
+}
+
+// NewValidatingTrading returns t checking its orders with v.
+func NewValidatingTrading(t Trading, v *OrderValidator) *ValidatingTrading {
+	return &ValidatingTrading{Trading: t, Validator: v}
+}
+
+// BuyLimit validates, then places, a limit buy order.
+func (t *ValidatingTrading) BuyLimit(market string, quantity, rate float64) (uuid string, err error) {
+	return t.BuyLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// BuyLimitCtx is like BuyLimit but carries ctx to the underlying HTTP requests.
+func (t *ValidatingTrading) BuyLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	if quantity, rate, err = t.Validator.Validate(ctx, market, quantity, rate); err != nil {
+		return
+	}
+	return t.Trading.BuyLimitCtx(ctx, market, quantity, rate)
+}
+
+// BuyMarket validates, then places, a market buy order.
+func (t *ValidatingTrading) BuyMarket(market string, quantity float64) (uuid string, err error) {
+	return t.BuyMarketCtx(context.Background(), market, quantity)
+}
+
+// BuyMarketCtx is like BuyMarket but carries ctx to the underlying HTTP requests.
+func (t *ValidatingTrading) BuyMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	if quantity, _, err = t.Validator.Validate(ctx, market, quantity, 0); err != nil {
+		return
+	}
+	return t.Trading.BuyMarketCtx(ctx, market, quantity)
+}
+
+// SellLimit validates, then places, a limit sell order.
+func (t *ValidatingTrading) SellLimit(market string, quantity, rate float64) (uuid string, err error) {
+	return t.SellLimitCtx(context.Background(), market, quantity, rate)
+}
+
+// SellLimitCtx is like SellLimit but carries ctx to the underlying HTTP requests.
+func (t *ValidatingTrading) SellLimitCtx(ctx context.Context, market string, quantity, rate float64) (uuid string, err error) {
+	if quantity, rate, err = t.Validator.Validate(ctx, market, quantity, rate); err != nil {
+		return
+	}
+	return t.Trading.SellLimitCtx(ctx, market, quantity, rate)
+}
+
+// SellMarket validates, then places, a market sell order.
+func (t *ValidatingTrading) SellMarket(market string, quantity float64) (uuid string, err error) {
+	return t.SellMarketCtx(context.Background(), market, quantity)
+}
+
+// SellMarketCtx is like SellMarket but carries ctx to the underlying HTTP requests.
+func (t *ValidatingTrading) SellMarketCtx(ctx context.Context, market string, quantity float64) (uuid string, err error) {
+	if quantity, _, err = t.Validator.Validate(ctx, market, quantity, 0); err != nil {
+		return
+	}
+	return t.Trading.SellMarketCtx(ctx, market, quantity)
+}
@@ -0,0 +1,97 @@
+package bittrex_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yangou/go-bittrex"
+	"github.com/yangou/go-bittrex/bittrextest"
+)
+
+func TestOrderValidator(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetMarkets(
+		&bittrex.Market{MarketName: "BTC-LTC", MarketCurrency: "LTC", BaseCurrency: "BTC", MinTradeSize: 0.1, IsActive: true},
+		&bittrex.Market{MarketName: "USDT-BTC", MarketCurrency: "BTC", BaseCurrency: "USDT", MinTradeSize: 0.0001, IsActive: true},
+		&bittrex.Market{MarketName: "BTC-OLD", MarketCurrency: "OLD", BaseCurrency: "BTC", MinTradeSize: 1, IsActive: false},
+	)
+	strict := bittrex.NewOrderValidator(s.Bittrex(), bittrex.WithPrecision("BTC-LTC", 2, 6), bittrex.WithMinNotional("USDT", 5))
+	rounding := bittrex.NewOrderValidator(s.Bittrex(), bittrex.WithPrecision("BTC-LTC", 2, 6), bittrex.WithRounding())
+
+	for _, tt := range []struct {
+		name           string
+		v              *bittrex.OrderValidator
+		market         string
+		quantity, rate float64
+		err            error   // nil if valid
+		field          string  // of an ErrPrecision
+		limit          float64 // of the error
+		wantQ, wantR   float64 // if valid
+	}{
+		{"valid", strict, "btc-ltc", 1.25, 0.012345, nil, "", 0, 1.25, 0.012345},
+		{"market order", strict, "BTC-LTC", 0.5, 0, nil, "", 0, 0.5, 0},
+		{"unknown market", strict, "BTC-NOPE", 1, 1, bittrex.ErrUnknownMarket, "", 0, 0, 0},
+		{"inactive market", strict, "BTC-OLD", 1, 1, bittrex.ErrMarketInactive, "", 0, 0, 0},
+		{"quantity decimals", strict, "BTC-LTC", 1.255, 0.01, bittrex.ErrPrecision, "quantity", 2, 0, 0},
+		{"rate decimals", strict, "BTC-LTC", 1.25, 0.0123456, bittrex.ErrPrecision, "rate", 6, 0, 0},
+		{"default precision", strict, "USDT-BTC", 0.123456789, 50000, bittrex.ErrPrecision, "quantity", 8, 0, 0},
+		{"float noise is not a decimal", strict, "BTC-LTC", 0.1 + 0.2, 0.01, nil, "", 0, 0.3, 0.01},
+		{"below min trade size", strict, "BTC-LTC", 0.05, 0.01, bittrex.ErrBelowMinTradeSize, "", 0.1, 0, 0},
+		{"zero quantity", strict, "USDT-BTC", 0, 50000, bittrex.ErrBelowMinTradeSize, "", 0.0001, 0, 0},
+		{"below default min notional", strict, "BTC-LTC", 1, 0.0004, bittrex.ErrBelowMinNotional, "", 0.0005, 0, 0},
+		{"at min notional", strict, "BTC-LTC", 1, 0.0005, nil, "", 0, 1, 0.0005},
+		{"below custom min notional", strict, "USDT-BTC", 0.0001, 40000, bittrex.ErrBelowMinNotional, "", 5, 0, 0},
+		{"market orders skip the notional", strict, "USDT-BTC", 0.0001, 0, nil, "", 0, 0.0001, 0},
+		{"rounded", rounding, "BTC-LTC", 1.259, 0.0123456, nil, "", 0, 1.25, 0.012346},
+		{"checked after rounding", rounding, "BTC-LTC", 0.109, 0.01, nil, "", 0, 0.1, 0.01},
+		{"below min after rounding", rounding, "BTC-LTC", 0.099, 0.01, bittrex.ErrBelowMinTradeSize, "", 0.1, 0, 0},
+	} {
+		q, r, err := tt.v.Validate(context.Background(), tt.market, tt.quantity, tt.rate)
+		if tt.err == nil {
+			if err != nil || q != tt.wantQ || r != tt.wantR {
+				t.Errorf("%s: got %v, %v, %v, want %v, %v", tt.name, q, r, err, tt.wantQ, tt.wantR)
+			}
+			continue
+		}
+		var verr *bittrex.ValidationError
+		if !errors.As(err, &verr) || !errors.Is(err, tt.err) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if verr.Field != tt.field || verr.Limit != tt.limit {
+			t.Errorf("%s: field %q limit %v, want %q %v", tt.name, verr.Field, verr.Limit, tt.field, tt.limit)
+		}
+	}
+}
+
+func TestValidationErrorMatchesAPIErrors(t *testing.T) {
+	s := bittrextest.NewServer()
+	defer s.Close()
+	s.SetMarkets(&bittrex.Market{MarketName: "BTC-LTC", MinTradeSize: 0.1, IsActive: true})
+	s.SetBalance("BTC", 1)
+	b := s.Bittrex()
+	trading := bittrex.NewValidatingTrading(b, bittrex.NewOrderValidator(b, bittrex.WithPrecision("BTC-LTC", 2, 8)))
+
+	if _, err := trading.BuyLimit("BTC-NOPE", 1, 0.01); !errors.Is(err, bittrex.ErrInvalidMarket) {
+		t.Fatalf("got %v, want ErrInvalidMarket", err)
+	}
+	if _, err := trading.BuyLimit("BTC-LTC", 0.01, 0.01); !errors.Is(err, bittrex.ErrMinTradeRequirementNotMet) {
+		t.Fatalf("got %v, want ErrMinTradeRequirementNotMet", err)
+	}
+	_, err := trading.BuyLimit("BTC-LTC", 1, 0.000000015)
+	if err == nil || !strings.Contains(err.Error(), "too many decimals: rate 1.5e-08, 8 allowed") {
+		t.Fatalf("got %v, want the rate precision", err)
+	}
+	if len(s.Orders()) != 0 {
+		t.Fatalf("rejected orders reached the exchange: %+v", s.Orders())
+	}
+	if _, err = trading.BuyLimit("BTC-LTC", 1, 0.01); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Orders()) != 1 {
+		t.Fatalf("orders %+v", s.Orders())
+	}
+}